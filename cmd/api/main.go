@@ -1,12 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"io"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/alejpaa/playlist-migration-tool/internal/config"
 	"github.com/alejpaa/playlist-migration-tool/internal/handlers"
+	"github.com/alejpaa/playlist-migration-tool/internal/jobs"
 	"github.com/alejpaa/playlist-migration-tool/internal/middleware"
 	"github.com/alejpaa/playlist-migration-tool/internal/services"
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
+	"github.com/alejpaa/playlist-migration-tool/pkg/auth"
+	"github.com/alejpaa/playlist-migration-tool/pkg/destination"
+	"github.com/alejpaa/playlist-migration-tool/pkg/spotify"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,15 +32,78 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Open the database
+	st, err := store.Open(cfg.DatabaseDSN)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer st.Close()
+
+	tokenStore, err := store.OpenTokenStore(cfg.TokenFile, cfg.DatabaseDSN, st)
+	if err != nil {
+		log.Fatalf("Failed to open token store: %v", err)
+	}
+	if closer, ok := tokenStore.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	// Access tokens must be issued to our OAuth client
+	var clientID string
+	if oauthConfig, err := auth.LoadConfig(cfg.GoogleCredentialsFile); err == nil {
+		clientID = oauthConfig.ClientID
+	} else {
+		log.Printf("Warning: %v, access token audience will not be checked", err)
+	}
+	tokenValidator := auth.NewTokenValidator(auth.TokenValidatorConfig{
+		URL:            cfg.TokenInfoURL,
+		ClientID:       clientID,
+		RequiredScopes: cfg.TokenRequiredScopes,
+		CacheTTL:       cfg.TokenCacheTTL,
+		HTTPClient:     &http.Client{Timeout: cfg.RequestTimeout},
+	})
+
 	// Initialize services
-	authService := services.NewAuthService(cfg.GoogleCredentialsFile)
-	playlistService := services.NewPlaylistService()
-	exportService := services.NewExportService()
+	oauthStates := services.NewOAuthStates(st, cfg.OAuthStateTTL)
+	spotifyAuthService := services.NewSpotifyAuthService(cfg.SpotifyClientID, cfg.SpotifyClientSecret, cfg.SpotifyRedirectURL, cfg.SpotifyAccountsURL, oauthStates)
+	quotaTracker := youtube.NewQuotaTracker(cfg.YouTubeDailyQuota)
+	retryPolicy := youtube.DefaultRetryPolicy()
+	retryPolicy.MaxAttempts = cfg.YouTubeMaxAttempts
+	sessionService := services.NewSessionService(st, tokenStore, cfg.GoogleCredentialsFile, cfg.SessionTTL)
+	youtubeClients := sessionService.ClientFactory(
+		youtube.WithBaseURL(cfg.YouTubeAPIBaseURL),
+		youtube.WithUserAgent(cfg.YouTubeUserAgent),
+		youtube.WithTimeout(cfg.YouTubeTimeout),
+		youtube.WithRetryPolicy(retryPolicy),
+		youtube.WithQuotaTracker(quotaTracker),
+	)
+	authService := services.NewAuthService(cfg.GoogleCredentialsFile, cfg.YouTubeRedirectURL, tokenStore, youtubeClients, sessionService, oauthStates)
+	accountService := services.NewAccountService(tokenStore, sessionService, tokenValidator, youtubeClients, cfg.TokenRevokeURL, &http.Client{Timeout: cfg.RequestTimeout})
+	playlistService := services.NewPlaylistService(youtubeClients)
+	exportService := services.NewExportService(playlistService, st)
+	quotaService := services.NewQuotaService(quotaTracker, sessionService)
+	destinations := map[string]destination.Factory{
+		"spotify": func(accessToken string) destination.Destination {
+			return spotify.NewClient(accessToken, spotify.WithBaseURL(cfg.SpotifyAPIBaseURL))
+		},
+	}
+	migrationRepo := services.NewStoreMigrationRepository(st)
+	migrationService := services.NewMigrationService(playlistService, youtubeClients, destinations, migrationRepo, st, cfg.ReviewThreshold)
+	jobManager := jobs.NewManager(cfg.JobWorkers, cfg.JobQueueSize, cfg.JobRetention)
+	jobService := services.NewJobService(jobManager, exportService, migrationService, st, cfg.ExportTimeout, cfg.MigrationTimeout)
+	if n, err := jobService.FailInterruptedJobs(context.Background()); err != nil {
+		log.Printf("Warning: unable to mark interrupted jobs as failed: %v", err)
+	} else if n > 0 {
+		log.Printf("Marked %d jobs interrupted by the last shutdown as failed", n)
+	}
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService)
+	authHandler := handlers.NewAuthHandler(authService, spotifyAuthService, cfg.PostLoginURL)
+	accountHandler := handlers.NewAccountHandler(accountService)
 	playlistHandler := handlers.NewPlaylistHandler(playlistService)
 	exportHandler := handlers.NewExportHandler(exportService)
+	quotaHandler := handlers.NewQuotaHandler(quotaService)
+	migrationHandler := handlers.NewMigrationHandler(migrationService)
+	jobHandler := handlers.NewJobHandler(jobService)
 	healthHandler := handlers.NewHealthHandler()
 
 	// Setup Gin router
@@ -42,26 +118,96 @@ func main() {
 	router.GET("/health", healthHandler.HealthCheck)
 
 	// Auth endpoints
-	auth := router.Group("/auth")
+	authRoutes := router.Group("/auth")
+	{
+		authRoutes.GET("/youtube/url", authHandler.GetYouTubeAuthURL)
+		authRoutes.GET("/youtube/callback", middleware.TimeoutMiddleware(cfg.RequestTimeout), authHandler.YouTubeCallback)
+		authRoutes.POST("/youtube/callback", middleware.TimeoutMiddleware(cfg.RequestTimeout), authHandler.CompleteYouTubeAuth)
+		authRoutes.POST("/youtube/device", middleware.TimeoutMiddleware(cfg.RequestTimeout), authHandler.StartYouTubeDeviceAuth)
+		authRoutes.POST("/youtube/device/poll", authHandler.PollYouTubeDeviceAuth)
+		authRoutes.GET("/spotify/url", authHandler.GetSpotifyAuthURL)
+		authRoutes.GET("/spotify/callback", middleware.TimeoutMiddleware(cfg.RequestTimeout), authHandler.SpotifyCallback)
+		authRoutes.POST("/spotify/callback", middleware.TimeoutMiddleware(cfg.RequestTimeout), authHandler.CompleteSpotifyAuth)
+	}
+
+	// Account endpoints act on the account behind the caller's credential
+	account := router.Group("/auth")
+	account.Use(middleware.AuthMiddleware(sessionService, tokenValidator), middleware.TimeoutMiddleware(cfg.RequestTimeout))
 	{
-		auth.GET("/youtube/url", authHandler.GetYouTubeAuthURL)
-		auth.POST("/youtube/callback", authHandler.CompleteYouTubeAuth)
-		auth.POST("/youtube", authHandler.AuthenticateYouTube)
+		account.GET("/me", accountHandler.GetAccount)
+		account.POST("/youtube/revoke", accountHandler.RevokeYouTube)
 	}
 
 	// Protected API endpoints (require auth)
 	api := router.Group("/api")
-	api.Use(middleware.AuthMiddleware())
+	api.Use(middleware.AuthMiddleware(sessionService, tokenValidator))
 	{
+		// Regular endpoints share the default request deadline
+		requests := api.Group("", middleware.TimeoutMiddleware(cfg.RequestTimeout))
+
 		// Playlist endpoints
-		api.GET("/playlists", playlistHandler.GetPlaylists)
-		api.GET("/playlists/:id", playlistHandler.GetPlaylistByID)
-		api.GET("/playlists/:id/songs", playlistHandler.GetPlaylistSongs)
+		requests.GET("/playlists", playlistHandler.GetPlaylists)
+		requests.GET("/playlists/:id", playlistHandler.GetPlaylistByID)
+		requests.GET("/playlists/:id/songs", playlistHandler.GetPlaylistSongs)
+		requests.GET("/playlists/:id/tracks", playlistHandler.GetPlaylistTracks)
+		requests.POST("/playlists", playlistHandler.CreatePlaylist)
+		requests.PUT("/playlists/:id", playlistHandler.UpdatePlaylist)
+		requests.DELETE("/playlists/:id", playlistHandler.DeletePlaylist)
+		requests.POST("/playlists/:id/items", playlistHandler.AddPlaylistItem)
+		requests.PUT("/playlists/:id/items/:item_id", playlistHandler.MovePlaylistItem)
+		requests.DELETE("/playlists/:id/items/:item_id", playlistHandler.RemovePlaylistItem)
+
+		// Export endpoints (walk whole playlists, so they get a longer deadline)
+		api.POST("/export/:id", middleware.TimeoutMiddleware(cfg.ExportTimeout), exportHandler.ExportPlaylist)
+
+		// Migration endpoints (copy whole playlists, so they get the longest deadline)
+		api.POST("/migrations", middleware.TimeoutMiddleware(cfg.MigrationTimeout), migrationHandler.CreateMigration)
+		requests.GET("/migrations/:id", migrationHandler.GetMigration)
+		requests.GET("/migrations/:id/review", migrationHandler.GetReviewQueue)
+		requests.POST("/migrations/:id/review/:position", migrationHandler.ReviewTrack)
+		api.POST("/migrations/:id/complete", middleware.TimeoutMiddleware(cfg.MigrationTimeout), migrationHandler.CompleteMigration)
+		api.POST("/migrations/:id/resume", middleware.TimeoutMiddleware(cfg.MigrationTimeout), migrationHandler.ResumeMigration)
+		api.POST("/migrate/:id", middleware.TimeoutMiddleware(cfg.MigrationTimeout), migrationHandler.MigrateToDestination)
+
+		// Background job endpoints
+		requests.POST("/jobs", jobHandler.CreateJob)
+		requests.GET("/jobs/:id", jobHandler.GetJob)
+		requests.DELETE("/jobs/:id", jobHandler.CancelJob)
+		api.GET("/jobs/:id/events", jobHandler.StreamJobEvents) // Streams until the job finishes, so no deadline
+
+		// Quota endpoints
+		requests.GET("/quota", quotaHandler.GetQuota)
+	}
 
-		// Export endpoints
-		api.POST("/export/:id", exportHandler.ExportPlaylist)
+	server := &http.Server{
+		Addr:    ":" + cfg.Port,
+		Handler: router,
 	}
 
-	log.Printf("🚀 Server starting on port %s", cfg.Port)
-	log.Fatal(router.Run(":" + cfg.Port))
+	go func() {
+		log.Printf("🚀 Server starting on port %s", cfg.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// Wait for an interrupt, then stop accepting requests and let running
+	// requests and jobs finish within the shutdown timeout
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	log.Printf("Shutting down, waiting up to %s for requests and jobs", cfg.ShutdownTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Pending device authorizations cannot be polled once the server is gone
+	authService.Shutdown()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("HTTP server shutdown: %v", err)
+	}
+	if err := jobManager.Shutdown(ctx); err != nil {
+		log.Printf("Job manager shutdown: %v, unfinished jobs were cancelled", err)
+	}
+	log.Println("Server stopped")
 }
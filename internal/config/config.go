@@ -2,14 +2,44 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds the application configuration
 type Config struct {
 	Port                  string
 	GoogleCredentialsFile string
-	TokenFile             string
+	YouTubeRedirectURL    string
+	PostLoginURL          string
+	TokenFile             string // Token directory, or a database DSN
 	Environment           string
+	RequestTimeout        time.Duration
+	ExportTimeout         time.Duration
+	MigrationTimeout      time.Duration
+	DatabaseDSN           string
+	SessionTTL            time.Duration
+	OAuthStateTTL         time.Duration
+	TokenInfoURL          string
+	TokenRevokeURL        string
+	TokenCacheTTL         time.Duration
+	TokenRequiredScopes   []string
+	ReviewThreshold       float64
+	JobWorkers            int
+	JobQueueSize          int
+	JobRetention          time.Duration
+	ShutdownTimeout       time.Duration
+	YouTubeAPIBaseURL     string
+	YouTubeUserAgent      string
+	YouTubeTimeout        time.Duration
+	YouTubeMaxAttempts    int
+	YouTubeDailyQuota     int
+	SpotifyClientID       string
+	SpotifyClientSecret   string
+	SpotifyRedirectURL    string
+	SpotifyAPIBaseURL     string
+	SpotifyAccountsURL    string
 }
 
 // Load loads configuration from environment variables with defaults
@@ -17,8 +47,35 @@ func Load() *Config {
 	return &Config{
 		Port:                  getEnv("PORT", "8080"),
 		GoogleCredentialsFile: getEnv("GOOGLE_CREDENTIALS_FILE", "client_secret_332431762901-dthq67hje7hcldkt4edg2n6dlbujsuck.apps.googleusercontent.com.json"),
-		TokenFile:             getEnv("TOKEN_FILE", "token.json"),
+		YouTubeRedirectURL:    getEnv("YOUTUBE_REDIRECT_URL", ""),
+		PostLoginURL:          getEnv("POST_LOGIN_URL", ""),
+		TokenFile:             getEnv("TOKEN_FILE", "data/tokens"),
 		Environment:           getEnv("ENVIRONMENT", "development"),
+		RequestTimeout:        getDurationEnv("REQUEST_TIMEOUT", 30*time.Second),
+		ExportTimeout:         getDurationEnv("EXPORT_TIMEOUT", 5*time.Minute),
+		MigrationTimeout:      getDurationEnv("MIGRATION_TIMEOUT", 15*time.Minute),
+		DatabaseDSN:           getEnv("DATABASE_DSN", "file:data/playlist-migration.db"),
+		SessionTTL:            getDurationEnv("SESSION_TTL", 720*time.Hour),
+		OAuthStateTTL:         getDurationEnv("OAUTH_STATE_TTL", 10*time.Minute),
+		TokenInfoURL:          getEnv("TOKENINFO_URL", "https://oauth2.googleapis.com/tokeninfo"),
+		TokenRevokeURL:        getEnv("TOKEN_REVOKE_URL", "https://oauth2.googleapis.com/revoke"),
+		TokenCacheTTL:         getDurationEnv("TOKEN_CACHE_TTL", 5*time.Minute),
+		TokenRequiredScopes:   getListEnv("TOKEN_REQUIRED_SCOPES"),
+		ReviewThreshold:       getFloatEnv("REVIEW_THRESHOLD", 0.8),
+		JobWorkers:            getIntEnv("JOB_WORKERS", 4),
+		JobQueueSize:          getIntEnv("JOB_QUEUE_SIZE", 100),
+		JobRetention:          getDurationEnv("JOB_RETENTION", time.Hour),
+		ShutdownTimeout:       getDurationEnv("SHUTDOWN_TIMEOUT", 30*time.Second),
+		YouTubeAPIBaseURL:     getEnv("YOUTUBE_API_BASE_URL", "https://www.googleapis.com/youtube/v3"),
+		YouTubeUserAgent:      getEnv("YOUTUBE_USER_AGENT", "playlist-migration-tool/1.0.0"),
+		YouTubeTimeout:        getDurationEnv("YOUTUBE_TIMEOUT", 30*time.Second),
+		YouTubeMaxAttempts:    getIntEnv("YOUTUBE_MAX_ATTEMPTS", 4),
+		YouTubeDailyQuota:     getIntEnv("YOUTUBE_DAILY_QUOTA", 10000),
+		SpotifyClientID:       getEnv("SPOTIFY_CLIENT_ID", ""),
+		SpotifyClientSecret:   getEnv("SPOTIFY_CLIENT_SECRET", ""),
+		SpotifyRedirectURL:    getEnv("SPOTIFY_REDIRECT_URL", "http://localhost:8080/auth/spotify/callback"),
+		SpotifyAPIBaseURL:     getEnv("SPOTIFY_API_BASE_URL", "https://api.spotify.com/v1"),
+		SpotifyAccountsURL:    getEnv("SPOTIFY_ACCOUNTS_URL", "https://accounts.spotify.com"),
 	}
 }
 
@@ -29,3 +86,44 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getIntEnv gets an integer environment variable with a fallback value
+func getIntEnv(key string, fallback int) int {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return fallback
+}
+
+// getFloatEnv gets a floating point environment variable with a fallback value
+func getFloatEnv(key string, fallback float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return fallback
+}
+
+// getListEnv gets a comma-separated environment variable as a list
+func getListEnv(key string) []string {
+	var list []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// getDurationEnv gets a duration environment variable (e.g. "30s") with a fallback value
+func getDurationEnv(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
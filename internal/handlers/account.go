@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandler handles the endpoints about the caller's linked account
+type AccountHandler struct {
+	accountService *services.AccountService
+}
+
+// NewAccountHandler creates a new AccountHandler
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// GetAccount handles GET /auth/me
+func (h *AccountHandler) GetAccount(c *gin.Context) {
+	// Get access token from context
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.accountService.GetAccount(c.Request.Context(), accessTokenStr, c.GetString("user_id"))
+	if err != nil {
+		respondWithError(c, err, "Failed to fetch account")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeYouTube handles POST /auth/youtube/revoke
+// The caller's tokens are revoked at Google and all of their sessions end
+func (h *AccountHandler) RevokeYouTube(c *gin.Context) {
+	// Get access token from context
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.accountService.RevokeYouTube(c.Request.Context(), accessTokenStr, c.GetString("user_id"))
+	if err != nil {
+		respondWithError(c, err, "Failed to revoke YouTube access")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
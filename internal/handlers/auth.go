@@ -2,81 +2,272 @@ package handlers
 
 import (
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/alejpaa/playlist-migration-tool/internal/models"
 	"github.com/alejpaa/playlist-migration-tool/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
+// Cookies holding the nonce that binds a sign-in to the browser that started it
+const (
+	youtubeOAuthCookie = "pmt_oauth_youtube"
+	spotifyOAuthCookie = "pmt_oauth_spotify"
+)
+
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
-	authService *services.AuthService
+	authService        *services.AuthService
+	spotifyAuthService *services.SpotifyAuthService
+	postLoginURL       string
 }
 
-// NewAuthHandler creates a new AuthHandler
-func NewAuthHandler(authService *services.AuthService) *AuthHandler {
+// NewAuthHandler creates a new AuthHandler. After a browser sign-in the user
+// is redirected to postLoginURL; when it is empty the result is returned as JSON.
+func NewAuthHandler(authService *services.AuthService, spotifyAuthService *services.SpotifyAuthService, postLoginURL string) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
+		authService:        authService,
+		spotifyAuthService: spotifyAuthService,
+		postLoginURL:       postLoginURL,
 	}
 }
 
-// AuthenticateYouTube handles YouTube authentication
-func (h *AuthHandler) AuthenticateYouTube(c *gin.Context) {
-	response, err := h.authService.AuthenticateWithYouTube()
+// GetYouTubeAuthURL obtiene la URL de autenticación de YouTube
+// Use ?access=write to request permission to create and edit playlists
+func (h *AuthHandler) GetYouTubeAuthURL(c *gin.Context) {
+	write := c.Query("access") == "write"
+
+	authURL, nonce, err := h.authService.GetYouTubeAuthURL(c.Request.Context(), write)
 	if err != nil {
-		if apiErr, ok := err.(*models.APIError); ok {
-			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		} else {
-			apiErr := models.NewInternalServerError("Authentication failed", err)
-			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		}
+		respondWithError(c, err, "Failed to generate auth URL")
+		return
+	}
+	setOAuthCookie(c, youtubeOAuthCookie, nonce)
+
+	c.JSON(http.StatusOK, gin.H{
+		"auth_url": authURL,
+		"message":  "Visita esta URL para autorizar la aplicación con tu cuenta de YouTube",
+	})
+}
+
+// CompleteYouTubeAuth completa la autenticación con el código de autorización
+// The request must carry the cookie set by GET /auth/youtube/url
+func (h *AuthHandler) CompleteYouTubeAuth(c *gin.Context) {
+	var request struct {
+		AuthCode string `json:"auth_code" binding:"required"`
+		State    string `json:"state" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := models.NewBadRequestError("Auth code and state are required", err)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return
+	}
+
+	response, err := h.authService.CompleteYouTubeAuth(c.Request.Context(), request.AuthCode, request.State, takeOAuthCookie(c, youtubeOAuthCookie))
+	if err != nil {
+		respondWithError(c, err, "Authentication failed")
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-// GetYouTubeAuthURL obtiene la URL de autenticación de YouTube
-func (h *AuthHandler) GetYouTubeAuthURL(c *gin.Context) {
-	authURL, err := h.authService.GetYouTubeAuthURL()
+// StartYouTubeDeviceAuth handles POST /auth/youtube/device
+// Use ?access=write to request permission to create and edit playlists
+func (h *AuthHandler) StartYouTubeDeviceAuth(c *gin.Context) {
+	write := c.Query("access") == "write"
+
+	response, err := h.authService.StartYouTubeDeviceAuth(c.Request.Context(), write)
+	if err != nil {
+		respondWithError(c, err, "Failed to start device authorization")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// PollYouTubeDeviceAuth handles POST /auth/youtube/device/poll
+// It answers 202 until the user approves the device code, then returns the session
+func (h *AuthHandler) PollYouTubeDeviceAuth(c *gin.Context) {
+	var request struct {
+		DeviceID string `json:"device_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := models.NewBadRequestError("Device ID is required", err)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return
+	}
+
+	response, err := h.authService.PollYouTubeDeviceAuth(c.Request.Context(), request.DeviceID)
+	if err != nil {
+		respondWithError(c, err, "Device authorization failed")
+		return
+	}
+	if response == nil {
+		c.JSON(http.StatusAccepted, gin.H{
+			"status":  "authorization_pending",
+			"message": "Esperando a que el usuario autorice la aplicación",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// YouTubeCallback handles GET /auth/youtube/callback, where Google redirects
+// the browser once the user has authorized the application
+func (h *AuthHandler) YouTubeCallback(c *gin.Context) {
+	code, state, ok := h.callbackParams(c, "youtube")
+	if !ok {
+		return
+	}
+
+	response, err := h.authService.CompleteYouTubeAuth(c.Request.Context(), code, state, takeOAuthCookie(c, youtubeOAuthCookie))
+	h.loginRedirect(c, "youtube", response, err)
+}
+
+// callbackParams reads the authorization code and state of a provider
+// redirect. When the user denied access or either is missing it redirects
+// with the error and ok is false.
+func (h *AuthHandler) callbackParams(c *gin.Context, provider string) (code, state string, ok bool) {
+	if reason := c.Query("error"); reason != "" {
+		h.loginRedirect(c, provider, nil, models.NewBadRequestError("Authorization was not granted: "+reason, nil))
+		return "", "", false
+	}
+
+	code, state = c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		h.loginRedirect(c, provider, nil, models.NewBadRequestError("Code and state are required", nil))
+		return "", "", false
+	}
+	return code, state, true
+}
+
+// loginRedirect sends the browser to the post-login URL with the provider and
+// the session, or the error, in the URL fragment, which browsers do not send
+// to servers or write to access logs. Without a post-login URL it responds
+// with JSON.
+func (h *AuthHandler) loginRedirect(c *gin.Context, provider string, response *models.AuthResponse, err error) {
+	if h.postLoginURL == "" {
+		if err != nil {
+			respondWithError(c, err, "Authentication failed")
+			return
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	values := url.Values{"provider": {provider}}
 	if err != nil {
-		if apiErr, ok := err.(*models.APIError); ok {
-			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		} else {
-			apiErr := models.NewInternalServerError("Failed to generate auth URL", err)
-			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		apiErr, ok := err.(*models.APIError)
+		if !ok {
+			apiErr = models.NewInternalServerError("Authentication failed", err)
 		}
+		values.Set("error", apiErr.Message)
+	} else if response.SessionToken == "" {
+		// Spotify sign-ins open no session: the client keeps the token
+		values.Set("access_token", response.AccessToken)
+	} else {
+		values.Set("session_token", response.SessionToken)
+		values.Set("user_id", response.UserID)
+		if response.SessionExpiresAt != nil {
+			values.Set("session_expires_at", response.SessionExpiresAt.Format(time.RFC3339))
+		}
+	}
+
+	target, _, _ := strings.Cut(h.postLoginURL, "#")
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, target+"#"+values.Encode())
+}
+
+// GetSpotifyAuthURL handles GET /auth/spotify/url
+func (h *AuthHandler) GetSpotifyAuthURL(c *gin.Context) {
+	authURL, nonce, err := h.spotifyAuthService.GetAuthURL(c.Request.Context())
+	if err != nil {
+		respondWithError(c, err, "Failed to generate auth URL")
 		return
 	}
+	setOAuthCookie(c, spotifyOAuthCookie, nonce)
 
 	c.JSON(http.StatusOK, gin.H{
 		"auth_url": authURL,
-		"message":  "Visita esta URL para autorizar la aplicación con tu cuenta de YouTube",
+		"message":  "Visita esta URL para autorizar la aplicación con tu cuenta de Spotify",
 	})
 }
 
-// CompleteYouTubeAuth completa la autenticación con el código de autorización
-func (h *AuthHandler) CompleteYouTubeAuth(c *gin.Context) {
+// SpotifyCallback handles GET /auth/spotify/callback, where Spotify redirects
+// the browser once the user has authorized the application
+func (h *AuthHandler) SpotifyCallback(c *gin.Context) {
+	code, state, ok := h.callbackParams(c, "spotify")
+	if !ok {
+		return
+	}
+
+	response, err := h.spotifyAuthService.CompleteAuth(c.Request.Context(), code, state, takeOAuthCookie(c, spotifyOAuthCookie))
+	h.loginRedirect(c, "spotify", response, err)
+}
+
+// CompleteSpotifyAuth handles POST /auth/spotify/callback
+// The request must carry the cookie set by GET /auth/spotify/url
+func (h *AuthHandler) CompleteSpotifyAuth(c *gin.Context) {
 	var request struct {
 		AuthCode string `json:"auth_code" binding:"required"`
+		State    string `json:"state" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		apiErr := models.NewBadRequestError("Auth code is required", err)
+		apiErr := models.NewBadRequestError("Auth code and state are required", err)
 		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
 		return
 	}
 
-	response, err := h.authService.CompleteYouTubeAuth(request.AuthCode)
+	response, err := h.spotifyAuthService.CompleteAuth(c.Request.Context(), request.AuthCode, request.State, takeOAuthCookie(c, spotifyOAuthCookie))
 	if err != nil {
-		if apiErr, ok := err.(*models.APIError); ok {
-			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		} else {
-			apiErr := models.NewInternalServerError("Authentication failed", err)
-			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		}
+		respondWithError(c, err, "Authentication failed")
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// setOAuthCookie stores the nonce of a sign-in in the browser. SameSite=Lax
+// still sends it on the top-level redirect back from the provider.
+func setOAuthCookie(c *gin.Context, name, nonce string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    nonce,
+		Path:     "/auth",
+		HttpOnly: true,
+		Secure:   isHTTPS(c),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// takeOAuthCookie returns the nonce of a sign-in and clears the cookie, since
+// its state can only be used once
+func takeOAuthCookie(c *gin.Context, name string) string {
+	nonce, err := c.Cookie(name)
+	if err != nil {
+		return ""
+	}
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Path:     "/auth",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isHTTPS(c),
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nonce
+}
+
+// isHTTPS reports whether the client reached the server over TLS, directly
+// or through a proxy
+func isHTTPS(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/services"
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube/youtubetest"
+	"github.com/gin-gonic/gin"
+)
+
+// testPostLoginURL is where the callbacks send the browser after signing in
+const testPostLoginURL = "http://app.test/signed-in"
+
+// newSpotifyAccounts fakes the Spotify accounts service. It hands out
+// spotify-token for the code "good-code" and rejects any other code.
+func newSpotifyAccounts(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/token" || r.FormValue("code") != "good-code" || r.FormValue("code_verifier") == "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"spotify-token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newSpotifyAuthRouter serves the Spotify sign-in endpoints against accounts
+func newSpotifyAuthRouter(accounts *httptest.Server) *gin.Engine {
+	states := services.NewOAuthStates(store.NewMemoryStore(), 0)
+	spotify := services.NewSpotifyAuthService("client-id", "client-secret", "http://localhost/auth/spotify/callback", accounts.URL, states)
+	handler := NewAuthHandler(nil, spotify, testPostLoginURL)
+
+	router := gin.New()
+	router.GET("/auth/spotify/url", handler.GetSpotifyAuthURL)
+	router.GET("/auth/spotify/callback", handler.SpotifyCallback)
+	return router
+}
+
+// beginSignIn calls a sign-in URL endpoint and returns the state of the
+// authorization URL and the cookie that binds it to the browser
+func beginSignIn(t *testing.T, router *gin.Engine, target string) (string, *http.Cookie) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	expectStatus(t, rec.Code, http.StatusOK)
+
+	var response struct {
+		AuthURL string `json:"auth_url"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := url.Parse(response.AuthURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("cookies = %v, want one", cookies)
+	}
+	return authURL.Query().Get("state"), cookies[0]
+}
+
+// followCallback calls a callback endpoint as the provider's redirect would
+// and returns the values in the fragment of the post-login redirect
+func followCallback(t *testing.T, router *gin.Engine, target string, cookie *http.Cookie) url.Values {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	expectStatus(t, rec.Code, http.StatusFound)
+
+	location := rec.Header().Get("Location")
+	if !strings.HasPrefix(location, testPostLoginURL+"#") {
+		t.Fatalf("redirected to %q, want %s#...", location, testPostLoginURL)
+	}
+	values, err := url.ParseQuery(strings.TrimPrefix(location, testPostLoginURL+"#"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return values
+}
+
+func TestSpotifyCallback(t *testing.T) {
+	accounts := newSpotifyAccounts(t)
+
+	tests := []struct {
+		name      string
+		query     func(state string) string
+		noCookie  bool
+		wantToken string
+		wantError string
+	}{
+		{
+			name:      "authorized",
+			query:     func(state string) string { return "code=good-code&state=" + state },
+			wantToken: "spotify-token",
+		},
+		{
+			name:      "access denied",
+			query:     func(state string) string { return "error=access_denied&state=" + state },
+			wantError: "Authorization was not granted: access_denied",
+		},
+		{
+			name:      "missing code",
+			query:     func(state string) string { return "state=" + state },
+			wantError: "Code and state are required",
+		},
+		{
+			name:      "unknown state",
+			query:     func(state string) string { return "code=good-code&state=forged" },
+			wantError: "Invalid or already used state, start the sign-in again",
+		},
+		{
+			name:      "another browser",
+			query:     func(state string) string { return "code=good-code&state=" + state },
+			noCookie:  true,
+			wantError: "Sign-in was started in another browser, start it again",
+		},
+		{
+			name:      "rejected code",
+			query:     func(state string) string { return "code=bad-code&state=" + state },
+			wantError: "Invalid authorization code",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newSpotifyAuthRouter(accounts)
+			state, cookie := beginSignIn(t, router, "/auth/spotify/url")
+			if tt.noCookie {
+				cookie = nil
+			}
+
+			values := followCallback(t, router, "/auth/spotify/callback?"+tt.query(state), cookie)
+			if got := values.Get("provider"); got != "spotify" {
+				t.Errorf("provider = %q, want spotify", got)
+			}
+			if got := values.Get("access_token"); got != tt.wantToken {
+				t.Errorf("access_token = %q, want %q", got, tt.wantToken)
+			}
+			if got := values.Get("error"); got != tt.wantError {
+				t.Errorf("error = %q, want %q", got, tt.wantError)
+			}
+		})
+	}
+}
+
+// newGoogleAccounts fakes Google's token endpoint. It hands out ya29.token for
+// the code "good-code" and rejects any other code.
+func newGoogleAccounts(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/token" || r.FormValue("code") != "good-code" || r.FormValue("code_verifier") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"ya29.token","refresh_token":"refresh","token_type":"Bearer","expires_in":3600}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newYouTubeAuthRouter serves the YouTube sign-in endpoints with a web client
+// whose token endpoint is accounts. It returns the store the tokens go to.
+func newYouTubeAuthRouter(t *testing.T, accounts *httptest.Server, yt *youtubetest.Server) (*gin.Engine, *store.TokenStore) {
+	t.Helper()
+
+	credentials := filepath.Join(t.TempDir(), "credentials.json")
+	secret := `{"web": {
+		"client_id": "client-id",
+		"client_secret": "client-secret",
+		"token_uri": "` + accounts.URL + `/token",
+		"redirect_uris": ["http://localhost/auth/youtube/callback"]
+	}}`
+	if err := os.WriteFile(credentials, []byte(secret), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	st := store.NewMemoryStore()
+	tokens := store.NewTokenStore(st, store.ProviderYouTube)
+	sessions := services.NewSessionService(st, tokens, credentials, 0)
+	clients := services.NewClientFactory(youtube.WithBaseURL(yt.URL), youtube.WithoutRetries())
+	service := services.NewAuthService(credentials, "", tokens, clients, sessions, services.NewOAuthStates(st, 0))
+	handler := NewAuthHandler(service, nil, testPostLoginURL)
+
+	router := gin.New()
+	router.GET("/auth/youtube/url", handler.GetYouTubeAuthURL)
+	router.GET("/auth/youtube/callback", handler.YouTubeCallback)
+	return router, tokens
+}
+
+func TestYouTubeCallback(t *testing.T) {
+	accounts := newGoogleAccounts(t)
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	yt.AddChannel(youtubetest.Channel{ID: "UC-user", Snippet: youtubetest.ChannelSnippet{Title: "My Channel"}}, true)
+
+	tests := []struct {
+		name      string
+		query     func(state string) string
+		noCookie  bool
+		wantUser  string
+		wantError string
+	}{
+		{
+			name:     "authorized",
+			query:    func(state string) string { return "code=good-code&state=" + state },
+			wantUser: "UC-user",
+		},
+		{
+			name:      "access denied",
+			query:     func(state string) string { return "error=access_denied&state=" + state },
+			wantError: "Authorization was not granted: access_denied",
+		},
+		{
+			name:      "missing code",
+			query:     func(state string) string { return "state=" + state },
+			wantError: "Code and state are required",
+		},
+		{
+			name:      "unknown state",
+			query:     func(state string) string { return "code=good-code&state=forged" },
+			wantError: "Invalid or already used state, start the sign-in again",
+		},
+		{
+			name:      "another browser",
+			query:     func(state string) string { return "code=good-code&state=" + state },
+			noCookie:  true,
+			wantError: "Sign-in was started in another browser, start it again",
+		},
+		{
+			name:      "rejected code",
+			query:     func(state string) string { return "code=bad-code&state=" + state },
+			wantError: "Invalid authorization code",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, tokens := newYouTubeAuthRouter(t, accounts, yt)
+			state, cookie := beginSignIn(t, router, "/auth/youtube/url")
+			if tt.noCookie {
+				cookie = nil
+			}
+
+			values := followCallback(t, router, "/auth/youtube/callback?"+tt.query(state), cookie)
+			if got := values.Get("provider"); got != "youtube" {
+				t.Errorf("provider = %q, want youtube", got)
+			}
+			if got := values.Get("error"); got != tt.wantError {
+				t.Errorf("error = %q, want %q", got, tt.wantError)
+			}
+			if got := values.Get("user_id"); got != tt.wantUser {
+				t.Errorf("user_id = %q, want %q", got, tt.wantUser)
+			}
+			// The access token stays on the server, behind the session
+			if values.Has("access_token") {
+				t.Error("access token in the redirect")
+			}
+
+			saved, err := tokens.Load(context.Background(), "UC-user")
+			if tt.wantUser == "" {
+				if err == nil || values.Get("session_token") != "" {
+					t.Errorf("failed sign-in saved a token or opened a session: %v", values)
+				}
+				return
+			}
+			if values.Get("session_token") == "" || values.Get("session_expires_at") == "" {
+				t.Errorf("no session in %v", values)
+			}
+			if err != nil || saved.AccessToken != "ya29.token" || saved.RefreshToken != "refresh" {
+				t.Errorf("saved token = %+v, %v", saved, err)
+			}
+		})
+	}
+}
@@ -23,17 +23,8 @@ func NewExportHandler(exportService *services.ExportService) *ExportHandler {
 // ExportPlaylist handles POST /export/:id
 func (h *ExportHandler) ExportPlaylist(c *gin.Context) {
 	// Get access token from context
-	accessToken, exists := c.Get("access_token")
-	if !exists {
-		apiErr := models.NewUnauthorizedError("Access token not found", nil)
-		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		return
-	}
-
-	accessTokenStr, ok := accessToken.(string)
+	accessTokenStr, ok := accessTokenFromContext(c)
 	if !ok {
-		apiErr := models.NewUnauthorizedError("Invalid access token format", nil)
-		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
 		return
 	}
 
@@ -59,14 +50,9 @@ func (h *ExportHandler) ExportPlaylist(c *gin.Context) {
 	}
 
 	// Export playlist
-	response, err := h.exportService.ExportPlaylist(accessTokenStr, playlistID, &request)
+	response, err := h.exportService.ExportPlaylist(c.Request.Context(), accessTokenStr, playlistID, &request)
 	if err != nil {
-		if apiErr, ok := err.(*models.APIError); ok {
-			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		} else {
-			apiErr := models.NewInternalServerError("Failed to export playlist", err)
-			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		}
+		respondWithError(c, err, "Failed to export playlist")
 		return
 	}
 
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube/youtubetest"
+)
+
+func TestExportPlaylistPaging(t *testing.T) {
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	// Three pages of playlistItems at 50 items per page
+	yt.SeedPlaylist("PL1", "Big playlist", 120)
+	router := newTestRouter(t, yt, 1)
+
+	var response models.ExportResponse
+	status := serve(t, router, http.MethodPost, "/api/export/PL1", models.ExportRequest{Format: "json"}, &response)
+	expectStatus(t, status, http.StatusOK)
+
+	var playlist models.PlaylistDetailResponse
+	if err := json.Unmarshal([]byte(response.Data), &playlist); err != nil {
+		t.Fatalf("export is not JSON: %v", err)
+	}
+	if len(playlist.Videos) != 120 {
+		t.Fatalf("exported %d videos, want 120", len(playlist.Videos))
+	}
+	for i, video := range playlist.Videos {
+		if video.Position != i {
+			t.Fatalf("video %d has position %d", i, video.Position)
+		}
+	}
+	if got := yt.Requests("playlistItems"); got != 3 {
+		t.Errorf("playlistItems requests = %d, want 3", got)
+	}
+}
+
+func TestExportPlaylistCSV(t *testing.T) {
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	yt.SeedPlaylist("PL1", "Playlist", 3)
+	router := newTestRouter(t, yt, 1)
+
+	var response models.ExportResponse
+	status := serve(t, router, http.MethodPost, "/api/export/PL1", models.ExportRequest{Format: "csv"}, &response)
+	expectStatus(t, status, http.StatusOK)
+
+	rows, err := csv.NewReader(strings.NewReader(response.Data)).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not CSV: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Errorf("CSV has %d rows, want a header and 3 videos", len(rows))
+	}
+}
+
+func TestExportPlaylistErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		playlistID string
+		format     string
+		setup      func(yt *youtubetest.Server)
+		wantStatus int
+	}{
+		{
+			name:       "unknown playlist",
+			playlistID: "missing",
+			format:     "json",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "items not found",
+			playlistID: "PL1",
+			format:     "json",
+			setup: func(yt *youtubetest.Server) {
+				yt.InjectError("playlistItems", youtubetest.NotFound("playlistNotFound"), 1)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "backend error",
+			playlistID: "PL1",
+			format:     "json",
+			setup: func(yt *youtubetest.Server) {
+				yt.InjectError("playlistItems", youtubetest.InternalError(), 1)
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "quota runs out mid-export",
+			playlistID: "PL1",
+			format:     "json",
+			setup: func(yt *youtubetest.Server) {
+				// playlists.list and the first playlistItems page only
+				yt.SetQuotaLimit(2)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "unsupported format",
+			playlistID: "PL1",
+			format:     "xml",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yt := youtubetest.NewServer()
+			defer yt.Close()
+			yt.SeedPlaylist("PL1", "Playlist", 120)
+			if tt.setup != nil {
+				tt.setup(yt)
+			}
+			router := newTestRouter(t, yt, 1)
+
+			status := serve(t, router, http.MethodPost, "/api/export/"+tt.playlistID, models.ExportRequest{Format: tt.format}, nil)
+			expectStatus(t, status, tt.wantStatus)
+		})
+	}
+}
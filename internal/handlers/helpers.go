@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+	"github.com/gin-gonic/gin"
+)
+
+// accessTokenFromContext returns the access token set by AuthMiddleware,
+// writing a 401 response when it is missing
+func accessTokenFromContext(c *gin.Context) (string, bool) {
+	accessToken, exists := c.Get("access_token")
+	if !exists {
+		apiErr := models.NewUnauthorizedError("Access token not found", nil)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return "", false
+	}
+
+	accessTokenStr, ok := accessToken.(string)
+	if !ok {
+		apiErr := models.NewUnauthorizedError("Invalid access token format", nil)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return "", false
+	}
+
+	return accessTokenStr, true
+}
+
+// ownerFromContext identifies the caller that jobs and migrations are
+// recorded for: the user of a session, or the Google account behind a raw
+// access token, so the owner survives token refreshes. Tokens without a
+// subject fall back to the token itself.
+func ownerFromContext(c *gin.Context, accessToken string) string {
+	if userID := c.GetString("user_id"); userID != "" {
+		return userID
+	}
+	if subject := c.GetString("token_subject"); subject != "" {
+		return "google:" + subject
+	}
+	return "token:" + youtube.TokenKey(accessToken)
+}
+
+// respondWithError writes err as a JSON error response. Errors that are not
+// an *models.APIError become a 500 with the given message.
+func respondWithError(c *gin.Context, err error, message string) {
+	apiErr, ok := err.(*models.APIError)
+	if !ok {
+		apiErr = models.NewInternalServerError(message, err)
+	}
+	c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+}
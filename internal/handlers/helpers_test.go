@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/services"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube/youtubetest"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// testAccessToken is the bearer token every test request is made with
+const testAccessToken = "test-token"
+
+// newTestRouter serves the playlist and export endpoints backed by the fake
+// YouTube server. Requests are authenticated as testAccessToken without
+// calling Google. Failed calls are retried up to attempts times, and opts are
+// added to every YouTube client.
+func newTestRouter(t *testing.T, yt *youtubetest.Server, attempts int, opts ...youtube.Option) *gin.Engine {
+	t.Helper()
+
+	policy := youtube.DefaultRetryPolicy()
+	policy.MaxAttempts = attempts
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = time.Millisecond
+	policy.Logf = t.Logf
+
+	opts = append([]youtube.Option{youtube.WithBaseURL(yt.URL), youtube.WithRetryPolicy(policy)}, opts...)
+	playlistService := services.NewPlaylistService(services.NewClientFactory(opts...))
+	playlistHandler := NewPlaylistHandler(playlistService)
+	exportHandler := NewExportHandler(services.NewExportService(playlistService, nil))
+
+	router := gin.New()
+	api := router.Group("/api", func(c *gin.Context) {
+		c.Set("access_token", testAccessToken)
+	})
+	api.GET("/playlists", playlistHandler.GetPlaylists)
+	api.GET("/playlists/:id", playlistHandler.GetPlaylistByID)
+	api.POST("/export/:id", exportHandler.ExportPlaylist)
+	return router
+}
+
+// serve sends a request to router and decodes the JSON response into out
+func serve(t *testing.T, router *gin.Engine, method, target string, body interface{}, out interface{}) int {
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, target, reader)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: invalid JSON response %q: %v", method, target, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+// expectStatus fails the test when the status of a response is not want
+func expectStatus(t *testing.T, got, want int) {
+	t.Helper()
+	if got != want {
+		t.Fatalf("status = %d (%s), want %d (%s)", got, http.StatusText(got), want, http.StatusText(want))
+	}
+}
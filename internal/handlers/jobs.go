@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/services"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// How often a comment is sent on idle event streams so proxies keep them open
+const sseKeepAlive = 15 * time.Second
+
+// JobHandler handles background job endpoints
+type JobHandler struct {
+	jobService *services.JobService
+}
+
+// NewJobHandler creates a new JobHandler
+func NewJobHandler(jobService *services.JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+	}
+}
+
+// CreateJob handles POST /jobs
+// The job is queued and its ID returned straight away; poll GET /jobs/:id for progress
+func (h *JobHandler) CreateJob(c *gin.Context) {
+	// Get access token from context
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var request models.JobRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := models.NewBadRequestError("Job type is required", err)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return
+	}
+
+	response, err := h.jobService.CreateJob(accessTokenStr, ownerFromContext(c, accessTokenStr), &request)
+	if err != nil {
+		respondWithError(c, err, "Failed to create job")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+// GetJob handles GET /jobs/:id
+// Jobs submitted by someone else are reported as not found
+func (h *JobHandler) GetJob(c *gin.Context) {
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.jobService.GetJob(ownerFromContext(c, accessTokenStr), c.Param("id"))
+	if err != nil {
+		respondWithError(c, err, "Failed to fetch job")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CancelJob handles DELETE /jobs/:id
+func (h *JobHandler) CancelJob(c *gin.Context) {
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.jobService.CancelJob(ownerFromContext(c, accessTokenStr), c.Param("id"))
+	if err != nil {
+		respondWithError(c, err, "Failed to cancel job")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// StreamJobEvents handles GET /jobs/:id/events
+// Job events are streamed as Server-Sent Events until the job finishes. A
+// reconnecting client sends the Last-Event-ID header (or ?last_event_id=) to
+// receive only the events it missed.
+func (h *JobHandler) StreamJobEvents(c *gin.Context) {
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+	owner := ownerFromContext(c, accessTokenStr)
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	afterID := 0
+	if lastEventID != "" {
+		id, err := strconv.Atoi(lastEventID)
+		if err != nil || id < 0 {
+			apiErr := models.NewBadRequestError("Last-Event-ID must be a positive number", err)
+			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+			return
+		}
+		afterID = id
+	}
+
+	// Fail with a regular JSON error before the stream starts
+	events, finished, changed, err := h.jobService.JobEvents(owner, c.Param("id"), afterID)
+	if err != nil {
+		respondWithError(c, err, "Failed to fetch job events")
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		for _, event := range events {
+			c.Render(-1, sse.Event{
+				Id:    strconv.Itoa(event.ID),
+				Event: event.Type,
+				Data:  event,
+			})
+			afterID = event.ID
+		}
+		c.Writer.Flush()
+
+		if finished {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-keepAlive.C:
+			io.WriteString(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+
+		events, finished, changed, err = h.jobService.JobEvents(owner, c.Param("id"), afterID)
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// MigrationHandler handles migration endpoints
+type MigrationHandler struct {
+	migrationService *services.MigrationService
+}
+
+// NewMigrationHandler creates a new MigrationHandler
+func NewMigrationHandler(migrationService *services.MigrationService) *MigrationHandler {
+	return &MigrationHandler{
+		migrationService: migrationService,
+	}
+}
+
+// CreateMigration handles POST /migrations
+// The source playlist is read with the caller's token and recreated with
+// destination_access_token from the body
+func (h *MigrationHandler) CreateMigration(c *gin.Context) {
+	// Get access token from context
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var request models.MigrationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := models.NewBadRequestError("Source playlist ID and destination access token are required", err)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return
+	}
+
+	response, err := h.migrationService.CopyPlaylist(c.Request.Context(), accessTokenStr, ownerFromContext(c, accessTokenStr), &request)
+	if err != nil {
+		respondWithError(c, err, "Failed to migrate playlist")
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// MigrateToDestination handles POST /migrate/:id?target=spotify
+// The playlist is read with the caller's token and rebuilt on the target
+// service with destination_access_token from the body
+func (h *MigrationHandler) MigrateToDestination(c *gin.Context) {
+	// Get access token from context
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	target := c.Query("target")
+	if target == "" {
+		apiErr := models.NewBadRequestError("Target is required", nil)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return
+	}
+
+	// Parse request body
+	var request models.DestinationMigrationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := models.NewBadRequestError("Destination access token is required", err)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return
+	}
+
+	response, err := h.migrationService.MigrateToDestination(c.Request.Context(), accessTokenStr, ownerFromContext(c, accessTokenStr), c.Param("id"), target, &request)
+	if err != nil {
+		respondWithError(c, err, "Failed to migrate playlist")
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetMigration handles GET /migrations/:id
+// Migrations started by someone else are reported as not found
+func (h *MigrationHandler) GetMigration(c *gin.Context) {
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.migrationService.GetMigration(ownerFromContext(c, accessTokenStr), c.Param("id"))
+	if err != nil {
+		respondWithError(c, err, "Failed to fetch migration")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResumeMigration handles POST /migrations/:id/resume
+// The source playlist is read again with the caller's token and the remaining
+// tracks are added with destination_access_token from the body
+func (h *MigrationHandler) ResumeMigration(c *gin.Context) {
+	// Get access token from context
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var request models.ResumeMigrationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := models.NewBadRequestError("Destination access token is required", err)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return
+	}
+
+	response, err := h.migrationService.ResumeMigration(c.Request.Context(), accessTokenStr, ownerFromContext(c, accessTokenStr), c.Param("id"), &request)
+	if err != nil {
+		respondWithError(c, err, "Failed to resume migration")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetReviewQueue handles GET /migrations/:id/review
+func (h *MigrationHandler) GetReviewQueue(c *gin.Context) {
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.migrationService.GetReviewQueue(ownerFromContext(c, accessTokenStr), c.Param("id"))
+	if err != nil {
+		respondWithError(c, err, "Failed to fetch review queue")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ReviewTrack handles POST /migrations/:id/review/:position
+func (h *MigrationHandler) ReviewTrack(c *gin.Context) {
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	position, err := strconv.Atoi(c.Param("position"))
+	if err != nil {
+		apiErr := models.NewBadRequestError("Position must be a number", err)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return
+	}
+
+	// Parse request body
+	var request models.ReviewDecisionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := models.NewBadRequestError("Action is required", err)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return
+	}
+
+	response, err := h.migrationService.ReviewTrack(ownerFromContext(c, accessTokenStr), c.Param("id"), position, &request)
+	if err != nil {
+		respondWithError(c, err, "Failed to review track")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CompleteMigration handles POST /migrations/:id/complete
+// Approved tracks are added with destination_access_token from the body
+func (h *MigrationHandler) CompleteMigration(c *gin.Context) {
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var request models.CompleteMigrationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := models.NewBadRequestError("Destination access token is required", err)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return
+	}
+
+	response, err := h.migrationService.CompleteMigration(c.Request.Context(), ownerFromContext(c, accessTokenStr), c.Param("id"), &request)
+	if err != nil {
+		respondWithError(c, err, "Failed to complete migration")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -24,17 +24,8 @@ func NewPlaylistHandler(playlistService *services.PlaylistService) *PlaylistHand
 // GetPlaylists handles GET /playlists
 func (h *PlaylistHandler) GetPlaylists(c *gin.Context) {
 	// Get access token from context
-	accessToken, exists := c.Get("access_token")
-	if !exists {
-		apiErr := models.NewUnauthorizedError("Access token not found", nil)
-		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		return
-	}
-
-	accessTokenStr, ok := accessToken.(string)
+	accessTokenStr, ok := accessTokenFromContext(c)
 	if !ok {
-		apiErr := models.NewUnauthorizedError("Invalid access token format", nil)
-		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
 		return
 	}
 
@@ -49,14 +40,9 @@ func (h *PlaylistHandler) GetPlaylists(c *gin.Context) {
 	pageToken := c.Query("page_token")
 
 	// Get playlists
-	response, err := h.playlistService.GetPlaylists(accessTokenStr, maxResults, pageToken)
+	response, err := h.playlistService.GetPlaylists(c.Request.Context(), accessTokenStr, maxResults, pageToken)
 	if err != nil {
-		if apiErr, ok := err.(*models.APIError); ok {
-			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		} else {
-			apiErr := models.NewInternalServerError("Failed to fetch playlists", err)
-			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		}
+		respondWithError(c, err, "Failed to fetch playlists")
 		return
 	}
 
@@ -66,17 +52,34 @@ func (h *PlaylistHandler) GetPlaylists(c *gin.Context) {
 // GetPlaylistByID handles GET /playlists/:id
 func (h *PlaylistHandler) GetPlaylistByID(c *gin.Context) {
 	// Get access token from context
-	accessToken, exists := c.Get("access_token")
-	if !exists {
-		apiErr := models.NewUnauthorizedError("Access token not found", nil)
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	// Get playlist ID from URL parameter
+	playlistID := c.Param("id")
+	if playlistID == "" {
+		apiErr := models.NewBadRequestError("Playlist ID is required", nil)
 		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
 		return
 	}
 
-	accessTokenStr, ok := accessToken.(string)
+	// Get playlist
+	response, err := h.playlistService.GetPlaylistByID(c.Request.Context(), accessTokenStr, playlistID)
+	if err != nil {
+		respondWithError(c, err, "Failed to fetch playlist")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPlaylistTracks handles GET /playlists/:id/tracks
+func (h *PlaylistHandler) GetPlaylistTracks(c *gin.Context) {
+	// Get access token from context
+	accessTokenStr, ok := accessTokenFromContext(c)
 	if !ok {
-		apiErr := models.NewUnauthorizedError("Invalid access token format", nil)
-		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
 		return
 	}
 
@@ -88,15 +91,9 @@ func (h *PlaylistHandler) GetPlaylistByID(c *gin.Context) {
 		return
 	}
 
-	// Get playlist
-	response, err := h.playlistService.GetPlaylistByID(accessTokenStr, playlistID)
+	response, err := h.playlistService.GetPlaylistTracks(c.Request.Context(), accessTokenStr, playlistID)
 	if err != nil {
-		if apiErr, ok := err.(*models.APIError); ok {
-			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		} else {
-			apiErr := models.NewInternalServerError("Failed to fetch playlist", err)
-			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		}
+		respondWithError(c, err, "Failed to fetch playlist tracks")
 		return
 	}
 
@@ -104,6 +101,7 @@ func (h *PlaylistHandler) GetPlaylistByID(c *gin.Context) {
 }
 
 // GetPlaylistSongs handles GET /playlists/:id/songs
+// Use ?page_token= to walk pages or ?all=true to fetch the whole playlist
 func (h *PlaylistHandler) GetPlaylistSongs(c *gin.Context) {
 	// Get access token from context
 	accessToken, exists := c.Get("access_token")
@@ -136,21 +134,147 @@ func (h *PlaylistHandler) GetPlaylistSongs(c *gin.Context) {
 		}
 	}
 
+	pageToken := c.Query("page_token")
+	all := c.Query("all") == "true"
+
 	// Get playlist songs
-	songs, err := h.playlistService.GetPlaylistSongs(accessTokenStr, playlistID, maxResults)
+	response, err := h.playlistService.GetPlaylistSongs(c.Request.Context(), accessTokenStr, playlistID, maxResults, pageToken, all)
 	if err != nil {
-		if apiErr, ok := err.(*models.APIError); ok {
-			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		} else {
-			apiErr := models.NewInternalServerError("Failed to fetch playlist songs", err)
-			c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
-		}
+		respondWithError(c, err, "Failed to fetch playlist songs")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreatePlaylist handles POST /playlists
+func (h *PlaylistHandler) CreatePlaylist(c *gin.Context) {
+	// Get access token from context
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var request models.CreatePlaylistRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := models.NewBadRequestError("Invalid request body", err)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return
+	}
+
+	response, err := h.playlistService.CreatePlaylist(c.Request.Context(), accessTokenStr, &request)
+	if err != nil {
+		respondWithError(c, err, "Failed to create playlist")
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdatePlaylist handles PUT /playlists/:id
+func (h *PlaylistHandler) UpdatePlaylist(c *gin.Context) {
+	// Get access token from context
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var request models.UpdatePlaylistRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := models.NewBadRequestError("Invalid request body", err)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return
+	}
+
+	response, err := h.playlistService.UpdatePlaylist(c.Request.Context(), accessTokenStr, c.Param("id"), &request)
+	if err != nil {
+		respondWithError(c, err, "Failed to update playlist")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeletePlaylist handles DELETE /playlists/:id
+func (h *PlaylistHandler) DeletePlaylist(c *gin.Context) {
+	// Get access token from context
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.playlistService.DeletePlaylist(c.Request.Context(), accessTokenStr, c.Param("id")); err != nil {
+		respondWithError(c, err, "Failed to delete playlist")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddPlaylistItem handles POST /playlists/:id/items
+func (h *PlaylistHandler) AddPlaylistItem(c *gin.Context) {
+	// Get access token from context
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var request models.AddPlaylistItemRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := models.NewBadRequestError("Video ID is required", err)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return
+	}
+
+	response, err := h.playlistService.AddPlaylistItem(c.Request.Context(), accessTokenStr, c.Param("id"), &request)
+	if err != nil {
+		respondWithError(c, err, "Failed to add video to playlist")
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// MovePlaylistItem handles PUT /playlists/:id/items/:item_id
+func (h *PlaylistHandler) MovePlaylistItem(c *gin.Context) {
+	// Get access token from context
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var request models.MovePlaylistItemRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := models.NewBadRequestError("Video ID and position are required", err)
+		c.JSON(apiErr.StatusCode, apiErr.ToErrorResponse())
+		return
+	}
+
+	response, err := h.playlistService.MovePlaylistItem(c.Request.Context(), accessTokenStr, c.Param("id"), c.Param("item_id"), &request)
+	if err != nil {
+		respondWithError(c, err, "Failed to move playlist item")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RemovePlaylistItem handles DELETE /playlists/:id/items/:item_id
+func (h *PlaylistHandler) RemovePlaylistItem(c *gin.Context) {
+	// Get access token from context
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.playlistService.RemovePlaylistItem(c.Request.Context(), accessTokenStr, c.Param("item_id")); err != nil {
+		respondWithError(c, err, "Failed to remove playlist item")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"songs":       songs,
-		"total_count": len(songs),
-		"playlist_id": playlistID,
-	})
+	c.Status(http.StatusNoContent)
 }
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube/youtubetest"
+)
+
+func TestGetPlaylistsPaging(t *testing.T) {
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	for i := 0; i < 7; i++ {
+		yt.AddPlaylist(youtubetest.NewPlaylist(fmt.Sprintf("PL%d", i), fmt.Sprintf("Playlist %d", i), "UC-test"))
+	}
+	router := newTestRouter(t, yt, 1)
+
+	var ids []string
+	target := "/api/playlists?max_results=3"
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination did not end")
+		}
+
+		var response models.PlaylistsResponse
+		expectStatus(t, serve(t, router, http.MethodGet, target, nil, &response), http.StatusOK)
+		if response.TotalCount != 7 {
+			t.Errorf("total_count = %d, want 7", response.TotalCount)
+		}
+		if len(response.Playlists) > 3 {
+			t.Errorf("page has %d playlists, want at most 3", len(response.Playlists))
+		}
+		for _, playlist := range response.Playlists {
+			ids = append(ids, playlist.ID)
+		}
+
+		if response.NextPageToken == "" {
+			break
+		}
+		target = "/api/playlists?max_results=3&page_token=" + response.NextPageToken
+	}
+
+	if want := []string{"PL0", "PL1", "PL2", "PL3", "PL4", "PL5", "PL6"}; fmt.Sprint(ids) != fmt.Sprint(want) {
+		t.Errorf("playlists = %v, want %v", ids, want)
+	}
+	if got := yt.Requests("playlists"); got != 3 {
+		t.Errorf("playlists requests = %d, want 3", got)
+	}
+}
+
+func TestGetPlaylistsErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		inject     youtubetest.Error
+		times      int
+		attempts   int
+		wantStatus int
+	}{
+		{name: "quota exceeded", inject: youtubetest.QuotaExceeded(), times: 1, attempts: 3, wantStatus: http.StatusForbidden},
+		{name: "rate limited", inject: youtubetest.RateLimitExceeded(), times: 3, attempts: 3, wantStatus: http.StatusTooManyRequests},
+		{name: "forbidden", inject: youtubetest.Forbidden(), times: 1, attempts: 3, wantStatus: http.StatusForbidden},
+		{name: "backend error", inject: youtubetest.InternalError(), times: 1, attempts: 1, wantStatus: http.StatusInternalServerError},
+		{name: "backend error is retried", inject: youtubetest.InternalError(), times: 2, attempts: 3, wantStatus: http.StatusOK},
+		{name: "revoked token", inject: youtubetest.Unauthorized(), times: 1, attempts: 3, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yt := youtubetest.NewServer()
+			defer yt.Close()
+			yt.AddPlaylist(youtubetest.NewPlaylist("PL1", "Playlist", "UC-test"))
+			yt.InjectError("playlists", tt.inject, tt.times)
+			router := newTestRouter(t, yt, tt.attempts)
+
+			var response models.ErrorResponse
+			expectStatus(t, serve(t, router, http.MethodGet, "/api/playlists", nil, &response), tt.wantStatus)
+			if tt.wantStatus != http.StatusOK && response.Message == "" {
+				t.Error("error response has no message")
+			}
+		})
+	}
+}
+
+func TestGetPlaylistsQuotaExhausted(t *testing.T) {
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	yt.AddPlaylist(youtubetest.NewPlaylist("PL1", "Playlist", "UC-test"))
+	yt.SetQuotaLimit(2)
+	router := newTestRouter(t, yt, 3)
+
+	for i := 0; i < 2; i++ {
+		expectStatus(t, serve(t, router, http.MethodGet, "/api/playlists", nil, nil), http.StatusOK)
+	}
+	expectStatus(t, serve(t, router, http.MethodGet, "/api/playlists", nil, nil), http.StatusForbidden)
+
+	// quotaExceeded is not transient, so it is not retried
+	if got := yt.Requests("playlists"); got != 3 {
+		t.Errorf("playlists requests = %d, want 3", got)
+	}
+}
+
+func TestGetPlaylistsQuotaBudget(t *testing.T) {
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	yt.AddPlaylist(youtubetest.NewPlaylist("PL1", "Playlist", "UC-test"))
+	// Room for two playlists.list calls in our own daily budget
+	router := newTestRouter(t, yt, 3, youtube.WithQuotaTracker(youtube.NewQuotaTracker(2)))
+
+	for i := 0; i < 2; i++ {
+		expectStatus(t, serve(t, router, http.MethodGet, "/api/playlists", nil, nil), http.StatusOK)
+	}
+	var response models.ErrorResponse
+	expectStatus(t, serve(t, router, http.MethodGet, "/api/playlists", nil, &response), http.StatusTooManyRequests)
+	if !strings.Contains(response.Message, "quota budget") {
+		t.Errorf("message = %q, want the budget reset time", response.Message)
+	}
+
+	// The budget is enforced before calling YouTube
+	if got := yt.Requests("playlists"); got != 2 {
+		t.Errorf("playlists requests = %d, want 2", got)
+	}
+}
+
+func TestGetPlaylistByIDNotFound(t *testing.T) {
+	tests := []struct {
+		name   string
+		inject bool
+	}{
+		{name: "unknown playlist"},
+		{name: "injected 404", inject: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yt := youtubetest.NewServer()
+			defer yt.Close()
+			yt.SeedPlaylist("PL1", "Playlist", 3)
+			target := "/api/playlists/missing"
+			if tt.inject {
+				yt.InjectError("playlists", youtubetest.NotFound("playlistNotFound"), 1)
+				target = "/api/playlists/PL1"
+			}
+			router := newTestRouter(t, yt, 3)
+
+			var response models.ErrorResponse
+			expectStatus(t, serve(t, router, http.MethodGet, target, nil, &response), http.StatusNotFound)
+			if response.Message != "Playlist not found" {
+				t.Errorf("message = %q, want %q", response.Message, "Playlist not found")
+			}
+		})
+	}
+}
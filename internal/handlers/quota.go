@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaHandler handles quota endpoints
+type QuotaHandler struct {
+	quotaService *services.QuotaService
+}
+
+// NewQuotaHandler creates a new QuotaHandler
+func NewQuotaHandler(quotaService *services.QuotaService) *QuotaHandler {
+	return &QuotaHandler{
+		quotaService: quotaService,
+	}
+}
+
+// GetQuota handles GET /quota
+func (h *QuotaHandler) GetQuota(c *gin.Context) {
+	// Get access token from context
+	accessTokenStr, ok := accessTokenFromContext(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.quotaService.GetQuota(c.Request.Context(), accessTokenStr, c.GetString("user_id"))
+	if err != nil {
+		respondWithError(c, err, "Failed to fetch quota usage")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
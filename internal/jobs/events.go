@@ -0,0 +1,83 @@
+package jobs
+
+import "time"
+
+// Event types
+const (
+	EventTrackFetched  = "track_fetched"
+	EventTrackMatched  = "track_matched"
+	EventTrackInserted = "track_inserted"
+	EventTrackSkipped  = "track_skipped"
+	EventTrackFailed   = "track_failed"
+	EventJobFinished   = "job_finished"
+)
+
+// Maximum number of events kept per job; older events are dropped first
+const maxEvents = 10000
+
+// Event is something that happened while a job was running. IDs start at 1
+// and increase by one per event, so a client can resume after the last ID it saw.
+type Event struct {
+	ID   int         `json:"id"`
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// JobFinishedData is the data of an EventJobFinished event
+type JobFinishedData struct {
+	State     string   `json:"state"`
+	Total     int      `json:"total"`
+	Processed int      `json:"processed"`
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// Events returns the events of a job with an ID greater than afterID. finished
+// reports whether the job is over, in which case no more events will follow.
+// Otherwise changed is closed as soon as a new event is recorded.
+func (m *Manager) Events(id string, afterID int) (events []Event, finished bool, changed <-chan struct{}, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, false, nil, ErrNotFound
+	}
+
+	// Events are numbered consecutively, so the first one to return is found by offset
+	skip := afterID - (j.lastEventID - len(j.events))
+	if skip < 0 {
+		skip = 0
+	}
+	if skip < len(j.events) {
+		events = make([]Event, 0, len(j.events)-skip)
+		for i := skip; i < len(j.events); i++ {
+			events = append(events, j.events[(j.eventsStart+i)%len(j.events)])
+		}
+	}
+
+	return events, j.Finished(), j.changed, nil
+}
+
+// emit records an event and wakes up the clients waiting for it. Once
+// maxEvents are kept, each event overwrites the oldest one. The caller must
+// hold the manager lock.
+func (j *job) emit(eventType string, data interface{}) {
+	j.lastEventID++
+	event := Event{
+		ID:   j.lastEventID,
+		Type: eventType,
+		Time: time.Now(),
+		Data: data,
+	}
+	if len(j.events) < maxEvents {
+		j.events = append(j.events, event)
+	} else {
+		j.events[j.eventsStart] = event
+		j.eventsStart = (j.eventsStart + 1) % maxEvents
+	}
+
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
@@ -0,0 +1,89 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+)
+
+// newEventJob registers a job with n events in a manager without workers
+func newEventJob(t *testing.T, n int) *Manager {
+	t.Helper()
+
+	m := &Manager{jobs: make(map[string]*job)}
+	j := &job{Job: Job{ID: "job", State: StateRunning}, changed: make(chan struct{})}
+	for i := 0; i < n; i++ {
+		j.emit(EventTrackInserted, i+1)
+	}
+	m.jobs[j.ID] = j
+	return m
+}
+
+func TestEventsAfter(t *testing.T) {
+	tests := []struct {
+		name      string
+		emitted   int
+		afterID   int
+		wantFirst int // ID of the first event returned, 0 for none
+		wantCount int
+	}{
+		{name: "no events", emitted: 0, afterID: 0},
+		{name: "all events", emitted: 5, afterID: 0, wantFirst: 1, wantCount: 5},
+		{name: "resume", emitted: 5, afterID: 3, wantFirst: 4, wantCount: 2},
+		{name: "up to date", emitted: 5, afterID: 5},
+		{name: "ahead of the job", emitted: 5, afterID: 9},
+		{name: "buffer just full", emitted: maxEvents, afterID: 0, wantFirst: 1, wantCount: maxEvents},
+		{name: "oldest overwritten", emitted: maxEvents + 7, afterID: 0, wantFirst: 8, wantCount: maxEvents},
+		{name: "resume after overwrite", emitted: maxEvents + 7, afterID: maxEvents + 2, wantFirst: maxEvents + 3, wantCount: 5},
+		{name: "wrapped twice", emitted: 2*maxEvents + 3, afterID: 2*maxEvents - 1, wantFirst: 2 * maxEvents, wantCount: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newEventJob(t, tt.emitted)
+
+			events, finished, changed, err := m.Events("job", tt.afterID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if finished || changed == nil {
+				t.Errorf("finished = %v, changed = %v for a running job", finished, changed)
+			}
+			if len(events) != tt.wantCount {
+				t.Fatalf("got %d events, want %d", len(events), tt.wantCount)
+			}
+			for i, event := range events {
+				if want := tt.wantFirst + i; event.ID != want || event.Data != want {
+					t.Fatalf("event %d has ID %d and data %v, want %d", i, event.ID, event.Data, want)
+				}
+			}
+			if got := len(m.jobs["job"].events); got > maxEvents {
+				t.Errorf("%d events kept, want at most %d", got, maxEvents)
+			}
+		})
+	}
+}
+
+func TestEventsWakeUpWaitingClients(t *testing.T) {
+	m := newEventJob(t, 1)
+
+	_, _, changed, err := m.Events("job", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+		t.Fatal("changed closed before a new event")
+	default:
+	}
+
+	m.jobs["job"].emit(EventTrackSkipped, nil)
+	select {
+	case <-changed:
+	default:
+		t.Fatal("changed not closed by a new event")
+	}
+
+	if _, _, _, err := m.Events("missing", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown job: %v, want ErrNotFound", err)
+	}
+}
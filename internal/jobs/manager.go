@@ -0,0 +1,395 @@
+// Package jobs runs long operations such as exports and migrations in a
+// bounded pool of background workers
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job states
+const (
+	StateQueued    = "queued"
+	StateRunning   = "running"
+	StateSucceeded = "succeeded"
+	StateFailed    = "failed"
+	StateCancelled = "cancelled"
+)
+
+// DefaultRetention is how long a finished job stays in memory when NewManager
+// is given no retention
+const DefaultRetention = time.Hour
+
+// Errors returned by Manager
+var (
+	ErrQueueFull    = errors.New("job queue is full")
+	ErrShuttingDown = errors.New("job manager is shutting down")
+	ErrNotFound     = errors.New("job not found")
+	ErrFinished     = errors.New("job already finished")
+)
+
+// Func is the work done by a job. Progress is reported through the Progress
+// attached to ctx (see ProgressFrom); the returned value is kept as the job result.
+type Func func(ctx context.Context) (interface{}, error)
+
+// Job is a snapshot of a job's state
+type Job struct {
+	ID         string
+	Type       string
+	Owner      string // Who submitted the job; see Submit
+	State      string
+	Total      int
+	Processed  int
+	Failed     int
+	Errors     []string
+	Result     interface{}
+	CreatedAt  time.Time
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
+
+// Percent returns how much of the job is done, from 0 to 100
+func (j *Job) Percent() float64 {
+	switch {
+	case j.State == StateSucceeded:
+		return 100
+	case j.Total <= 0:
+		return 0
+	default:
+		return float64(j.Processed) * 100 / float64(j.Total)
+	}
+}
+
+// Finished reports whether the job reached a final state
+func (j *Job) Finished() bool {
+	return j.State == StateSucceeded || j.State == StateFailed || j.State == StateCancelled
+}
+
+// job is a Job plus the data needed to run, cancel and follow it
+type job struct {
+	Job
+	fn      Func
+	timeout time.Duration
+	cancel  context.CancelFunc
+
+	// version counts state changes. Callbacks run outside the manager lock
+	// and may race, so notifyMu and notified keep them in order.
+	version  int
+	notifyMu sync.Mutex
+	notified int
+
+	events      []Event // Ring buffer of the last maxEvents events
+	eventsStart int     // Index of the oldest event once events is full
+	lastEventID int
+	changed     chan struct{} // Closed and replaced whenever an event is recorded
+}
+
+// Manager queues jobs and runs them on a fixed number of workers
+type Manager struct {
+	queue chan *job
+
+	// ctx is the parent of every job context; cancelling it aborts running jobs
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// retention is how long finished jobs, with their result and events, are kept
+	retention time.Duration
+
+	mu       sync.RWMutex
+	jobs     map[string]*job
+	closing  bool
+	onChange func(Job)
+}
+
+// NewManager starts a Manager with the given number of workers. At most
+// queueSize jobs can wait for a free worker. Finished jobs are dropped once
+// they have been over for retention (DefaultRetention when zero).
+func NewManager(workers, queueSize int, retention time.Duration) *Manager {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		queue:     make(chan *job, queueSize),
+		ctx:       ctx,
+		cancel:    cancel,
+		retention: retention,
+		jobs:      make(map[string]*job),
+	}
+
+	m.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go m.worker()
+	}
+
+	return m
+}
+
+// OnStateChange registers fn to be called with a snapshot whenever a job is
+// queued, starts or finishes, e.g. to persist job records. fn runs on the
+// goroutine that changed the job, outside the manager lock. Calls for the
+// same job never overlap, and a state that was overtaken by a newer one
+// before fn could see it is skipped, so the last call always carries the
+// latest state.
+func (m *Manager) OnStateChange(fn func(Job)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onChange = fn
+}
+
+// Submit queues a job on behalf of owner, an opaque caller identity the
+// manager only records. A timeout greater than zero bounds how long it may run.
+func (m *Manager) Submit(jobType, owner string, timeout time.Duration, fn Func) (*Job, error) {
+	m.mu.Lock()
+
+	if m.closing {
+		m.mu.Unlock()
+		return nil, ErrShuttingDown
+	}
+	m.prune(time.Now())
+
+	j := &job{
+		Job: Job{
+			ID:        newID(),
+			Type:      jobType,
+			Owner:     owner,
+			State:     StateQueued,
+			CreatedAt: time.Now(),
+		},
+		fn:      fn,
+		timeout: timeout,
+		changed: make(chan struct{}),
+	}
+
+	select {
+	case m.queue <- j:
+	default:
+		m.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+
+	m.jobs[j.ID] = j
+	snapshot, notice := j.snapshot(), m.changed(j)
+	m.mu.Unlock()
+
+	notice()
+	return snapshot, nil
+}
+
+// Get returns a snapshot of a job
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return j.snapshot(), nil
+}
+
+// Cancel stops a queued or running job
+func (m *Manager) Cancel(id string) (*Job, error) {
+	m.mu.Lock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, ErrNotFound
+	}
+
+	notice := func() {}
+	switch j.State {
+	case StateQueued:
+		// The worker drops it when it is dequeued
+		j.finish(StateCancelled, nil)
+		notice = m.changed(j)
+	case StateRunning:
+		// The worker records the final state once fn returns
+		j.cancel()
+	default:
+		m.mu.Unlock()
+		return nil, ErrFinished
+	}
+
+	snapshot := j.snapshot()
+	m.mu.Unlock()
+
+	notice()
+	return snapshot, nil
+}
+
+// Shutdown stops accepting jobs and waits for queued and running jobs to
+// finish. When ctx is done first, the remaining jobs are cancelled and
+// Shutdown returns once the workers have stopped.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.closing {
+		m.closing = true
+		close(m.queue)
+	}
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		m.cancel()
+		return nil
+	case <-ctx.Done():
+		m.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// prune drops the jobs that finished more than the retention period ago. The
+// caller must hold the lock.
+func (m *Manager) prune(now time.Time) {
+	for id, j := range m.jobs {
+		if j.FinishedAt != nil && now.Sub(*j.FinishedAt) > m.retention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// worker runs queued jobs until the queue is closed
+func (m *Manager) worker() {
+	defer m.wg.Done()
+
+	for j := range m.queue {
+		m.run(j)
+	}
+}
+
+// run executes a single job
+func (m *Manager) run(j *job) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if j.timeout > 0 {
+		ctx, cancel = context.WithTimeout(m.ctx, j.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(m.ctx)
+	}
+	defer cancel()
+
+	m.mu.Lock()
+	if j.State != StateQueued {
+		// Cancelled while waiting in the queue
+		m.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	j.State = StateRunning
+	j.StartedAt = &now
+	j.cancel = cancel
+	notice := m.changed(j)
+	m.mu.Unlock()
+
+	notice()
+
+	ctx = withProgress(ctx, &Progress{manager: m, job: j})
+	result, err := m.call(ctx, j.fn)
+
+	m.mu.Lock()
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled):
+		j.finish(StateCancelled, err)
+	case err != nil:
+		j.finish(StateFailed, err)
+	default:
+		j.Result = result
+		j.finish(StateSucceeded, nil)
+	}
+	snapshot, notice := j.snapshot(), m.changed(j)
+	m.mu.Unlock()
+
+	notice()
+
+	log.Printf("Job %s (%s) %s: %d/%d processed, %d failed", snapshot.ID, snapshot.Type, snapshot.State, snapshot.Processed, snapshot.Total, snapshot.Failed)
+}
+
+// call runs fn, turning a panic into an error so it cannot kill the worker
+func (m *Manager) call(ctx context.Context, fn Func) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// changed records a state change of j and returns the function that reports
+// it to the state change callback, if any. The caller must hold the lock to
+// call changed, and must release it before calling the returned function.
+func (m *Manager) changed(j *job) func() {
+	j.version++
+	version, snapshot, onChange := j.version, j.snapshot(), m.onChange
+	if onChange == nil {
+		return func() {}
+	}
+
+	return func() {
+		j.notifyMu.Lock()
+		defer j.notifyMu.Unlock()
+
+		if version < j.notified {
+			// A newer state was already reported
+			return
+		}
+		j.notified = version
+		onChange(*snapshot)
+	}
+}
+
+// finish records the final state of a job. The caller must hold the manager lock.
+func (j *job) finish(state string, err error) {
+	now := time.Now()
+	j.State = state
+	j.FinishedAt = &now
+	if err != nil {
+		j.Errors = append(j.Errors, err.Error())
+	}
+
+	j.emit(EventJobFinished, JobFinishedData{
+		State:     j.State,
+		Total:     j.Total,
+		Processed: j.Processed,
+		Failed:    j.Failed,
+		Errors:    append([]string(nil), j.Errors...),
+	})
+}
+
+// snapshot copies the public state of a job. The caller must hold the manager lock.
+func (j *job) snapshot() *Job {
+	snapshot := j.Job
+	snapshot.Errors = append([]string(nil), j.Errors...)
+	return &snapshot
+}
+
+// newID returns a random job identifier
+func newID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,135 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// wait polls a job until it finishes
+func wait(t *testing.T, m *Manager, id string) *Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Finished() {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return nil
+}
+
+func TestManagerEvictsFinishedJobs(t *testing.T) {
+	m := NewManager(2, 10, 20*time.Millisecond)
+	defer m.Shutdown(context.Background())
+
+	done := func(ctx context.Context) (interface{}, error) { return "done", nil }
+	release := make(chan struct{})
+	blocked := func(ctx context.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	}
+
+	finished, err := m.Submit("test", "owner", 0, done)
+	if err != nil {
+		t.Fatal(err)
+	}
+	running, err := m.Submit("test", "owner", 0, blocked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close(release)
+	wait(t, m, finished.ID)
+
+	// Within the retention period the result can still be read
+	if job, err := m.Get(finished.ID); err != nil || job.Result != "done" {
+		t.Fatalf("finished job = %+v, %v", job, err)
+	}
+
+	// The next submission drops it, but not the job that is still running
+	time.Sleep(40 * time.Millisecond)
+	if _, err := m.Submit("test", "owner", 0, done); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Get(finished.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expired job: %v, want ErrNotFound", err)
+	}
+	if _, _, _, err := m.Events(finished.ID, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("events of expired job: %v, want ErrNotFound", err)
+	}
+	if job, err := m.Get(running.ID); err != nil || job.State != StateRunning {
+		t.Errorf("running job = %+v, %v", job, err)
+	}
+}
+
+func TestManagerDefaultRetention(t *testing.T) {
+	m := NewManager(1, 1, 0)
+	defer m.Shutdown(context.Background())
+
+	if m.retention != DefaultRetention {
+		t.Errorf("retention = %s, want %s", m.retention, DefaultRetention)
+	}
+}
+
+func TestManagerDropsOvertakenStateChanges(t *testing.T) {
+	m := NewManager(1, 1, 0)
+	defer m.Shutdown(context.Background())
+
+	var states []string
+	m.OnStateChange(func(job Job) { states = append(states, job.State) })
+
+	// The worker reports a job as running before Submit gets to report it
+	// as queued
+	j := &job{Job: Job{ID: "job", State: StateQueued}}
+	m.mu.Lock()
+	queued := m.changed(j)
+	j.State = StateRunning
+	running := m.changed(j)
+	m.mu.Unlock()
+
+	running()
+	queued()
+
+	if len(states) != 1 || states[0] != StateRunning {
+		t.Errorf("reported states = %v, want [running]", states)
+	}
+}
+
+func TestManagerReportsStatesInOrder(t *testing.T) {
+	m := NewManager(4, 100, 0)
+
+	var mu sync.Mutex
+	last := make(map[string]string)
+	m.OnStateChange(func(job Job) {
+		mu.Lock()
+		last[job.ID] = job.State
+		mu.Unlock()
+	})
+
+	done := func(ctx context.Context) (interface{}, error) { return nil, nil }
+	var ids []string
+	for i := 0; i < 100; i++ {
+		job, err := m.Submit("test", "owner", 0, done)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, job.ID)
+	}
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range ids {
+		if last[id] != StateSucceeded {
+			t.Errorf("job %s last reported as %q, want %q", id, last[id], StateSucceeded)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package jobs
+
+import "context"
+
+// Maximum number of error messages kept per job
+const maxErrors = 100
+
+type progressKey struct{}
+
+// Progress reports the progress of the job running with a context. A nil
+// Progress ignores every update, so code shared with synchronous requests can
+// report unconditionally.
+type Progress struct {
+	manager *Manager
+	job     *job
+}
+
+// withProgress attaches a Progress to ctx
+func withProgress(ctx context.Context, p *Progress) context.Context {
+	return context.WithValue(ctx, progressKey{}, p)
+}
+
+// ProgressFrom returns the Progress attached to ctx, or nil outside a job
+func ProgressFrom(ctx context.Context) *Progress {
+	p, _ := ctx.Value(progressKey{}).(*Progress)
+	return p
+}
+
+// SetTotal sets the number of items the job will process
+func (p *Progress) SetTotal(total int) {
+	if p == nil {
+		return
+	}
+	p.manager.mu.Lock()
+	defer p.manager.mu.Unlock()
+	p.job.Total = total
+}
+
+// Advance marks n items as processed
+func (p *Progress) Advance(n int) {
+	if p == nil {
+		return
+	}
+	p.manager.mu.Lock()
+	defer p.manager.mu.Unlock()
+	p.job.Processed += n
+}
+
+// Fail marks n items as processed and failed with the given message
+func (p *Progress) Fail(n int, message string) {
+	if p == nil {
+		return
+	}
+	p.manager.mu.Lock()
+	defer p.manager.mu.Unlock()
+	p.job.Processed += n
+	p.job.Failed += n
+	if len(p.job.Errors) < maxErrors {
+		p.job.Errors = append(p.job.Errors, message)
+	}
+}
+
+// Emit records an event that clients following the job receive
+func (p *Progress) Emit(eventType string, data interface{}) {
+	if p == nil {
+		return
+	}
+	p.manager.mu.Lock()
+	defer p.manager.mu.Unlock()
+	p.job.emit(eventType, data)
+}
@@ -0,0 +1,213 @@
+// Package matching turns YouTube video titles into structured track queries
+// that can be searched on other music services
+package matching
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// TrackQuery is the artist and track parsed from a video title
+type TrackQuery struct {
+	Artist   string   `json:"artist"`
+	Title    string   `json:"title"`
+	Featured []string `json:"featured,omitempty"`
+}
+
+// String returns the query as a single search string ("Artist Title")
+func (q TrackQuery) String() string {
+	return strings.TrimSpace(q.Artist + " " + q.Title)
+}
+
+var (
+	// Any (...), [...], {...} or 【...】 group
+	bracketPattern = regexp.MustCompile(`[(\[{【]([^()\[\]{}【】]*)[)\]}】]`)
+
+	// Words that mark a bracketed group or a trailing segment as noise
+	noisePattern = regexp.MustCompile(`(?i)\b(official|oficial|video|vídeo|audio|lyrics?|letra|visuali[sz]er|hd|hq|4k|1080p|720p|mv|m/v|explicit|clean|remaster(ed)?|full album|color coded|radio edit|single version|album version)\b`)
+
+	// Words that may accompany noise words without being part of a title
+	// ("Official Music Video", "2004 Remastered Version")
+	noiseFillerPattern = regexp.MustCompile(`(?i)\b(music|musical|new|version|versión|digital(ly)?|\d{4}|with|con|sub(s|titulado)?|eng|esp|rom|han)\b`)
+
+	// Trailing noise that is not wrapped in brackets ("Song Official Video")
+	trailingNoisePattern = regexp.MustCompile(`(?i)\s+(official\s+(music\s+)?(video|audio)|video\s+oficial|audio\s+oficial|lyric\s+video|lyrics|letra)$`)
+
+	// Remaster tags written as a suffix ("Song - Remastered 2011", "Song - 2011 Remaster")
+	remasterPattern = regexp.MustCompile(`(?i)\s+[-–—]\s+(\d{4}\s+)?(digital(ly)?\s+)?remaster(ed)?(\s+\d{4})?(\s+version)?$`)
+
+	// Edition tags that catalogues append to the original recording ("Song - Radio Edit")
+	editionPattern = regexp.MustCompile(`(?i)\s+[-–—]\s+(radio edit|single version|album version|original mix|mono|stereo)$`)
+
+	// Featured artist credits
+	featBracketPattern = regexp.MustCompile(`(?i)^\s*(feat\.?|ft\.?|featuring)\s+(.+)$`)
+	featInlinePattern  = regexp.MustCompile(`(?i)(^|\s)(feat\.?|ft\.?|featuring)\s+`)
+	featListPattern    = regexp.MustCompile(`\s*,\s*`)
+	featLastPattern    = regexp.MustCompile(`(?i)\s*&\s*|\s+(and|y)\s+`)
+
+	// Separator between artist and track
+	separatorPattern = regexp.MustCompile(`\s+[-–—~]\s+`)
+
+	// Artist "Track" titles
+	quotedPattern = regexp.MustCompile(`^(.+?)\s+["“](.+?)["”]$`)
+
+	// Suffixes added to artist channel names
+	channelSuffixPattern = regexp.MustCompile(`(?i)(\s*-\s*topic|vevo|\s+official|\s+oficial|\s+music)$`)
+
+	spacePattern = regexp.MustCompile(`\s+`)
+)
+
+// Parse splits a video title into artist and track. Noise such as
+// "(Official Video)" or "[HD]", remaster tags, featured credits and emoji are
+// removed. When the title has no artist, the channel name is used instead, and
+// "Artist - Topic" channels always provide the artist.
+func Parse(title, channelTitle string) TrackQuery {
+	var query TrackQuery
+
+	s := stripEmoji(title)
+
+	// Bracketed groups are either featured credits, noise or kept as part of
+	// the title ("(Acoustic)", "(Live at Wembley)")
+	s = bracketPattern.ReplaceAllStringFunc(s, func(group string) string {
+		content := bracketPattern.FindStringSubmatch(group)[1]
+		if m := featBracketPattern.FindStringSubmatch(content); m != nil {
+			query.Featured = append(query.Featured, splitArtists(m[2])...)
+			return " "
+		}
+		if isNoise(content) {
+			return " "
+		}
+		return group
+	})
+	s = stripTrailingSegments(s)
+
+	artist, track := split(s)
+	if topic, ok := topicArtist(channelTitle); ok {
+		// Auto-generated "Topic" videos are titled with the track alone
+		artist, track = topic, s
+	} else if artist == "" {
+		artist = cleanChannel(channelTitle)
+	}
+
+	artist, featured := cutFeatured(artist)
+	query.Featured = append(query.Featured, featured...)
+	track, featured = cutFeatured(track)
+	query.Featured = append(query.Featured, featured...)
+
+	track = remasterPattern.ReplaceAllString(clean(track), "")
+	track = editionPattern.ReplaceAllString(track, "")
+	track = trailingNoisePattern.ReplaceAllString(track, "")
+
+	query.Artist = clean(artist)
+	query.Title = clean(track)
+	return query
+}
+
+// stripTrailingSegments drops "| Official Video" style segments after a pipe
+// or double slash
+func stripTrailingSegments(s string) string {
+	for _, sep := range []string{"|", "//"} {
+		parts := strings.Split(s, sep)
+		for len(parts) > 1 && isNoise(parts[len(parts)-1]) {
+			parts = parts[:len(parts)-1]
+		}
+		s = strings.Join(parts, sep)
+	}
+	return s
+}
+
+// isNoise reports whether a bracketed group or trailing segment holds only
+// noise. A group that merely contains a noise word is part of the title, as
+// in "(Video Killed the Radio Star)" or "(Acoustic Audio Session)".
+func isNoise(s string) bool {
+	if !noisePattern.MatchString(s) {
+		return false
+	}
+	rest := noiseFillerPattern.ReplaceAllString(noisePattern.ReplaceAllString(s, " "), " ")
+	return strings.IndexFunc(rest, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) < 0
+}
+
+// split separates "Artist - Track" or `Artist "Track"` titles. The artist is
+// empty when the title has no separator.
+func split(s string) (artist, track string) {
+	if loc := separatorPattern.FindStringIndex(s); loc != nil {
+		return s[:loc[0]], s[loc[1]:]
+	}
+	if m := quotedPattern.FindStringSubmatch(strings.TrimSpace(s)); m != nil {
+		return m[1], m[2]
+	}
+	return "", s
+}
+
+// cutFeatured removes an inline "feat. X" credit and returns the artists it lists
+func cutFeatured(s string) (string, []string) {
+	loc := featInlinePattern.FindStringIndex(s)
+	if loc == nil {
+		return s, nil
+	}
+	return s[:loc[0]], splitArtists(s[loc[1]:])
+}
+
+// splitArtists splits "A, B & C" into its artists. "&", "and" and "y" only
+// separate the last two artists of a comma list, since on their own they
+// usually join a single act such as "Jesse y Joy" or "Wisin & Yandel".
+func splitArtists(s string) []string {
+	names := featListPattern.Split(s, -1)
+	if len(names) > 1 {
+		last := names[len(names)-1]
+		names = append(names[:len(names)-1], featLastPattern.Split(last, 2)...)
+	}
+
+	var artists []string
+	for _, name := range names {
+		if name = clean(name); name != "" {
+			artists = append(artists, name)
+		}
+	}
+	return artists
+}
+
+// topicArtist returns the artist of an auto-generated "Artist - Topic" channel
+func topicArtist(channelTitle string) (string, bool) {
+	name := strings.TrimSpace(channelTitle)
+	if !strings.HasSuffix(strings.ToLower(name), " - topic") {
+		return "", false
+	}
+	return clean(name[:len(name)-len(" - topic")]), true
+}
+
+// cleanChannel removes suffixes such as "VEVO" or "Official" from a channel name
+func cleanChannel(channelTitle string) string {
+	name := strings.TrimSpace(channelTitle)
+	for {
+		trimmed := strings.TrimSpace(channelSuffixPattern.ReplaceAllString(name, ""))
+		if trimmed == name || trimmed == "" {
+			return clean(name)
+		}
+		name = trimmed
+	}
+}
+
+// stripEmoji removes emoji and other pictographic symbols
+func stripEmoji(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '‍' || r == '️' || r == '︎':
+			return -1
+		case unicode.Is(unicode.So, r):
+			return -1
+		case r >= 0x1f000:
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// clean collapses whitespace and trims separators and quotes left at the edges
+func clean(s string) string {
+	s = spacePattern.ReplaceAllString(s, " ")
+	return strings.Trim(s, " -–—~|/,:\"“”")
+}
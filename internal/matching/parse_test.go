@@ -0,0 +1,381 @@
+package matching
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   string
+		channel string
+		want    TrackQuery
+	}{
+		// Plain "Artist - Track" titles and noise
+		{
+			name:    "plain separator",
+			title:   "Daft Punk - Get Lucky",
+			channel: "Daft Punk",
+			want:    TrackQuery{Artist: "Daft Punk", Title: "Get Lucky"},
+		},
+		{
+			name:    "official video in brackets",
+			title:   "Artist - Song (Official Video) [HD]",
+			channel: "ArtistVEVO",
+			want:    TrackQuery{Artist: "Artist", Title: "Song"},
+		},
+		{
+			name:    "official music video",
+			title:   "Rick Astley - Never Gonna Give You Up (Official Music Video)",
+			channel: "Rick Astley",
+			want:    TrackQuery{Artist: "Rick Astley", Title: "Never Gonna Give You Up"},
+		},
+		{
+			name:    "spanish noise",
+			title:   "Shakira - Hips Don't Lie (Video Oficial)",
+			channel: "shakiraVEVO",
+			want:    TrackQuery{Artist: "Shakira", Title: "Hips Don't Lie"},
+		},
+		{
+			name:    "lyrics in curly brackets",
+			title:   "Adele - Hello {Lyrics}",
+			channel: "Lyrics Channel",
+			want:    TrackQuery{Artist: "Adele", Title: "Hello"},
+		},
+		{
+			name:    "cjk brackets",
+			title:   "BTS - Dynamite 【MV】",
+			channel: "HYBE LABELS",
+			want:    TrackQuery{Artist: "BTS", Title: "Dynamite"},
+		},
+		{
+			name:    "trailing noise without brackets",
+			title:   "Coldplay - Yellow Official Video",
+			channel: "Coldplay",
+			want:    TrackQuery{Artist: "Coldplay", Title: "Yellow"},
+		},
+		{
+			name:    "pipe segment",
+			title:   "Dua Lipa - Levitating | Official Music Video",
+			channel: "Dua Lipa",
+			want:    TrackQuery{Artist: "Dua Lipa", Title: "Levitating"},
+		},
+		{
+			name:    "double slash segment",
+			title:   "Rosalía - Malamente // Lyric Video",
+			channel: "Rosalía",
+			want:    TrackQuery{Artist: "Rosalía", Title: "Malamente"},
+		},
+		{
+			name:    "en dash separator",
+			title:   "Radiohead – Creep",
+			channel: "Radiohead",
+			want:    TrackQuery{Artist: "Radiohead", Title: "Creep"},
+		},
+		{
+			name:    "tilde separator",
+			title:   "Nujabes ~ Feather",
+			channel: "lofi uploads",
+			want:    TrackQuery{Artist: "Nujabes", Title: "Feather"},
+		},
+
+		// Topic channels
+		{
+			name:    "topic channel",
+			title:   "Bohemian Rhapsody",
+			channel: "Queen - Topic",
+			want:    TrackQuery{Artist: "Queen", Title: "Bohemian Rhapsody"},
+		},
+		{
+			name:    "topic channel with dash in track",
+			title:   "Under Pressure - Remastered 2011",
+			channel: "Queen - Topic",
+			want:    TrackQuery{Artist: "Queen", Title: "Under Pressure"},
+		},
+		{
+			name:    "topic channel with feat in track",
+			title:   "Umbrella (feat. JAY-Z)",
+			channel: "Rihanna - Topic",
+			want:    TrackQuery{Artist: "Rihanna", Title: "Umbrella", Featured: []string{"JAY-Z"}},
+		},
+		{
+			name:    "topic channel lowercase suffix",
+			title:   "Blinding Lights",
+			channel: "The Weeknd - topic",
+			want:    TrackQuery{Artist: "The Weeknd", Title: "Blinding Lights"},
+		},
+
+		// Featured artists
+		{
+			name:    "feat in brackets",
+			title:   "Mark Ronson - Uptown Funk (feat. Bruno Mars)",
+			channel: "Mark Ronson",
+			want:    TrackQuery{Artist: "Mark Ronson", Title: "Uptown Funk", Featured: []string{"Bruno Mars"}},
+		},
+		{
+			name:    "ft in square brackets",
+			title:   "Calvin Harris - This Is What You Came For [ft. Rihanna]",
+			channel: "CalvinHarrisVEVO",
+			want:    TrackQuery{Artist: "Calvin Harris", Title: "This Is What You Came For", Featured: []string{"Rihanna"}},
+		},
+		{
+			name:    "featuring in brackets",
+			title:   "Eminem - Love The Way You Lie (featuring Rihanna)",
+			channel: "EminemVEVO",
+			want:    TrackQuery{Artist: "Eminem", Title: "Love The Way You Lie", Featured: []string{"Rihanna"}},
+		},
+		{
+			name:    "feat inline after artist",
+			title:   "Pitbull feat. Ne-Yo - Give Me Everything",
+			channel: "PitbullVEVO",
+			want:    TrackQuery{Artist: "Pitbull", Title: "Give Me Everything", Featured: []string{"Ne-Yo"}},
+		},
+		{
+			name:    "ft inline after track",
+			title:   "Luis Fonsi - Despacito ft. Daddy Yankee",
+			channel: "LuisFonsiVEVO",
+			want:    TrackQuery{Artist: "Luis Fonsi", Title: "Despacito", Featured: []string{"Daddy Yankee"}},
+		},
+		{
+			name:    "feat list with comma and ampersand",
+			title:   "DJ Khaled - Wild Thoughts (feat. Rihanna, Bryson Tiller & Santana)",
+			channel: "DJKhaledVEVO",
+			want:    TrackQuery{Artist: "DJ Khaled", Title: "Wild Thoughts", Featured: []string{"Rihanna", "Bryson Tiller", "Santana"}},
+		},
+		{
+			name:    "feat list with comma and y",
+			title:   "J Balvin - Ay Vamos (ft. Nicky Jam, Farruko y Ozuna)",
+			channel: "J Balvin",
+			want:    TrackQuery{Artist: "J Balvin", Title: "Ay Vamos", Featured: []string{"Nicky Jam", "Farruko", "Ozuna"}},
+		},
+		{
+			name:    "feat and noise together",
+			title:   "Artist - Song (feat. Guest) (Official Video)",
+			channel: "Artist",
+			want:    TrackQuery{Artist: "Artist", Title: "Song", Featured: []string{"Guest"}},
+		},
+
+		// Names that must not be split
+		{
+			name:    "spanish y inside a duo name",
+			title:   "Reik - Me Niego (feat. Jesse y Joy)",
+			channel: "ReikVEVO",
+			want:    TrackQuery{Artist: "Reik", Title: "Me Niego", Featured: []string{"Jesse y Joy"}},
+		},
+		{
+			name:    "ampersand inside a duo name",
+			title:   "Daddy Yankee - Noche de Entierro (ft. Wisin & Yandel)",
+			channel: "Daddy Yankee",
+			want:    TrackQuery{Artist: "Daddy Yankee", Title: "Noche de Entierro", Featured: []string{"Wisin & Yandel"}},
+		},
+		{
+			name:    "and inside a band name",
+			title:   "Calvin Harris - Sweet Nothing (feat. Florence and the Machine)",
+			channel: "CalvinHarrisVEVO",
+			want:    TrackQuery{Artist: "Calvin Harris", Title: "Sweet Nothing", Featured: []string{"Florence and the Machine"}},
+		},
+		{
+			name:    "artist with y is kept whole",
+			title:   "Jesse y Joy - Corre!",
+			channel: "JesseYJoyVEVO",
+			want:    TrackQuery{Artist: "Jesse y Joy", Title: "Corre!"},
+		},
+		{
+			name:    "bracketed title that contains a noise word",
+			title:   "The Buggles - (Video Killed the Radio Star)",
+			channel: "The Buggles",
+			want:    TrackQuery{Artist: "The Buggles", Title: "(Video Killed the Radio Star)"},
+		},
+		{
+			name:    "kept group with noise word after the track",
+			title:   "Ed Sheeran - Shape of You (Acoustic Audio Session)",
+			channel: "Ed Sheeran",
+			want:    TrackQuery{Artist: "Ed Sheeran", Title: "Shape of You (Acoustic Audio Session)"},
+		},
+		{
+			name:    "live group is kept",
+			title:   "Queen - Bohemian Rhapsody (Live at Wembley '86)",
+			channel: "Queen Official",
+			want:    TrackQuery{Artist: "Queen", Title: "Bohemian Rhapsody (Live at Wembley '86)"},
+		},
+		{
+			name:    "acoustic group is kept",
+			title:   "Artist - Song (Acoustic)",
+			channel: "Artist",
+			want:    TrackQuery{Artist: "Artist", Title: "Song (Acoustic)"},
+		},
+		{
+			name:    "hyphenated word is not a separator",
+			title:   "Jay-Z - Empire State of Mind",
+			channel: "JayZVEVO",
+			want:    TrackQuery{Artist: "Jay-Z", Title: "Empire State of Mind"},
+		},
+		{
+			name:    "word containing ft is not a credit",
+			title:   "Artist - Left Behind",
+			channel: "Artist",
+			want:    TrackQuery{Artist: "Artist", Title: "Left Behind"},
+		},
+
+		// Remaster and edition suffixes
+		{
+			name:    "remastered year suffix",
+			title:   "The Beatles - Here Comes The Sun - Remastered 2009",
+			channel: "The Beatles",
+			want:    TrackQuery{Artist: "The Beatles", Title: "Here Comes The Sun"},
+		},
+		{
+			name:    "year remaster suffix",
+			title:   "David Bowie - Heroes - 2017 Remaster",
+			channel: "David Bowie",
+			want:    TrackQuery{Artist: "David Bowie", Title: "Heroes"},
+		},
+		{
+			name:    "digital remaster suffix",
+			title:   "Pink Floyd - Time - Digitally Remastered",
+			channel: "Pink Floyd",
+			want:    TrackQuery{Artist: "Pink Floyd", Title: "Time"},
+		},
+		{
+			name:    "remastered in brackets",
+			title:   "Led Zeppelin - Kashmir (Remaster)",
+			channel: "Led Zeppelin",
+			want:    TrackQuery{Artist: "Led Zeppelin", Title: "Kashmir"},
+		},
+		{
+			name:    "remastered year in brackets",
+			title:   "Fleetwood Mac - Dreams (2004 Remastered Version)",
+			channel: "Fleetwood Mac",
+			want:    TrackQuery{Artist: "Fleetwood Mac", Title: "Dreams"},
+		},
+		{
+			name:    "radio edit suffix",
+			title:   "Avicii - Levels - Radio Edit",
+			channel: "Avicii",
+			want:    TrackQuery{Artist: "Avicii", Title: "Levels"},
+		},
+		{
+			name:    "single version suffix",
+			title:   "Artist - Song - Single Version",
+			channel: "Artist",
+			want:    TrackQuery{Artist: "Artist", Title: "Song"},
+		},
+		{
+			name:    "original mix suffix",
+			title:   "Deadmau5 - Strobe - Original Mix",
+			channel: "deadmau5",
+			want:    TrackQuery{Artist: "Deadmau5", Title: "Strobe"},
+		},
+		{
+			name:    "radio edit in brackets",
+			title:   "Artist - Song (Radio Edit)",
+			channel: "Artist",
+			want:    TrackQuery{Artist: "Artist", Title: "Song"},
+		},
+
+		// Emoji
+		{
+			name:    "emoji around the title",
+			title:   "🔥 Bad Bunny - Tití Me Preguntó 🔥",
+			channel: "Bad Bunny",
+			want:    TrackQuery{Artist: "Bad Bunny", Title: "Tití Me Preguntó"},
+		},
+		{
+			name:    "emoji with variation selector",
+			title:   "Harry Styles - As It Was ❤️",
+			channel: "Harry Styles",
+			want:    TrackQuery{Artist: "Harry Styles", Title: "As It Was"},
+		},
+		{
+			name:    "zero width joiner emoji",
+			title:   "Artist - Song 👨‍👩‍👧",
+			channel: "Artist",
+			want:    TrackQuery{Artist: "Artist", Title: "Song"},
+		},
+
+		// Quoted titles
+		{
+			name:    "double quoted title",
+			title:   `Metallica "Enter Sandman"`,
+			channel: "Metallica",
+			want:    TrackQuery{Artist: "Metallica", Title: "Enter Sandman"},
+		},
+		{
+			name:    "curly quoted title",
+			title:   "Björk “Army of Me”",
+			channel: "björk",
+			want:    TrackQuery{Artist: "Björk", Title: "Army of Me"},
+		},
+		{
+			name:    "quoted title with noise",
+			title:   `Nirvana "Smells Like Teen Spirit" (Official Music Video)`,
+			channel: "Nirvana",
+			want:    TrackQuery{Artist: "Nirvana", Title: "Smells Like Teen Spirit"},
+		},
+
+		// No separator: the channel names the artist
+		{
+			name:    "no separator uses channel",
+			title:   "Someone Like You",
+			channel: "Adele",
+			want:    TrackQuery{Artist: "Adele", Title: "Someone Like You"},
+		},
+		{
+			name:    "no separator strips vevo",
+			title:   "Halo (Official Video)",
+			channel: "BeyonceVEVO",
+			want:    TrackQuery{Artist: "Beyonce", Title: "Halo"},
+		},
+		{
+			name:    "no separator strips official",
+			title:   "Shallow",
+			channel: "Lady Gaga Official",
+			want:    TrackQuery{Artist: "Lady Gaga", Title: "Shallow"},
+		},
+		{
+			name:    "no separator strips music",
+			title:   "Sunflower",
+			channel: "Post Malone Music",
+			want:    TrackQuery{Artist: "Post Malone", Title: "Sunflower"},
+		},
+		{
+			name:    "no separator with feat",
+			title:   "Señorita ft. Camila Cabello",
+			channel: "Shawn Mendes",
+			want:    TrackQuery{Artist: "Shawn Mendes", Title: "Señorita", Featured: []string{"Camila Cabello"}},
+		},
+		{
+			name:    "empty channel",
+			title:   "Untitled",
+			channel: "",
+			want:    TrackQuery{Artist: "", Title: "Untitled"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.title, tt.channel)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q, %q) = %#v, want %#v", tt.title, tt.channel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackQueryString(t *testing.T) {
+	tests := []struct {
+		query TrackQuery
+		want  string
+	}{
+		{TrackQuery{Artist: "Queen", Title: "Bohemian Rhapsody"}, "Queen Bohemian Rhapsody"},
+		{TrackQuery{Title: "Untitled"}, "Untitled"},
+		{TrackQuery{Artist: "Adele"}, "Adele"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.query.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package matching
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/pkg/destination"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Default scoring parameters
+const (
+	DefaultDurationTolerance = 3 * time.Second
+	DefaultMaxDurationDiff   = 30 * time.Second
+)
+
+// Factor weights; duration is left out of the weighted sum when either side
+// has no duration. Duration weighs enough that a right title and artist with
+// a duration off by more than MaxDurationDiff (an extended or live cut)
+// scores 0.75 and lands under the default review threshold.
+const (
+	titleWeight    = 0.40
+	artistWeight   = 0.35
+	durationWeight = 0.25
+)
+
+// Version markers that make a recording different from the original
+var versionMarkers = []string{"live", "cover", "remix", "acoustic", "instrumental", "karaoke", "sped up", "slowed", "nightcore", "8d"}
+
+// Source is a source video prepared for scoring
+type Source struct {
+	VideoID  string
+	Title    string // Raw video title
+	Query    TrackQuery
+	Duration time.Duration // Zero when unknown
+	ISRC     string        // Empty when unknown
+}
+
+// NewSource builds a Source from an enriched playlist video
+func NewSource(video *models.VideoResponse) Source {
+	return Source{
+		VideoID:  video.ID,
+		Title:    video.Title,
+		Query:    Parse(video.Title, video.ChannelTitle),
+		Duration: time.Duration(video.DurationSeconds) * time.Second,
+	}
+}
+
+// Factor is one component of a match confidence
+type Factor struct {
+	Name        string  `json:"name"`
+	Score       float64 `json:"score"`
+	Explanation string  `json:"explanation"`
+}
+
+// Match is a candidate track scored against a source video
+type Match struct {
+	Track      destination.Track `json:"track"`
+	Confidence float64           `json:"confidence"` // 0 (no match) to 1 (certain)
+	Factors    []Factor          `json:"factors"`
+}
+
+// Scorer compares candidate tracks with source videos
+type Scorer struct {
+	// Durations closer than this are a perfect duration match
+	DurationTolerance time.Duration
+	// Durations further apart than this get no duration score
+	MaxDurationDiff time.Duration
+}
+
+// NewScorer creates a Scorer with the default tolerances
+func NewScorer() *Scorer {
+	return &Scorer{
+		DurationTolerance: DefaultDurationTolerance,
+		MaxDurationDiff:   DefaultMaxDurationDiff,
+	}
+}
+
+// Rank scores every candidate and returns them from best to worst match
+func (s *Scorer) Rank(source Source, candidates []destination.Track) []Match {
+	matches := make([]Match, len(candidates))
+	for i, candidate := range candidates {
+		matches[i] = s.Score(source, candidate)
+	}
+
+	// Stable so that ties keep the destination's own relevance order
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Confidence > matches[j].Confidence
+	})
+	return matches
+}
+
+// Score compares a single candidate with a source video
+func (s *Scorer) Score(source Source, candidate destination.Track) Match {
+	match := Match{Track: candidate}
+
+	// ISRC identifies the recording, so equality settles the match
+	if source.ISRC != "" && candidate.ISRC != "" && strings.EqualFold(source.ISRC, candidate.ISRC) {
+		match.Confidence = 1
+		match.Factors = []Factor{{
+			Name:        "isrc",
+			Score:       1,
+			Explanation: fmt.Sprintf("ISRC %s matches", candidate.ISRC),
+		}}
+		return match
+	}
+
+	title := s.titleFactor(source, candidate)
+	artist := s.artistFactor(source, candidate)
+	match.Factors = append(match.Factors, title, artist)
+
+	total := title.Score*titleWeight + artist.Score*artistWeight
+	weight := titleWeight + artistWeight
+	if duration, ok := s.durationFactor(source, candidate); ok {
+		match.Factors = append(match.Factors, duration)
+		total += duration.Score * durationWeight
+		weight += durationWeight
+	}
+	confidence := total / weight
+
+	// A right title by the wrong artist (or the reverse) is a different song,
+	// so the weaker of the two also scales the result
+	confidence *= 0.5 + 0.5*math.Min(title.Score, artist.Score)
+
+	if source.ISRC != "" && candidate.ISRC != "" {
+		// Different ISRCs can still be the same song (re-releases), so only penalise
+		match.Factors = append(match.Factors, Factor{
+			Name:        "isrc",
+			Score:       -0.1,
+			Explanation: fmt.Sprintf("ISRC differs (%s vs %s)", source.ISRC, candidate.ISRC),
+		})
+		confidence *= 0.9
+	}
+
+	for _, penalty := range versionPenalties(source, candidate) {
+		match.Factors = append(match.Factors, penalty)
+		confidence *= 1 + penalty.Score
+	}
+
+	match.Confidence = math.Round(clamp(confidence)*1000) / 1000
+	return match
+}
+
+// titleFactor compares the parsed source title with the candidate title
+func (s *Scorer) titleFactor(source Source, candidate destination.Track) Factor {
+	a, b := normalizeTitle(source.Query.Title), normalizeTitle(candidate.Title)
+	score := similarity(a, b)
+	return Factor{
+		Name:        "title",
+		Score:       round(score),
+		Explanation: fmt.Sprintf("Title similarity %.2f (%q vs %q)", score, a, b),
+	}
+}
+
+// artistFactor compares the source artists, including featured ones, with the
+// candidate artists and keeps the best pair
+func (s *Scorer) artistFactor(source Source, candidate destination.Track) Factor {
+	sourceArtists := append([]string{source.Query.Artist}, source.Query.Featured...)
+
+	// Titles that could not be split may still name the artist as a whole
+	// word ("Everlong" by "Eve" is not a match for "Foo Fighters - Everlong")
+	title := ""
+	if source.Query.Artist == "" {
+		title = " " + normalize(source.Title) + " "
+	}
+
+	best, bestSource, bestCandidate := 0.0, normalize(source.Query.Artist), ""
+	for _, c := range candidate.Artists {
+		nc := normalize(c)
+		for _, a := range sourceArtists {
+			na := normalize(a)
+			if score := similarity(na, nc); score > best || bestCandidate == "" {
+				best, bestSource, bestCandidate = score, na, nc
+			}
+		}
+
+		if title != "" && nc != "" && best < 1 && strings.Contains(title, " "+nc+" ") {
+			best, bestSource, bestCandidate = 1, strings.TrimSpace(title), nc
+		}
+	}
+
+	if bestCandidate == "" {
+		return Factor{Name: "artist", Explanation: "Candidate has no artists"}
+	}
+	return Factor{
+		Name:        "artist",
+		Score:       round(best),
+		Explanation: fmt.Sprintf("Artist similarity %.2f (%q vs %q)", best, bestSource, bestCandidate),
+	}
+}
+
+// durationFactor scores the duration difference. ok is false when either
+// duration is unknown.
+func (s *Scorer) durationFactor(source Source, candidate destination.Track) (Factor, bool) {
+	if source.Duration <= 0 || candidate.Duration <= 0 {
+		return Factor{}, false
+	}
+
+	diff := source.Duration - candidate.Duration
+	if diff < 0 {
+		diff = -diff
+	}
+	diff = diff.Round(time.Second)
+
+	factor := Factor{Name: "duration"}
+	switch {
+	case diff <= s.DurationTolerance:
+		factor.Score = 1
+		factor.Explanation = fmt.Sprintf("Duration differs by %s (within %s tolerance)", diff, s.DurationTolerance)
+	case diff >= s.MaxDurationDiff:
+		factor.Explanation = fmt.Sprintf("Duration differs by %s (more than %s)", diff, s.MaxDurationDiff)
+	default:
+		factor.Score = round(1 - float64(diff-s.DurationTolerance)/float64(s.MaxDurationDiff-s.DurationTolerance))
+		factor.Explanation = fmt.Sprintf("Duration differs by %s (outside %s tolerance)", diff, s.DurationTolerance)
+	}
+	return factor, true
+}
+
+// versionPenalties penalises live, cover, remix and similar versions present
+// on only one side. Scores are negative fractions of the confidence.
+func versionPenalties(source Source, candidate destination.Track) []Factor {
+	sourceText := " " + normalize(source.Title) + " "
+	candidateText := " " + normalize(candidate.Title+" "+candidate.Album) + " "
+
+	var penalties []Factor
+	for _, marker := range versionMarkers {
+		inSource := strings.Contains(sourceText, " "+marker+" ")
+		inCandidate := strings.Contains(candidateText, " "+marker+" ")
+
+		switch {
+		case inCandidate && !inSource:
+			penalties = append(penalties, Factor{
+				Name:        marker,
+				Score:       -0.3,
+				Explanation: fmt.Sprintf("Candidate is a %s version but the source is not", marker),
+			})
+		case inSource && !inCandidate:
+			penalties = append(penalties, Factor{
+				Name:        marker,
+				Score:       -0.15,
+				Explanation: fmt.Sprintf("Source is a %s version but the candidate is not", marker),
+			})
+		}
+	}
+	return penalties
+}
+
+// normalizeTitle removes featured credits, noise, remaster and edition tags
+// from a track title before normalising it
+func normalizeTitle(title string) string {
+	s := bracketPattern.ReplaceAllStringFunc(stripEmoji(title), func(group string) string {
+		content := bracketPattern.FindStringSubmatch(group)[1]
+		if featBracketPattern.MatchString(content) || isNoise(content) {
+			return " "
+		}
+		return group
+	})
+	s = remasterPattern.ReplaceAllString(strings.TrimSpace(s), "")
+	s = editionPattern.ReplaceAllString(s, "")
+	s, _ = cutFeatured(s)
+	return normalize(s)
+}
+
+// normalize lowercases s, removes accents and punctuation and collapses spaces
+func normalize(s string) string {
+	s = strings.ReplaceAll(s, "&", " and ")
+
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining accent left over by the decomposition
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case r == '\'' || r == '’':
+			// "Don't" and "Dont" are the same word
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// similarity returns how alike two normalised strings are, from 0 to 1. It
+// takes the best of the edit-distance ratio and the word overlap, so that
+// reordered or partially credited names still score well.
+func similarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	return math.Max(editRatio(a, b), wordOverlap(a, b))
+}
+
+// editRatio is 1 minus the Levenshtein distance relative to the longer string
+func editRatio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(max(len(ra), len(rb)))
+}
+
+// wordOverlap is the Dice coefficient of the word sets of a and b
+func wordOverlap(a, b string) float64 {
+	wa, wb := strings.Fields(a), strings.Fields(b)
+	set := make(map[string]bool, len(wa))
+	for _, w := range wa {
+		set[w] = true
+	}
+
+	common := 0
+	for _, w := range wb {
+		if set[w] {
+			common++
+			delete(set, w)
+		}
+	}
+	return 2 * float64(common) / float64(len(wa)+len(wb))
+}
+
+func clamp(f float64) float64 {
+	return math.Max(0, math.Min(1, f))
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
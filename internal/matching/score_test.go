@@ -0,0 +1,246 @@
+package matching_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/matching"
+	"github.com/alejpaa/playlist-migration-tool/internal/services"
+	"github.com/alejpaa/playlist-migration-tool/pkg/destination"
+)
+
+// source builds a scoring source from a video title, its channel and its
+// duration in seconds (0 when unknown)
+func source(title, channel string, seconds int) matching.Source {
+	return matching.Source{
+		Title:    title,
+		Query:    matching.Parse(title, channel),
+		Duration: time.Duration(seconds) * time.Second,
+	}
+}
+
+// track builds a destination candidate lasting seconds
+func track(title string, seconds int, artists ...string) destination.Track {
+	return destination.Track{Title: title, Artists: artists, Duration: time.Duration(seconds) * time.Second}
+}
+
+func TestScoreAgainstReviewThreshold(t *testing.T) {
+	threshold := services.DefaultReviewThreshold
+
+	tests := []struct {
+		name       string
+		source     matching.Source
+		candidate  destination.Track
+		wantReview bool
+	}{
+		// Same recording: accepted without review
+		{
+			name:      "exact match",
+			source:    source("Queen - Bohemian Rhapsody (Official Video)", "Queen Official", 354),
+			candidate: track("Bohemian Rhapsody", 355, "Queen"),
+		},
+		{
+			name:      "topic channel",
+			source:    source("Bohemian Rhapsody", "Queen - Topic", 354),
+			candidate: track("Bohemian Rhapsody", 354, "Queen"),
+		},
+		{
+			name:      "accents and case",
+			source:    source("BEYONCÉ - Halo", "Beyoncé", 261),
+			candidate: track("Halo", 261, "Beyonce"),
+		},
+		{
+			name:      "remaster tag on both sides",
+			source:    source("Queen - Bohemian Rhapsody (Remastered 2011)", "Queen", 354),
+			candidate: track("Bohemian Rhapsody - Remastered 2011", 354, "Queen"),
+		},
+		{
+			name:      "featured artist",
+			source:    source("Daft Punk - Get Lucky ft. Pharrell Williams", "Daft Punk", 248),
+			candidate: track("Get Lucky (feat. Pharrell Williams)", 248, "Daft Punk", "Pharrell Williams"),
+		},
+		{
+			name:      "featured artist credited first",
+			source:    source("Daft Punk - Get Lucky ft. Pharrell Williams", "Daft Punk", 248),
+			candidate: track("Get Lucky", 248, "Pharrell Williams", "Daft Punk"),
+		},
+		{
+			name:      "unknown durations",
+			source:    source("Queen - Bohemian Rhapsody", "Queen", 0),
+			candidate: track("Bohemian Rhapsody", 0, "Queen"),
+		},
+		{
+			name:      "duration within tolerance",
+			source:    source("Queen - Bohemian Rhapsody", "Queen", 354),
+			candidate: track("Bohemian Rhapsody", 357, "Queen"),
+		},
+		{
+			name:      "duration somewhat off",
+			source:    source("Queen - Bohemian Rhapsody", "Queen", 354),
+			candidate: track("Bohemian Rhapsody", 370, "Queen"),
+		},
+
+		// Probably another recording or another song: sent to review
+		{
+			name:       "duration far off",
+			source:     source("Queen - Bohemian Rhapsody", "Queen", 354),
+			candidate:  track("Bohemian Rhapsody", 384, "Queen"),
+			wantReview: true,
+		},
+		{
+			name:       "right title, wrong artist",
+			source:     source("Queen - Bohemian Rhapsody", "Queen", 354),
+			candidate:  track("Bohemian Rhapsody", 354, "Panic! At The Disco"),
+			wantReview: true,
+		},
+		{
+			name:       "artist name inside another word of the title",
+			source:     source("Foo Fighters - Everlong", "Foo Fighters", 250),
+			candidate:  track("Everlong", 250, "Eve"),
+			wantReview: true,
+		},
+		{
+			name:       "right artist, wrong title",
+			source:     source("Queen - Bohemian Rhapsody", "Queen", 354),
+			candidate:  track("Under Pressure", 248, "Queen"),
+			wantReview: true,
+		},
+		{
+			name:       "live candidate for a studio video",
+			source:     source("Queen - Bohemian Rhapsody", "Queen", 354),
+			candidate:  track("Bohemian Rhapsody - Live Aid", 354, "Queen"),
+			wantReview: true,
+		},
+		{
+			name:       "studio candidate for a live video",
+			source:     source("Queen - Bohemian Rhapsody (Live at Wembley)", "Queen", 354),
+			candidate:  track("Bohemian Rhapsody", 354, "Queen"),
+			wantReview: true,
+		},
+		{
+			name:       "remix",
+			source:     source("Queen - Bohemian Rhapsody", "Queen", 354),
+			candidate:  track("Bohemian Rhapsody (Remix)", 354, "Queen"),
+			wantReview: true,
+		},
+		{
+			name:       "karaoke",
+			source:     source("Queen - Bohemian Rhapsody", "Queen", 354),
+			candidate:  track("Bohemian Rhapsody (Karaoke Version)", 354, "Karaoke Hits"),
+			wantReview: true,
+		},
+		{
+			name:       "candidate without artists",
+			source:     source("Queen - Bohemian Rhapsody", "Queen", 354),
+			candidate:  track("Bohemian Rhapsody", 354),
+			wantReview: true,
+		},
+	}
+
+	scorer := matching.NewScorer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := scorer.Score(tt.source, tt.candidate)
+			if match.Confidence < 0 || match.Confidence > 1 {
+				t.Fatalf("confidence %.3f out of range", match.Confidence)
+			}
+			if review := match.Confidence < threshold; review != tt.wantReview {
+				t.Errorf("confidence %.3f: review = %v, want %v (factors %+v)", match.Confidence, review, tt.wantReview, match.Factors)
+			}
+		})
+	}
+}
+
+func TestScoreISRC(t *testing.T) {
+	scorer := matching.NewScorer()
+	src := source("Queen - Bohemian Rhapsody", "Queen", 354)
+
+	// The same ISRC settles the match even when the metadata disagrees
+	src.ISRC = "GBUM71029604"
+	candidate := track("Bohemian Rhapsody - 2011 Mix", 400, "Queen")
+	candidate.ISRC = "gbum71029604"
+	if match := scorer.Score(src, candidate); match.Confidence != 1 || len(match.Factors) != 1 {
+		t.Errorf("same ISRC: confidence %.3f, factors %+v; want 1 and only the isrc factor", match.Confidence, match.Factors)
+	}
+
+	// A different ISRC is only a mild penalty: re-releases get new codes
+	candidate = track("Bohemian Rhapsody", 354, "Queen")
+	candidate.ISRC = "GBUM71507409"
+	if match := scorer.Score(src, candidate); match.Confidence != 0.9 {
+		t.Errorf("different ISRC: confidence %.3f, want 0.9", match.Confidence)
+	}
+}
+
+func TestScoreDurationFactor(t *testing.T) {
+	tests := []struct {
+		diff time.Duration
+		want float64
+	}{
+		{diff: 0, want: 1},
+		{diff: 3 * time.Second, want: 1},
+		{diff: -3 * time.Second, want: 1},
+		{diff: 3400 * time.Millisecond, want: 1}, // Rounded to whole seconds
+		{diff: 4 * time.Second, want: 0.96},
+		{diff: 16500 * time.Millisecond, want: 0.48},
+		{diff: 29 * time.Second, want: 0.04},
+		{diff: 30 * time.Second, want: 0},
+		{diff: -5 * time.Minute, want: 0},
+	}
+
+	scorer := matching.NewScorer()
+	src := source("Queen - Bohemian Rhapsody", "Queen", 354)
+	for _, tt := range tests {
+		candidate := track("Bohemian Rhapsody", 354, "Queen")
+		candidate.Duration += tt.diff
+
+		got, ok := durationScore(scorer.Score(src, candidate))
+		if !ok {
+			t.Fatalf("diff %s: no duration factor", tt.diff)
+		}
+		if got != tt.want {
+			t.Errorf("diff %s: duration score %.2f, want %.2f", tt.diff, got, tt.want)
+		}
+	}
+
+	// An unknown duration on either side is left out instead of scoring 0
+	candidate := track("Bohemian Rhapsody", 0, "Queen")
+	if _, ok := durationScore(scorer.Score(src, candidate)); ok {
+		t.Error("unknown candidate duration was scored")
+	}
+}
+
+// durationScore returns the score of the duration factor of match
+func durationScore(match matching.Match) (float64, bool) {
+	for _, factor := range match.Factors {
+		if factor.Name == "duration" {
+			return factor.Score, true
+		}
+	}
+	return 0, false
+}
+
+func TestRank(t *testing.T) {
+	src := source("Queen - Bohemian Rhapsody", "Queen", 354)
+	candidates := []destination.Track{
+		track("Bohemian Rhapsody (Karaoke Version)", 354, "Karaoke Hits"),
+		track("Bohemian Rhapsody - Live Aid", 354, "Queen"),
+		track("Bohemian Rhapsody", 354, "Queen"),
+		track("Bohemian Rhapsody", 354, "Queen"),
+	}
+	candidates[2].ID = "first"
+	candidates[3].ID = "second"
+
+	matches := matching.NewScorer().Rank(src, candidates)
+	if len(matches) != len(candidates) {
+		t.Fatalf("got %d matches, want %d", len(matches), len(candidates))
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i].Confidence > matches[i-1].Confidence {
+			t.Errorf("match %d (%.3f) ranks above match %d (%.3f)", i, matches[i].Confidence, i-1, matches[i-1].Confidence)
+		}
+	}
+	// Ties keep the destination's own order
+	if matches[0].Track.ID != "first" || matches[1].Track.ID != "second" {
+		t.Errorf("tied matches reordered: %q, %q", matches[0].Track.ID, matches[1].Track.ID)
+	}
+}
@@ -1,15 +1,35 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"strings"
 
 	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/services"
 	"github.com/alejpaa/playlist-migration-tool/pkg/auth"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
 )
 
-// AuthMiddleware verifies the access token
-func AuthMiddleware() gin.HandlerFunc {
+// SessionValidator resolves the session credentials issued by the server
+type SessionValidator interface {
+	// ValidateSession returns the user a credential belongs to, or an
+	// *models.APIError when it is unknown or expired
+	ValidateSession(ctx context.Context, credential string) (string, error)
+	// SessionToken returns the user's current Google token, refreshed when
+	// it has expired, or an *models.APIError
+	SessionToken(ctx context.Context, userID string) (*oauth2.Token, error)
+}
+
+// AuthMiddleware verifies the bearer credential: either a session credential,
+// resolved with sessions, or a raw Google access token, checked with tokens.
+// The credential is stored as "access_token" and the expiry and scopes of the
+// Google token behind it as "token_expiry" and "token_scopes"; for sessions
+// the user is stored as "user_id", for access tokens the Google account they
+// belong to as "token_subject". Sessions report no scopes when the token
+// store did not keep them.
+func AuthMiddleware(sessions SessionValidator, tokens *auth.TokenValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Extract token from Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -34,19 +54,71 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Validate token by trying to use it (simple validation)
-		if !auth.ValidateToken(accessToken) {
-			respondWithError(c, models.NewUnauthorizedError("Invalid or expired token", nil))
+		// Session credentials map to a stored token the server refreshes
+		if services.IsSessionCredential(accessToken) {
+			userID, err := sessions.ValidateSession(c.Request.Context(), accessToken)
+			var tok *oauth2.Token
+			if err == nil {
+				tok, err = sessions.SessionToken(c.Request.Context(), userID)
+			}
+			if err != nil {
+				apiErr, ok := err.(*models.APIError)
+				if !ok {
+					apiErr = models.NewInternalServerError("Unable to validate session", err)
+				}
+				respondWithError(c, apiErr)
+				c.Abort()
+				return
+			}
+
+			c.Set("user_id", userID)
+			c.Set("access_token", accessToken)
+			c.Set("token_expiry", tok.Expiry)
+			c.Set("token_scopes", tokenScopes(tok))
+			c.Next()
+			return
+		}
+
+		// Ask Google whether the token is valid and meant for this application
+		info, err := tokens.Validate(c.Request.Context(), accessToken)
+		if err != nil {
+			respondWithError(c, tokenError(err))
 			c.Abort()
 			return
 		}
 
 		// Add token to context
 		c.Set("access_token", accessToken)
+		c.Set("token_subject", info.Subject)
+		c.Set("token_expiry", info.Expiry)
+		c.Set("token_scopes", info.Scopes)
 		c.Next()
 	}
 }
 
+// tokenScopes returns the scopes a token was granted, as reported by the
+// token endpoint, or none when unknown
+func tokenScopes(tok *oauth2.Token) []string {
+	scope, _ := tok.Extra("scope").(string)
+	return strings.Fields(scope)
+}
+
+// tokenError maps a TokenValidator error to an APIError
+func tokenError(err error) *models.APIError {
+	switch {
+	case errors.Is(err, auth.ErrInvalidToken):
+		return models.NewUnauthorizedError("Invalid or expired token", err)
+	case errors.Is(err, auth.ErrWrongAudience):
+		return models.NewUnauthorizedError("Token was not issued to this application", err)
+	case errors.Is(err, auth.ErrMissingScope):
+		return models.NewForbiddenError("Token does not grant access to YouTube playlists", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return models.NewGatewayTimeoutError("Timed out validating the access token", err)
+	default:
+		return models.NewServiceUnavailableError("Unable to validate the access token, try again later", err)
+	}
+}
+
 // respondWithError writes an error response
 func respondWithError(c *gin.Context, err *models.APIError) {
 	c.JSON(err.StatusCode, err.ToErrorResponse())
@@ -23,6 +23,11 @@ func (e *APIError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying error so errors.Is/As can inspect it
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
 // NewAPIError creates a new API error
 func NewAPIError(message string, statusCode int, err error) *APIError {
 	return &APIError{
@@ -41,14 +46,34 @@ func NewUnauthorizedError(message string, err error) *APIError {
 	return NewAPIError(message, http.StatusUnauthorized, err)
 }
 
+func NewForbiddenError(message string, err error) *APIError {
+	return NewAPIError(message, http.StatusForbidden, err)
+}
+
 func NewNotFoundError(message string, err error) *APIError {
 	return NewAPIError(message, http.StatusNotFound, err)
 }
 
+func NewConflictError(message string, err error) *APIError {
+	return NewAPIError(message, http.StatusConflict, err)
+}
+
+func NewTooManyRequestsError(message string, err error) *APIError {
+	return NewAPIError(message, http.StatusTooManyRequests, err)
+}
+
 func NewInternalServerError(message string, err error) *APIError {
 	return NewAPIError(message, http.StatusInternalServerError, err)
 }
 
+func NewServiceUnavailableError(message string, err error) *APIError {
+	return NewAPIError(message, http.StatusServiceUnavailable, err)
+}
+
+func NewGatewayTimeoutError(message string, err error) *APIError {
+	return NewAPIError(message, http.StatusGatewayTimeout, err)
+}
+
 // ToErrorResponse converts APIError to ErrorResponse
 func (e *APIError) ToErrorResponse() *ErrorResponse {
 	return &ErrorResponse{
@@ -17,3 +17,91 @@ type PlaylistsQuery struct {
 	MaxResults int    `json:"max_results,omitempty"`
 	PageToken  string `json:"page_token,omitempty"`
 }
+
+// CreatePlaylistRequest represents a request to create a playlist
+type CreatePlaylistRequest struct {
+	Title         string `json:"title" binding:"required"`
+	Description   string `json:"description"`
+	PrivacyStatus string `json:"privacy_status"` // "public", "unlisted" or "private" (default)
+}
+
+// UpdatePlaylistRequest represents a partial update of a playlist
+type UpdatePlaylistRequest struct {
+	Title         *string `json:"title"`
+	Description   *string `json:"description"`
+	PrivacyStatus *string `json:"privacy_status"`
+}
+
+// AddPlaylistItemRequest represents a request to add a video to a playlist
+type AddPlaylistItemRequest struct {
+	VideoID  string `json:"video_id" binding:"required"`
+	Position *int   `json:"position"` // Appended at the end when omitted
+}
+
+// MovePlaylistItemRequest represents a request to move a playlist item
+type MovePlaylistItemRequest struct {
+	VideoID  string `json:"video_id" binding:"required"`
+	Position *int   `json:"position" binding:"required"`
+}
+
+// MigrationRequest represents a request to copy a playlist to another YouTube account
+type MigrationRequest struct {
+	SourcePlaylistID       string  `json:"source_playlist_id" binding:"required"`
+	DestinationAccessToken string  `json:"destination_access_token" binding:"required"`
+	Title                  string  `json:"title"`          // Defaults to the source title
+	Description            *string `json:"description"`    // Defaults to the source description
+	PrivacyStatus          string  `json:"privacy_status"` // Defaults to the source privacy status
+}
+
+// DestinationMigrationRequest represents a request to rebuild a playlist on another service
+type DestinationMigrationRequest struct {
+	DestinationAccessToken string  `json:"destination_access_token" binding:"required"`
+	Title                  string  `json:"title"`       // Defaults to the source title
+	Description            *string `json:"description"` // Defaults to the source description
+	Public                 *bool   `json:"public"`      // Defaults to the source privacy status
+}
+
+// ResumeMigrationRequest represents a request to resume a stopped migration
+type ResumeMigrationRequest struct {
+	DestinationAccessToken string `json:"destination_access_token" binding:"required"`
+}
+
+// Review actions
+const (
+	ReviewActionAccept = "accept" // Use the best suggested candidate
+	ReviewActionSelect = "select" // Use another suggested candidate
+	ReviewActionManual = "manual" // Use a destination ID supplied by the user
+	ReviewActionSkip   = "skip"   // Leave the track out of the migration
+)
+
+// ReviewDecisionRequest represents a decision on a track waiting for review
+type ReviewDecisionRequest struct {
+	Action        string `json:"action" binding:"required"`
+	DestinationID string `json:"destination_id"` // Required for select and manual
+}
+
+// CompleteMigrationRequest represents a request to finalise a reviewed migration
+type CompleteMigrationRequest struct {
+	DestinationAccessToken string `json:"destination_access_token" binding:"required"`
+	Force                  bool   `json:"force"` // Skip tracks still awaiting review
+}
+
+// Job types
+const (
+	JobTypeExport               = "export"                // Export a playlist (ExportRequest)
+	JobTypeMigration            = "migration"             // Copy a playlist between YouTube accounts (MigrationRequest)
+	JobTypeDestinationMigration = "destination_migration" // Rebuild a playlist on another service (DestinationMigrationRequest)
+	JobTypeResumeMigration      = "resume_migration"      // Resume a stopped migration (ResumeMigrationRequest)
+)
+
+// JobRequest represents a request to run an export or migration in the background
+type JobRequest struct {
+	Type        string                       `json:"type" binding:"required"`
+	PlaylistID  string                       `json:"playlist_id"` // Export and destination migration
+	Target      string                       `json:"target"`      // Destination migration
+	Export      *ExportRequest               `json:"export"`
+	Migration   *MigrationRequest            `json:"migration"`
+	Destination *DestinationMigrationRequest `json:"destination"`
+	MigrationID string                       `json:"migration_id"` // Resume
+	Resume      *ResumeMigrationRequest      `json:"resume"`
+}
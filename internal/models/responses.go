@@ -6,7 +6,42 @@ import "time"
 type AuthResponse struct {
 	Success     bool   `json:"success"`
 	AccessToken string `json:"access_token,omitempty"`
+	UserID      string `json:"user_id,omitempty"` // YouTube channel ID the token is saved under
 	Message     string `json:"message"`
+
+	// SessionToken is a server-issued bearer credential for /api. Unlike the
+	// access token it survives token expiry: the server refreshes the token.
+	SessionToken     string     `json:"session_token,omitempty"`
+	SessionExpiresAt *time.Time `json:"session_expires_at,omitempty"`
+}
+
+// DeviceAuthResponse is returned when a device authorization starts. The
+// user enters UserCode at VerificationURL while the client polls with DeviceID.
+type DeviceAuthResponse struct {
+	DeviceID                string    `json:"device_id"`
+	UserCode                string    `json:"user_code"`
+	VerificationURL         string    `json:"verification_url"`
+	VerificationURLComplete string    `json:"verification_url_complete,omitempty"`
+	ExpiresAt               time.Time `json:"expires_at"`
+	Interval                int64     `json:"interval"` // Seconds to wait between polls
+	Message                 string    `json:"message"`
+}
+
+// AccountResponse describes the YouTube account linked to the caller's credential
+type AccountResponse struct {
+	UserID         string     `json:"user_id"` // YouTube channel ID
+	ChannelTitle   string     `json:"channel_title"`
+	CustomURL      string     `json:"custom_url,omitempty"`
+	ThumbnailURL   string     `json:"thumbnail_url,omitempty"`
+	Scopes         []string   `json:"scopes"`
+	TokenExpiresAt *time.Time `json:"token_expires_at,omitempty"`
+}
+
+// RevokeResponse represents the response after disconnecting an account
+type RevokeResponse struct {
+	Success bool   `json:"success"`
+	UserID  string `json:"user_id,omitempty"`
+	Message string `json:"message"`
 }
 
 // HealthResponse represents the health check response
@@ -44,14 +79,58 @@ type PlaylistDetailResponse struct {
 
 // VideoResponse represents a video in a playlist
 type VideoResponse struct {
-	ID           string    `json:"id"`
-	Title        string    `json:"title"`
-	Description  string    `json:"description"`
-	ChannelTitle string    `json:"channel_title"`
-	Duration     string    `json:"duration,omitempty"`
-	Position     int       `json:"position"`
-	AddedAt      time.Time `json:"added_at"`
-	ThumbnailURL string    `json:"thumbnail_url"`
+	ID              string    `json:"id"`
+	ItemID          string    `json:"item_id,omitempty"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description"`
+	ChannelTitle    string    `json:"channel_title"`
+	Duration        string    `json:"duration,omitempty"`
+	DurationSeconds int       `json:"duration_seconds,omitempty"`
+	ViewCount       int64     `json:"view_count,omitempty"`
+	Availability    string    `json:"availability,omitempty"` // "available", "private", "unavailable" or "deleted"
+	AllowedRegions  []string  `json:"allowed_regions,omitempty"`
+	BlockedRegions  []string  `json:"blocked_regions,omitempty"`
+	IsMusic         bool      `json:"is_music"`
+	Position        int       `json:"position"`
+	AddedAt         time.Time `json:"added_at"`
+	ThumbnailURL    string    `json:"thumbnail_url"`
+}
+
+// TrackResponse represents a playlist video parsed into artist and track
+type TrackResponse struct {
+	VideoID         string   `json:"video_id"`
+	VideoTitle      string   `json:"video_title"`
+	ChannelTitle    string   `json:"channel_title"`
+	Artist          string   `json:"artist"`
+	Title           string   `json:"title"`
+	Featured        []string `json:"featured,omitempty"`
+	Query           string   `json:"query"`
+	DurationSeconds int      `json:"duration_seconds,omitempty"`
+	Position        int      `json:"position"`
+}
+
+// PlaylistTracksResponse represents the parsed tracks of a playlist
+type PlaylistTracksResponse struct {
+	PlaylistID string          `json:"playlist_id"`
+	Tracks     []TrackResponse `json:"tracks"`
+	TotalCount int             `json:"total_count"`
+}
+
+// Video availability values
+const (
+	AvailabilityAvailable   = "available"
+	AvailabilityPrivate     = "private"
+	AvailabilityUnavailable = "unavailable"
+	AvailabilityDeleted     = "deleted"
+)
+
+// PlaylistSongsResponse represents a page (or the whole list) of songs in a playlist
+type PlaylistSongsResponse struct {
+	PlaylistID    string          `json:"playlist_id"`
+	Songs         []VideoResponse `json:"songs"`
+	TotalCount    int             `json:"total_count"`
+	NextPageToken string          `json:"next_page_token,omitempty"`
+	PrevPageToken string          `json:"prev_page_token,omitempty"`
 }
 
 // ExportResponse represents the response after exporting a playlist
@@ -62,3 +141,117 @@ type ExportResponse struct {
 	DownloadURL string `json:"download_url,omitempty"` // For large exports
 	Message     string `json:"message"`
 }
+
+// QuotaResponse represents today's YouTube Data API quota consumption
+type QuotaResponse struct {
+	Budget    int                `json:"budget"`
+	Used      int                `json:"used"`
+	Remaining int                `json:"remaining"`
+	ResetAt   time.Time          `json:"reset_at"`
+	ByMethod  map[string]int     `json:"by_method"`
+	Token     TokenQuotaResponse `json:"token"`
+	Tokens    int                `json:"tokens"`
+}
+
+// TokenQuotaResponse represents the quota consumed by the caller's access token
+type TokenQuotaResponse struct {
+	Key      string         `json:"key"`
+	Used     int            `json:"used"`
+	ByMethod map[string]int `json:"by_method"`
+}
+
+// Migration status values
+const (
+	MigrationStatusRunning   = "running"
+	MigrationStatusCompleted = "completed"
+	MigrationStatusPartial   = "partial" // Finished, but some tracks failed
+	MigrationStatusFailed    = "failed"  // Aborted before processing every track
+	MigrationStatusReview    = "review"  // Waiting for low-confidence matches to be reviewed
+)
+
+// Migration track status values
+const (
+	TrackStatusPending  = "pending"
+	TrackStatusInserted = "inserted"
+	TrackStatusSkipped  = "skipped"
+	TrackStatusFailed   = "failed"
+	TrackStatusReview   = "review"   // Low-confidence match waiting for a decision
+	TrackStatusApproved = "approved" // Match chosen in review, added when the migration is completed
+)
+
+// MigrationResponse represents the state and outcome of a playlist migration
+type MigrationResponse struct {
+	ID                    string                   `json:"id"`
+	Target                string                   `json:"target"`
+	UserID                string                   `json:"user_id,omitempty"` // Who started the migration
+	Status                string                   `json:"status"`
+	SourcePlaylistID      string                   `json:"source_playlist_id"`
+	DestinationPlaylistID string                   `json:"destination_playlist_id,omitempty"`
+	Title                 string                   `json:"title"`
+	Total                 int                      `json:"total"`
+	Processed             int                      `json:"processed"`
+	Inserted              int                      `json:"inserted"`
+	Skipped               int                      `json:"skipped"`
+	Failed                int                      `json:"failed"`
+	PendingReview         int                      `json:"pending_review"`
+	Checkpoint            *MigrationCheckpoint     `json:"checkpoint,omitempty"`
+	Error                 string                   `json:"error,omitempty"`
+	Tracks                []MigrationTrackResponse `json:"tracks"`
+	StartedAt             time.Time                `json:"started_at"`
+	FinishedAt            *time.Time               `json:"finished_at,omitempty"`
+}
+
+// MigrationCheckpoint records how far a migration got, so it can be resumed
+type MigrationCheckpoint struct {
+	Position              int       `json:"position"` // Source position of the first track still to be added
+	DestinationPlaylistID string    `json:"destination_playlist_id,omitempty"`
+	InsertedIDs           []string  `json:"inserted_ids"` // Source video IDs already added to the destination
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// MigrationTrackResponse represents the outcome of migrating a single track
+type MigrationTrackResponse struct {
+	Position      int                      `json:"position"`
+	SourceID      string                   `json:"source_id"`
+	Title         string                   `json:"title"`
+	Status        string                   `json:"status"`
+	DestinationID string                   `json:"destination_id,omitempty"`
+	Confidence    float64                  `json:"confidence,omitempty"` // Match confidence for search-based targets
+	Reason        string                   `json:"reason,omitempty"`
+	Candidates    []MatchCandidateResponse `json:"candidates,omitempty"` // Ranked candidates for tracks sent to review
+}
+
+// MatchCandidateResponse represents a destination track suggested for a source video
+type MatchCandidateResponse struct {
+	ID              string   `json:"id"`
+	Title           string   `json:"title"`
+	Artists         []string `json:"artists"`
+	Album           string   `json:"album,omitempty"`
+	DurationSeconds int      `json:"duration_seconds,omitempty"`
+	Confidence      float64  `json:"confidence"`
+	Factors         []string `json:"factors"` // Explanation of each scoring factor
+}
+
+// ReviewQueueResponse represents the tracks of a migration waiting for review
+type ReviewQueueResponse struct {
+	MigrationID   string                   `json:"migration_id"`
+	Status        string                   `json:"status"`
+	PendingReview int                      `json:"pending_review"`
+	Tracks        []MigrationTrackResponse `json:"tracks"`
+}
+
+// JobResponse represents the state of a background job
+type JobResponse struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	State      string      `json:"state"` // "queued", "running", "succeeded", "failed" or "cancelled"
+	Percent    float64     `json:"percent"`
+	Total      int         `json:"total"`
+	Processed  int         `json:"processed"`
+	Failed     int         `json:"failed"`
+	Errors     []string    `json:"errors,omitempty"`
+	Result     interface{} `json:"result,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	StartedAt  *time.Time  `json:"started_at,omitempty"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+}
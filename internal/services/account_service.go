@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/pkg/auth"
+	youtubeapi "github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+	"golang.org/x/oauth2"
+)
+
+// AccountService reports and disconnects the YouTube account behind a
+// credential. Credentials are either session credentials, for which userID
+// is known, or raw access tokens.
+type AccountService struct {
+	tokens     auth.TokenStore
+	sessions   *SessionService
+	validator  *auth.TokenValidator
+	newClient  ClientFactory
+	revokeURL  string
+	httpClient *http.Client
+}
+
+// NewAccountService creates a new AccountService. Tokens are revoked at
+// revokeURL with httpClient; auth.DefaultRevokeURL and http.DefaultClient are
+// used when they are empty.
+func NewAccountService(tokens auth.TokenStore, sessions *SessionService, validator *auth.TokenValidator, newClient ClientFactory, revokeURL string, httpClient *http.Client) *AccountService {
+	if newClient == nil {
+		newClient = NewClientFactory()
+	}
+	return &AccountService{
+		tokens:     tokens,
+		sessions:   sessions,
+		validator:  validator,
+		newClient:  newClient,
+		revokeURL:  revokeURL,
+		httpClient: httpClient,
+	}
+}
+
+// GetAccount returns the channel linked to a credential, along with the
+// scopes and expiry of the access token behind it
+func (s *AccountService) GetAccount(ctx context.Context, credential, userID string) (*models.AccountResponse, error) {
+	channel, err := s.newClient(ctx, credential).GetMyChannel(ctx)
+	if errors.Is(err, youtubeapi.ErrNoChannel) {
+		return nil, models.NewNotFoundError("The account has no YouTube channel", err)
+	}
+	if err != nil {
+		return nil, youtubeError(err, "Failed to fetch YouTube account")
+	}
+
+	// Sessions are backed by the user's stored token
+	accessToken := credential
+	if userID != "" {
+		tok, err := s.storedToken(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		accessToken = tok.AccessToken
+	}
+
+	info, err := s.validator.Validate(ctx, accessToken)
+	if err != nil {
+		return nil, tokenInfoError(err)
+	}
+
+	response := &models.AccountResponse{
+		UserID:       channel.ID,
+		ChannelTitle: channel.Snippet.Title,
+		CustomURL:    channel.Snippet.CustomURL,
+		ThumbnailURL: channel.Snippet.Thumbnails["default"].URL,
+		Scopes:       info.Scopes,
+	}
+	if !info.Expiry.IsZero() {
+		response.TokenExpiresAt = &info.Expiry
+	}
+	return response, nil
+}
+
+// RevokeYouTube disconnects the account behind a credential: its tokens are
+// revoked at Google, the stored token is deleted and every session of the
+// user ends. Nothing is deleted unless Google confirmed the revocation, so a
+// failed call can be retried.
+func (s *AccountService) RevokeYouTube(ctx context.Context, credential, userID string) (*models.RevokeResponse, error) {
+	var revoke []string
+	if userID == "" {
+		revoke = append(revoke, credential)
+
+		// Find whose stored token and sessions to clean up. The credential
+		// is revoked even when the account cannot be identified.
+		if channel, err := s.newClient(ctx, credential).GetMyChannel(ctx); err == nil {
+			userID = channel.ID
+		}
+	}
+
+	var stored *oauth2.Token
+	if userID != "" {
+		tok, err := s.tokens.Load(ctx, userID)
+		switch {
+		case err == nil:
+			stored = tok
+		case !errors.Is(err, auth.ErrTokenNotFound):
+			return nil, models.NewInternalServerError("Unable to load token", err)
+		}
+	}
+	if stored != nil {
+		// Revoking the refresh token also revokes its access tokens
+		if stored.RefreshToken != "" {
+			revoke = append(revoke, stored.RefreshToken)
+		} else {
+			revoke = append(revoke, stored.AccessToken)
+		}
+	}
+
+	for _, token := range revoke {
+		if err := auth.RevokeToken(ctx, s.httpClient, s.revokeURL, token); err != nil {
+			return nil, googleError(err, "Unable to revoke the token at Google, try again later")
+		}
+		s.validator.Forget(token)
+	}
+	if stored != nil {
+		s.validator.Forget(stored.AccessToken)
+	}
+
+	if userID != "" {
+		if err := s.tokens.Delete(ctx, userID); err != nil && !errors.Is(err, auth.ErrTokenNotFound) {
+			return nil, models.NewInternalServerError("Unable to delete token", err)
+		}
+		if err := s.sessions.EndUserSessions(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &models.RevokeResponse{
+		Success: true,
+		UserID:  userID,
+		Message: "YouTube account disconnected",
+	}, nil
+}
+
+// storedToken returns a valid access token for userID, refreshing it if needed
+func (s *AccountService) storedToken(ctx context.Context, userID string) (*oauth2.Token, error) {
+	source, err := s.sessions.TokenSource(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tok, err := source.Token()
+	if err != nil {
+		return nil, youtubeError(err, "Failed to refresh YouTube token")
+	}
+	return tok, nil
+}
+
+// tokenInfoError maps a TokenValidator error to an APIError
+func tokenInfoError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrInvalidToken), errors.Is(err, auth.ErrWrongAudience):
+		return models.NewUnauthorizedError("Invalid or expired token", err)
+	case errors.Is(err, auth.ErrMissingScope):
+		return models.NewForbiddenError("Token does not grant access to YouTube playlists", err)
+	default:
+		return googleError(err, "Unable to inspect the access token, try again later")
+	}
+}
+
+// googleError maps a failure to reach one of Google's OAuth endpoints
+func googleError(err error, message string) *models.APIError {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return models.NewGatewayTimeoutError("Timed out waiting for Google", err)
+	}
+	return models.NewServiceUnavailableError(message, err)
+}
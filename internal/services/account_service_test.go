@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/pkg/auth"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube/youtubetest"
+	"golang.org/x/oauth2"
+)
+
+const readonlyScope = "https://www.googleapis.com/auth/youtube.readonly"
+
+// googleFake fakes Google's revoke and tokeninfo endpoints
+type googleFake struct {
+	revoke    *httptest.Server
+	tokenInfo *httptest.Server
+
+	mu           sync.Mutex
+	revoked      []string
+	revokeStatus int    // Status of the next revocations; 200 when zero
+	revokeBody   string // Body sent with revokeStatus
+}
+
+func newGoogleFake(t *testing.T) *googleFake {
+	g := &googleFake{}
+	g.revoke = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		if g.revokeStatus != 0 && g.revokeStatus != http.StatusOK {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(g.revokeStatus)
+			w.Write([]byte(g.revokeBody))
+			return
+		}
+		g.revoked = append(g.revoked, r.PostFormValue("token"))
+	}))
+	g.tokenInfo = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exp := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"aud":"client-id","sub":"123","scope":"` + readonlyScope + `","exp":"` + exp + `"}`))
+	}))
+	t.Cleanup(g.revoke.Close)
+	t.Cleanup(g.tokenInfo.Close)
+	return g
+}
+
+func (g *googleFake) failRevocations(status int, body string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.revokeStatus, g.revokeBody = status, body
+}
+
+func (g *googleFake) revokedTokens() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.revoked...)
+}
+
+// accountFixture is an AccountService for user-1, whose token is stored and
+// who has an open session
+type accountFixture struct {
+	service  *AccountService
+	sessions *SessionService
+	google   *googleFake
+	session  string
+}
+
+func newAccountFixture(t *testing.T) *accountFixture {
+	t.Helper()
+
+	yt := youtubetest.NewServer()
+	t.Cleanup(yt.Close)
+	yt.AddChannel(youtubetest.Channel{ID: "user-1", Snippet: youtubetest.ChannelSnippet{Title: "My Channel", CustomURL: "@mine"}}, true)
+
+	tok := &oauth2.Token{AccessToken: "ya29.stored", RefreshToken: "refresh-1", Expiry: time.Now().Add(time.Hour)}
+	sessions := newTestSessionService(t, "user-1", tok)
+	session, _, err := sessions.CreateSession(context.Background(), "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	google := newGoogleFake(t)
+	validator := auth.NewTokenValidator(auth.TokenValidatorConfig{URL: google.tokenInfo.URL})
+	clients := sessions.ClientFactory(youtube.WithBaseURL(yt.URL), youtube.WithoutRetries())
+
+	return &accountFixture{
+		service:  NewAccountService(sessions.tokens, sessions, validator, clients, google.revoke.URL, nil),
+		sessions: sessions,
+		google:   google,
+		session:  session,
+	}
+}
+
+// signedIn reports whether user-1 still has a stored token and an open session
+func (f *accountFixture) signedIn(t *testing.T) (hasToken, hasSession bool) {
+	t.Helper()
+
+	_, err := f.sessions.tokens.Load(context.Background(), "user-1")
+	if err != nil && !errors.Is(err, auth.ErrTokenNotFound) {
+		t.Fatal(err)
+	}
+	_, sessionErr := f.sessions.ValidateSession(context.Background(), f.session)
+	return err == nil, sessionErr == nil
+}
+
+func TestRevokeYouTube(t *testing.T) {
+	tests := []struct {
+		name        string
+		credential  func(f *accountFixture) string
+		userID      string
+		wantRevoked []string
+	}{
+		{
+			name:        "session",
+			credential:  func(f *accountFixture) string { return f.session },
+			userID:      "user-1",
+			wantRevoked: []string{"refresh-1"},
+		},
+		{
+			// The account behind the token is found through its channel
+			name:        "access token",
+			credential:  func(f *accountFixture) string { return "ya29.other" },
+			wantRevoked: []string{"ya29.other", "refresh-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAccountFixture(t)
+
+			response, err := f.service.RevokeYouTube(context.Background(), tt.credential(f), tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !response.Success || response.UserID != "user-1" {
+				t.Errorf("response = %+v", response)
+			}
+
+			if got := f.google.revokedTokens(); fmt.Sprint(got) != fmt.Sprint(tt.wantRevoked) {
+				t.Errorf("revoked %v, want %v", got, tt.wantRevoked)
+			}
+			if hasToken, hasSession := f.signedIn(t); hasToken || hasSession {
+				t.Errorf("after revocation: stored token %v, open session %v; want neither", hasToken, hasSession)
+			}
+		})
+	}
+}
+
+func TestRevokeYouTubeKeepsEverythingWhenGoogleFails(t *testing.T) {
+	f := newAccountFixture(t)
+	f.google.failRevocations(http.StatusInternalServerError, `{"error":"backend_error"}`)
+
+	_, err := f.service.RevokeYouTube(context.Background(), f.session, "user-1")
+	var apiErr *models.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want a 503 APIError", err)
+	}
+
+	// Nothing is deleted, so the call can be retried
+	if hasToken, hasSession := f.signedIn(t); !hasToken || !hasSession {
+		t.Errorf("after failed revocation: stored token %v, open session %v; want both", hasToken, hasSession)
+	}
+
+	f.google.failRevocations(http.StatusOK, "")
+	if _, err := f.service.RevokeYouTube(context.Background(), f.session, "user-1"); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if hasToken, hasSession := f.signedIn(t); hasToken || hasSession {
+		t.Errorf("after retry: stored token %v, open session %v; want neither", hasToken, hasSession)
+	}
+}
+
+func TestRevokeYouTubeAlreadyRevoked(t *testing.T) {
+	f := newAccountFixture(t)
+	// Google answers invalid_token for tokens that were already revoked
+	f.google.failRevocations(http.StatusBadRequest, `{"error":"invalid_token","error_description":"Token expired or revoked"}`)
+
+	if _, err := f.service.RevokeYouTube(context.Background(), f.session, "user-1"); err != nil {
+		t.Fatal(err)
+	}
+	if hasToken, hasSession := f.signedIn(t); hasToken || hasSession {
+		t.Errorf("stored token %v, open session %v; want neither", hasToken, hasSession)
+	}
+}
+
+func TestGetAccount(t *testing.T) {
+	f := newAccountFixture(t)
+
+	for _, tt := range []struct {
+		name       string
+		credential string
+		userID     string
+	}{
+		{name: "session", credential: f.session, userID: "user-1"},
+		{name: "access token", credential: "ya29.other"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			account, err := f.service.GetAccount(context.Background(), tt.credential, tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if account.UserID != "user-1" || account.ChannelTitle != "My Channel" || account.CustomURL != "@mine" {
+				t.Errorf("account = %+v", account)
+			}
+			if len(account.Scopes) != 1 || account.Scopes[0] != readonlyScope {
+				t.Errorf("scopes = %v, want [%s]", account.Scopes, readonlyScope)
+			}
+			if account.TokenExpiresAt == nil || time.Until(*account.TokenExpiresAt) <= 0 {
+				t.Errorf("token_expires_at = %v, want in the future", account.TokenExpiresAt)
+			}
+		})
+	}
+}
+
+func TestGetAccountAfterRevocation(t *testing.T) {
+	f := newAccountFixture(t)
+	if _, err := f.service.RevokeYouTube(context.Background(), f.session, "user-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The session is over, so its stored token cannot be found
+	_, err := f.service.GetAccount(context.Background(), f.session, "user-1")
+	var apiErr *models.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("err = %v, want a 401 APIError", err)
+	}
+}
@@ -2,112 +2,286 @@ package services
 
 import (
 	"context"
-	"encoding/json"
-	"os"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
 	"github.com/alejpaa/playlist-migration-tool/pkg/auth"
+	youtubeapi "github.com/alejpaa/playlist-migration-tool/pkg/youtube"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 	"google.golang.org/api/youtube/v3"
 )
 
 // AuthService handles authentication logic
 type AuthService struct {
 	credentialsFile string
+	redirectURL     string
+	tokens          auth.TokenStore
+	newClient       ClientFactory
+	sessions        *SessionService
+	states          *OAuthStates
+	devices         *deviceAuths
+
+	// ctx is the parent of the device authorization pollers, which outlive
+	// the request that starts them; Shutdown cancels it
+	ctx     context.Context
+	cancel  context.CancelFunc
+	pollers sync.WaitGroup
 }
 
-// NewAuthService creates a new AuthService
-func NewAuthService(credentialsFile string) *AuthService {
+// deviceSignInTimeout bounds the work done once a device authorization is
+// approved: identifying the channel, saving the token and opening a session
+const deviceSignInTimeout = 30 * time.Second
+
+// NewAuthService creates a new AuthService. redirectURL, when set, replaces
+// the first redirect URI of the credentials file. Tokens are kept in tokens, keyed
+// by the user's channel ID, which is looked up with a client from newClient.
+// A nil factory uses the default YouTube client. Each sign-in opens a session
+// in sessions; states guards the web flow against forged callbacks.
+func NewAuthService(credentialsFile, redirectURL string, tokens auth.TokenStore, newClient ClientFactory, sessions *SessionService, states *OAuthStates) *AuthService {
+	if newClient == nil {
+		newClient = NewClientFactory()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
 	return &AuthService{
 		credentialsFile: credentialsFile,
+		redirectURL:     redirectURL,
+		tokens:          tokens,
+		newClient:       newClient,
+		sessions:        sessions,
+		states:          states,
+		devices:         newDeviceAuths(),
+		ctx:             ctx,
+		cancel:          cancel,
 	}
 }
 
-// GetYouTubeAuthURL genera la URL de autenticación de YouTube
-func (s *AuthService) GetYouTubeAuthURL() (string, error) {
-	// Read credentials file
-	b, err := os.ReadFile(s.credentialsFile)
+// Shutdown stops polling Google for pending device authorizations, which
+// fail with a 503, and waits for the pollers to return
+func (s *AuthService) Shutdown() {
+	s.cancel()
+	s.pollers.Wait()
+}
+
+// GetYouTubeAuthURL genera la URL de autenticación de YouTube. Con write en
+// true se solicita el scope youtube.force-ssl, necesario para crear y
+// modificar playlists; si no, solo lectura. Cada URL lleva un state aleatorio
+// de un solo uso y un challenge PKCE, que se verifican en el callback. El
+// nonce devuelto debe guardarse en una cookie del navegador que inicia el
+// acceso, y se exige en el callback.
+func (s *AuthService) GetYouTubeAuthURL(ctx context.Context, write bool) (string, string, error) {
+	config, err := s.oauthConfig(youtubeScopes(write))
 	if err != nil {
-		return "", models.NewInternalServerError("Unable to read credentials file", err)
+		return "", "", err
+	}
+	if config.RedirectURL == "" {
+		return "", "", models.NewInternalServerError("No redirect URL configured, use the device flow instead", nil)
 	}
 
-	var creds auth.Credentials
-	if err := json.Unmarshal(b, &creds); err != nil {
-		return "", models.NewInternalServerError("Unable to parse credentials file", err)
+	state, nonce, challenge, err := s.states.Begin(ctx, store.ProviderYouTube)
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL := config.AuthCodeURL(state, oauth2.AccessTypeOffline, challenge)
+	return authURL, nonce, nil
+}
+
+// CompleteYouTubeAuth completa la autenticación con el código de autorización.
+// El state debe ser el de una URL generada por GetYouTubeAuthURL, sin usar y
+// sin vencer, y nonce el de la cookie del mismo navegador.
+func (s *AuthService) CompleteYouTubeAuth(ctx context.Context, authCode, state, nonce string) (*models.AuthResponse, error) {
+	verifier, err := s.states.Complete(ctx, store.ProviderYouTube, state, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := s.oauthConfig([]string{youtube.YoutubeReadonlyScope})
+	if err != nil {
+		return nil, err
 	}
 
-	// Create OAuth2 config
-	config := &oauth2.Config{
-		ClientID:     creds.Installed.ClientID,
-		ClientSecret: creds.Installed.ClientSecret,
-		RedirectURL:  creds.Installed.RedirectURIs[0],
-		Scopes:       []string{youtube.YoutubeReadonlyScope},
-		Endpoint:     google.Endpoint,
+	// Exchange authorization code for token
+	tok, err := config.Exchange(ctx, authCode, verifier)
+	if err != nil {
+		return nil, models.NewBadRequestError("Invalid authorization code", err)
 	}
 
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	return authURL, nil
+	return s.saveYouTubeToken(ctx, tok)
 }
 
-// CompleteYouTubeAuth completa la autenticación con el código de autorización
-func (s *AuthService) CompleteYouTubeAuth(authCode string) (*models.AuthResponse, error) {
-	// Read credentials file
-	b, err := os.ReadFile(s.credentialsFile)
+// StartYouTubeDeviceAuth inicia el flujo de autorización para dispositivos,
+// pensado para servidores y clientes sin navegador: devuelve un código que el
+// usuario introduce en la URL de verificación desde cualquier otro
+// dispositivo. Mientras tanto el servidor consulta a Google en segundo plano;
+// el cliente obtiene el resultado con PollYouTubeDeviceAuth.
+func (s *AuthService) StartYouTubeDeviceAuth(ctx context.Context, write bool) (*models.DeviceAuthResponse, error) {
+	config, err := s.oauthConfig(deviceScopes(write))
 	if err != nil {
-		return nil, models.NewInternalServerError("Unable to read credentials file", err)
+		return nil, err
 	}
 
-	var creds auth.Credentials
-	if err := json.Unmarshal(b, &creds); err != nil {
-		return nil, models.NewInternalServerError("Unable to parse credentials file", err)
+	da, err := config.DeviceAuth(ctx)
+	if err != nil {
+		return nil, deviceAuthError(err, "Failed to start device authorization")
+	}
+	// Google always sends expires_in, but without it polling would never stop
+	if da.Expiry.IsZero() {
+		da.Expiry = time.Now().Add(defaultDeviceCodeTTL)
 	}
 
-	// Create OAuth2 config
-	config := &oauth2.Config{
-		ClientID:     creds.Installed.ClientID,
-		ClientSecret: creds.Installed.ClientSecret,
-		RedirectURL:  creds.Installed.RedirectURIs[0],
-		Scopes:       []string{youtube.YoutubeReadonlyScope},
-		Endpoint:     google.Endpoint,
+	deviceID, pending, err := s.devices.add(da.Expiry)
+	if errors.Is(err, errTooManyDeviceAuths) {
+		return nil, models.NewTooManyRequestsError("Too many device authorizations in progress, try again later", err)
+	}
+	if err != nil {
+		return nil, models.NewInternalServerError("Unable to create device authorization", err)
 	}
 
-	// Exchange authorization code for token
-	tok, err := config.Exchange(context.TODO(), authCode)
+	s.pollers.Add(1)
+	go s.pollDeviceAuth(config, da, pending)
+
+	return &models.DeviceAuthResponse{
+		DeviceID:                deviceID,
+		UserCode:                da.UserCode,
+		VerificationURL:         da.VerificationURI,
+		VerificationURLComplete: da.VerificationURIComplete,
+		ExpiresAt:               da.Expiry,
+		Interval:                da.Interval,
+		Message:                 "Visita la URL de verificación e introduce el código para autorizar la aplicación",
+	}, nil
+}
+
+// pollDeviceAuth polls Google until the user answers or the code expires, and
+// signs the user in once they approve. The request that started the flow is
+// over by then, so it runs under the service context instead.
+func (s *AuthService) pollDeviceAuth(config *oauth2.Config, da *oauth2.DeviceAuthResponse, pending *deviceAuth) {
+	defer s.pollers.Done()
+
+	tok, err := config.DeviceAccessToken(s.ctx, da)
 	if err != nil {
-		return nil, models.NewBadRequestError("Invalid authorization code", err)
+		pending.finish(nil, deviceAuthError(err, "Device authorization failed"))
+		return
 	}
 
-	// Save token
-	f, err := os.OpenFile("token.json", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	ctx, cancel := context.WithTimeout(s.ctx, deviceSignInTimeout)
+	defer cancel()
+
+	response, err := s.saveYouTubeToken(ctx, tok)
 	if err != nil {
+		log.Printf("Device authorization approved but sign-in failed: %v", err)
+	}
+	pending.finish(response, err)
+}
+
+// PollYouTubeDeviceAuth devuelve el resultado de una autorización iniciada
+// con StartYouTubeDeviceAuth: nil mientras el usuario no responda, y la
+// sesión cuando la aprueba. El resultado se entrega una sola vez.
+func (s *AuthService) PollYouTubeDeviceAuth(ctx context.Context, deviceID string) (*models.AuthResponse, error) {
+	pending, ok := s.devices.take(deviceID)
+	if !ok {
+		return nil, models.NewNotFoundError("Device authorization not found or already completed", nil)
+	}
+
+	select {
+	case <-pending.done:
+		return pending.response, pending.err
+	default:
+		return nil, nil
+	}
+}
+
+// saveYouTubeToken identifies the user by their channel, saves the user and
+// the token under its ID and opens a session
+func (s *AuthService) saveYouTubeToken(ctx context.Context, tok *oauth2.Token) (*models.AuthResponse, error) {
+	channel, err := s.newClient(ctx, tok.AccessToken).GetMyChannel(ctx)
+	if errors.Is(err, youtubeapi.ErrNoChannel) {
+		return nil, models.NewBadRequestError("The account has no YouTube channel", err)
+	}
+	if err != nil {
+		return nil, youtubeError(err, "Failed to identify YouTube account")
+	}
+
+	if err := s.tokens.Save(ctx, channel.ID, tok); err != nil {
 		return nil, models.NewInternalServerError("Unable to save token", err)
 	}
-	defer f.Close()
-	json.NewEncoder(f).Encode(tok)
+	if err := s.sessions.SaveUser(ctx, channel.ID, channel.Snippet.Title); err != nil {
+		return nil, err
+	}
 
-	return &models.AuthResponse{
-		Success:     true,
-		AccessToken: tok.AccessToken,
-		Message:     "Successfully authenticated with YouTube",
-	}, nil
+	return s.signedIn(ctx, tok.AccessToken, channel.ID)
 }
 
-// AuthenticateWithYouTube handles YouTube OAuth authentication
-func (s *AuthService) AuthenticateWithYouTube() (*models.AuthResponse, error) {
-	accessToken, err := auth.GetAccessToken(s.credentialsFile)
+// signedIn opens a session for a user whose token was just saved
+func (s *AuthService) signedIn(ctx context.Context, accessToken, userID string) (*models.AuthResponse, error) {
+	sessionToken, expiresAt, err := s.sessions.CreateSession(ctx, userID)
 	if err != nil {
-		return nil, models.NewInternalServerError("Failed to authenticate with YouTube", err)
+		return nil, err
 	}
 
 	return &models.AuthResponse{
-		Success:     true,
-		AccessToken: accessToken,
-		Message:     "Successfully authenticated with YouTube",
+		Success:          true,
+		AccessToken:      accessToken,
+		UserID:           userID,
+		Message:          "Successfully authenticated with YouTube",
+		SessionToken:     sessionToken,
+		SessionExpiresAt: &expiresAt,
 	}, nil
 }
 
-// ValidateToken validates an access token
-func (s *AuthService) ValidateToken(token string) bool {
-	return auth.ValidateToken(token)
+// oauthConfig loads the OAuth client from the credentials file with the given scopes
+func (s *AuthService) oauthConfig(scopes []string) (*oauth2.Config, error) {
+	config, err := auth.LoadConfig(s.credentialsFile)
+	if err != nil {
+		return nil, models.NewInternalServerError("Unable to load credentials file", err)
+	}
+	config.Scopes = scopes
+	if s.redirectURL != "" {
+		config.RedirectURL = s.redirectURL
+	}
+	return config, nil
+}
+
+// youtubeScopes returns the OAuth scopes to request: read-only by default,
+// or full playlist management when write access is requested
+func youtubeScopes(write bool) []string {
+	if write {
+		return []string{youtube.YoutubeForceSslScope}
+	}
+	return []string{youtube.YoutubeReadonlyScope}
+}
+
+// deviceScopes is youtubeScopes for the device flow, where Google does not
+// allow youtube.force-ssl; the broader youtube scope grants write access
+func deviceScopes(write bool) []string {
+	if write {
+		return []string{youtube.YoutubeScope}
+	}
+	return []string{youtube.YoutubeReadonlyScope}
+}
+
+// deviceAuthError maps a device flow failure to an API error
+func deviceAuthError(err error, message string) error {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		switch retrieveErr.ErrorCode {
+		case "access_denied":
+			return models.NewForbiddenError("Authorization was not granted", err)
+		case "expired_token":
+			return models.NewAPIError("Device code expired, start again", http.StatusGone, err)
+		case "invalid_client", "unauthorized_client":
+			return models.NewInternalServerError("The OAuth client does not support the device flow", err)
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return models.NewAPIError("Device code expired, start again", http.StatusGone, err)
+	}
+	if errors.Is(err, context.Canceled) {
+		return models.NewServiceUnavailableError("The server restarted before the authorization was approved, start again", err)
+	}
+	return youtubeError(err, message)
 }
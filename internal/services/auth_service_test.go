@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube/youtubetest"
+	"golang.org/x/oauth2"
+)
+
+// userRecorder records the users saved to a store.Store
+type userRecorder struct {
+	store.Store
+
+	mu    sync.Mutex
+	users []store.User
+}
+
+func (r *userRecorder) SaveUser(ctx context.Context, user *store.User) error {
+	r.mu.Lock()
+	r.users = append(r.users, *user)
+	r.mu.Unlock()
+	return r.Store.SaveUser(ctx, user)
+}
+
+func TestSignInSavesUser(t *testing.T) {
+	ctx := context.Background()
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	yt.AddChannel(youtubetest.Channel{ID: "UC-user", Snippet: youtubetest.ChannelSnippet{Title: "My Channel"}}, true)
+
+	st := &userRecorder{Store: store.NewMemoryStore()}
+	tokens := store.NewTokenStore(st, store.ProviderYouTube)
+	sessions := NewSessionService(st, tokens, "", 0)
+	clients := NewClientFactory(youtube.WithBaseURL(yt.URL), youtube.WithoutRetries())
+	service := NewAuthService("", "", tokens, clients, sessions, nil)
+
+	tok := &oauth2.Token{AccessToken: "ya29.token", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}
+	response, err := service.saveYouTubeToken(ctx, tok)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.UserID != "UC-user" || response.SessionToken == "" {
+		t.Errorf("response = %+v", response)
+	}
+
+	if len(st.users) != 1 || st.users[0].ID != "UC-user" || st.users[0].DisplayName != "My Channel" {
+		t.Errorf("saved users = %+v, want UC-user named My Channel", st.users)
+	}
+	if saved, err := tokens.Load(ctx, "UC-user"); err != nil || saved.RefreshToken != "refresh" {
+		t.Errorf("saved token = %+v, %v", saved, err)
+	}
+}
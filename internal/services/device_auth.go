@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+)
+
+// defaultDeviceCodeTTL is how long a device code is polled for when Google
+// does not say when it expires
+const defaultDeviceCodeTTL = 30 * time.Minute
+
+// deviceAuthGrace is how long a finished device authorization waits to be
+// polled before it is dropped
+const deviceAuthGrace = 10 * time.Minute
+
+// maxPendingDeviceAuths bounds the device authorizations kept at once, each
+// with a goroutine polling Google, so unauthenticated clients cannot pile
+// them up
+const maxPendingDeviceAuths = 100
+
+// errTooManyDeviceAuths is returned by deviceAuths.add when it is full
+var errTooManyDeviceAuths = errors.New("too many pending device authorizations")
+
+// deviceAuth is a device authorization waiting for the user to approve it.
+// done is closed once response or err is set.
+type deviceAuth struct {
+	expiresAt time.Time
+	done      chan struct{}
+	response  *models.AuthResponse
+	err       error
+}
+
+// finish records the outcome of the authorization
+func (a *deviceAuth) finish(response *models.AuthResponse, err error) {
+	a.response = response
+	a.err = err
+	close(a.done)
+}
+
+// deviceAuths tracks pending device authorizations by device ID. The ID is
+// a random secret handed to the client that started the flow, since whoever
+// polls with it receives the session. Only its hash is kept, as with sessions.
+type deviceAuths struct {
+	mu      sync.Mutex
+	pending map[string]*deviceAuth
+}
+
+// newDeviceAuths creates an empty deviceAuths
+func newDeviceAuths() *deviceAuths {
+	return &deviceAuths{
+		pending: make(map[string]*deviceAuth),
+	}
+}
+
+// add registers an authorization that expires at expiresAt and returns its
+// device ID. Authorizations nobody polled are dropped; if maxPendingDeviceAuths
+// remain, add fails with errTooManyDeviceAuths.
+func (d *deviceAuths) add(expiresAt time.Time) (string, *deviceAuth, error) {
+	id, err := randomSecret()
+	if err != nil {
+		return "", nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for key, auth := range d.pending {
+		if now.After(auth.expiresAt.Add(deviceAuthGrace)) {
+			delete(d.pending, key)
+		}
+	}
+	if len(d.pending) >= maxPendingDeviceAuths {
+		return "", nil, errTooManyDeviceAuths
+	}
+
+	auth := &deviceAuth{
+		expiresAt: expiresAt,
+		done:      make(chan struct{}),
+	}
+	d.pending[secretHash(id)] = auth
+	return id, auth, nil
+}
+
+// take returns the authorization with the given device ID. A finished one is
+// removed, so its outcome is delivered once.
+func (d *deviceAuths) take(id string) (*deviceAuth, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := secretHash(id)
+	auth, ok := d.pending[key]
+	if !ok {
+		return nil, false
+	}
+
+	select {
+	case <-auth.done:
+		delete(d.pending, key)
+	default:
+	}
+	return auth, true
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"golang.org/x/oauth2"
+)
+
+func TestDeviceAuthsLimit(t *testing.T) {
+	devices := newDeviceAuths()
+	expiresAt := time.Now().Add(time.Hour)
+
+	// One long forgotten authorization, then enough live ones to fill up
+	if _, _, err := devices.add(time.Now().Add(-deviceAuthGrace - time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < maxPendingDeviceAuths; i++ {
+		if _, _, err := devices.add(expiresAt); err != nil {
+			t.Fatalf("authorization %d: %v", i+1, err)
+		}
+	}
+
+	// The forgotten one is dropped to make room for one more, but no more
+	id, last, err := devices.add(expiresAt)
+	if err != nil {
+		t.Fatalf("authorization after pruning: %v", err)
+	}
+	if _, _, err := devices.add(expiresAt); !errors.Is(err, errTooManyDeviceAuths) {
+		t.Fatalf("authorization over the limit: %v, want errTooManyDeviceAuths", err)
+	}
+
+	// Delivering an outcome frees its slot
+	last.finish(nil, nil)
+	if _, ok := devices.take(id); !ok {
+		t.Fatal("finished authorization not found")
+	}
+	if _, _, err := devices.add(expiresAt); err != nil {
+		t.Errorf("authorization after one was delivered: %v", err)
+	}
+}
+
+func TestAuthServiceShutdownStopsDevicePolling(t *testing.T) {
+	// Google keeps answering that the user has not approved yet
+	google := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"authorization_pending"}`))
+	}))
+	defer google.Close()
+
+	service := NewAuthService("", "", nil, nil, nil, nil)
+	config := &oauth2.Config{ClientID: "client-id", Endpoint: oauth2.Endpoint{TokenURL: google.URL}}
+	da := &oauth2.DeviceAuthResponse{DeviceCode: "device-code", Interval: 1, Expiry: time.Now().Add(time.Hour)}
+	_, pending, err := service.devices.add(da.Expiry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service.pollers.Add(1)
+	go service.pollDeviceAuth(config, da, pending)
+
+	stopped := make(chan struct{})
+	go func() {
+		service.Shutdown()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown did not stop the poller")
+	}
+
+	if !isDone(pending) {
+		t.Fatal("authorization not finished after Shutdown")
+	}
+	apiErr, ok := pending.err.(*models.APIError)
+	if !ok || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("error = %v, want 503", pending.err)
+	}
+}
+
+// isDone reports whether a device authorization has an outcome
+func isDone(auth *deviceAuth) bool {
+	select {
+	case <-auth.done:
+		return true
+	default:
+		return false
+	}
+}
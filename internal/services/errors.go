@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/pkg/destination"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+	"golang.org/x/oauth2"
+)
+
+// youtubeError maps an error returned by pkg/youtube to an APIError with the
+// matching status code. message is used when the failure is not recognised.
+func youtubeError(err error, message string) *models.APIError {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return models.NewGatewayTimeoutError("Timed out waiting for the YouTube API", err)
+	}
+	if errors.Is(err, context.Canceled) {
+		return models.NewAPIError("Request was cancelled", http.StatusRequestTimeout, err)
+	}
+
+	var quotaErr *youtube.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		return models.NewTooManyRequestsError(
+			fmt.Sprintf("Daily YouTube quota budget reached, it resets at %s", quotaErr.ResetAt.Format(time.RFC3339)), err)
+	}
+
+	// The session behind the client could not provide a token
+	var sessionErr *models.APIError
+	if errors.As(err, &sessionErr) {
+		return sessionErr
+	}
+	if isTokenRefreshError(err) {
+		return models.NewUnauthorizedError("Google rejected the refresh token, sign in again", err)
+	}
+
+	apiErr, ok := youtube.AsAPIError(err)
+	if !ok {
+		return models.NewInternalServerError(message, err)
+	}
+
+	switch {
+	case apiErr.IsAuthError():
+		return models.NewUnauthorizedError("YouTube rejected the access token", err)
+	case apiErr.IsRateLimited():
+		return models.NewTooManyRequestsError("YouTube API rate limit exceeded, try again later", err)
+	case apiErr.IsQuotaExceeded():
+		return models.NewForbiddenError("YouTube API quota exceeded", err)
+	case apiErr.IsNotFound():
+		return models.NewNotFoundError(notFoundMessage(apiErr.Reason), err)
+	case apiErr.StatusCode == http.StatusForbidden:
+		return models.NewForbiddenError("Access to the YouTube resource is forbidden", err)
+	case apiErr.StatusCode == http.StatusBadRequest:
+		return models.NewBadRequestError(apiErr.Message, err)
+	case apiErr.StatusCode == http.StatusServiceUnavailable:
+		return models.NewServiceUnavailableError("YouTube API is temporarily unavailable", err)
+	default:
+		return models.NewInternalServerError(message, err)
+	}
+}
+
+// isTokenRefreshError reports whether err comes from a failed token refresh
+func isTokenRefreshError(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	return errors.As(err, &retrieveErr)
+}
+
+// notFoundMessage returns a user-facing message for a *NotFound reason
+func notFoundMessage(reason string) string {
+	switch reason {
+	case youtube.ReasonPlaylistItemNotFound:
+		return "Playlist item not found"
+	case youtube.ReasonVideoNotFound:
+		return "Video not found"
+	case youtube.ReasonChannelNotFound:
+		return "Channel not found"
+	default:
+		return "Playlist not found"
+	}
+}
+
+// destinationError maps an error returned by a destination provider to an
+// APIError with the matching status code
+func destinationError(err error, message string) *models.APIError {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return models.NewGatewayTimeoutError("Timed out waiting for the destination API", err)
+	}
+
+	var statusErr destination.StatusError
+	if !errors.As(err, &statusErr) {
+		return models.NewInternalServerError(message, err)
+	}
+
+	switch status := statusErr.HTTPStatus(); {
+	case status == http.StatusUnauthorized:
+		return models.NewUnauthorizedError("The destination rejected the access token", err)
+	case status == http.StatusForbidden:
+		return models.NewForbiddenError("Access to the destination resource is forbidden", err)
+	case status == http.StatusNotFound:
+		return models.NewNotFoundError("Destination resource not found", err)
+	case status == http.StatusTooManyRequests:
+		return models.NewTooManyRequestsError("Destination API rate limit exceeded, try again later", err)
+	case status >= 500:
+		return models.NewServiceUnavailableError("Destination API is temporarily unavailable", err)
+	default:
+		return models.NewAPIError(message, status, err)
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+)
+
+func TestYouTubeError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantMessage string
+	}{
+		{
+			name:        "revoked token",
+			err:         &youtube.APIError{StatusCode: http.StatusUnauthorized},
+			wantStatus:  http.StatusUnauthorized,
+			wantMessage: "YouTube rejected the access token",
+		},
+		{
+			name:        "rate limited",
+			err:         &youtube.APIError{StatusCode: http.StatusForbidden, Reason: youtube.ReasonRateLimitExceeded},
+			wantStatus:  http.StatusTooManyRequests,
+			wantMessage: "YouTube API rate limit exceeded, try again later",
+		},
+		{
+			name:        "quota exceeded",
+			err:         &youtube.APIError{StatusCode: http.StatusForbidden, Reason: youtube.ReasonQuotaExceeded},
+			wantStatus:  http.StatusForbidden,
+			wantMessage: "YouTube API quota exceeded",
+		},
+		{
+			name:        "playlist not found",
+			err:         &youtube.APIError{StatusCode: http.StatusNotFound, Reason: youtube.ReasonPlaylistNotFound},
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "Playlist not found",
+		},
+		{
+			name:        "video not found",
+			err:         &youtube.APIError{StatusCode: http.StatusNotFound, Reason: youtube.ReasonVideoNotFound},
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "Video not found",
+		},
+		{
+			name:        "playlist item not found",
+			err:         &youtube.APIError{StatusCode: http.StatusForbidden, Reason: youtube.ReasonPlaylistItemNotFound},
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "Playlist item not found",
+		},
+		{
+			name:        "forbidden",
+			err:         &youtube.APIError{StatusCode: http.StatusForbidden, Reason: youtube.ReasonForbidden},
+			wantStatus:  http.StatusForbidden,
+			wantMessage: "Access to the YouTube resource is forbidden",
+		},
+		{
+			name:        "bad request keeps the API message",
+			err:         &youtube.APIError{StatusCode: http.StatusBadRequest, Message: "Invalid value for maxResults"},
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "Invalid value for maxResults",
+		},
+		{
+			name:        "unavailable",
+			err:         &youtube.APIError{StatusCode: http.StatusServiceUnavailable},
+			wantStatus:  http.StatusServiceUnavailable,
+			wantMessage: "YouTube API is temporarily unavailable",
+		},
+		{
+			name:        "backend error",
+			err:         &youtube.APIError{StatusCode: http.StatusInternalServerError, Reason: youtube.ReasonBackendError},
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "Failed to fetch playlists",
+		},
+		{
+			name:        "wrapped API error",
+			err:         fmt.Errorf("listing playlists: %w", &youtube.APIError{StatusCode: http.StatusUnauthorized}),
+			wantStatus:  http.StatusUnauthorized,
+			wantMessage: "YouTube rejected the access token",
+		},
+		{
+			name:        "not an API error",
+			err:         errors.New("connection refused"),
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "Failed to fetch playlists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := youtubeError(tt.err, "Failed to fetch playlists")
+
+			if got.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", got.StatusCode, tt.wantStatus)
+			}
+			if got.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", got.Message, tt.wantMessage)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Error("the YouTube error is not wrapped")
+			}
+		})
+	}
+}
@@ -1,33 +1,41 @@
 package services
 
 import (
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/alejpaa/playlist-migration-tool/internal/jobs"
 	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
 )
 
 // ExportService handles playlist export logic
 type ExportService struct {
 	playlistService *PlaylistService
+	store           store.Store
 }
 
-// NewExportService creates a new ExportService
-func NewExportService() *ExportService {
+// NewExportService creates a new ExportService. When st is not nil, a
+// snapshot of every exported playlist is kept there.
+func NewExportService(playlistService *PlaylistService, st store.Store) *ExportService {
 	return &ExportService{
-		playlistService: NewPlaylistService(),
+		playlistService: playlistService,
+		store:           st,
 	}
 }
 
 // ExportPlaylist exports a playlist in the specified format
-func (s *ExportService) ExportPlaylist(accessToken, playlistID string, request *models.ExportRequest) (*models.ExportResponse, error) {
+func (s *ExportService) ExportPlaylist(ctx context.Context, accessToken, playlistID string, request *models.ExportRequest) (*models.ExportResponse, error) {
 	// Get playlist details
-	playlist, err := s.playlistService.GetPlaylistByID(accessToken, playlistID)
+	playlist, err := s.playlistService.GetPlaylistByID(ctx, accessToken, playlistID)
 	if err != nil {
 		return nil, err
 	}
+	savePlaylistSnapshot(ctx, s.store, playlist)
+	jobs.ProgressFrom(ctx).SetTotal(len(playlist.Videos))
 
 	var exportData string
 	switch request.Format {
@@ -44,6 +52,7 @@ func (s *ExportService) ExportPlaylist(accessToken, playlistID string, request *
 	if err != nil {
 		return nil, models.NewInternalServerError("Failed to export playlist", err)
 	}
+	jobs.ProgressFrom(ctx).Advance(len(playlist.Videos))
 
 	return &models.ExportResponse{
 		Success: true,
@@ -69,12 +78,12 @@ func (s *ExportService) exportAsCSV(playlist *models.PlaylistDetailResponse, inc
 
 	// Write header
 	if includeInfo {
-		header := []string{"Position", "Title", "Channel", "Video ID", "Description", "Added At"}
+		header := []string{"Position", "Title", "Channel", "Video ID", "Duration", "Description", "Added At"}
 		if err := writer.Write(header); err != nil {
 			return "", err
 		}
 	} else {
-		header := []string{"Position", "Title", "Channel", "Video ID"}
+		header := []string{"Position", "Title", "Channel", "Video ID", "Duration"}
 		if err := writer.Write(header); err != nil {
 			return "", err
 		}
@@ -89,6 +98,7 @@ func (s *ExportService) exportAsCSV(playlist *models.PlaylistDetailResponse, inc
 				video.Title,
 				video.ChannelTitle,
 				video.ID,
+				video.Duration,
 				video.Description,
 				video.AddedAt.Format("2006-01-02 15:04:05"),
 			}
@@ -98,6 +108,7 @@ func (s *ExportService) exportAsCSV(playlist *models.PlaylistDetailResponse, inc
 				video.Title,
 				video.ChannelTitle,
 				video.ID,
+				video.Duration,
 			}
 		}
 		if err := writer.Write(row); err != nil {
@@ -123,7 +134,12 @@ func (s *ExportService) exportAsM3U(playlist *models.PlaylistDetailResponse) (st
 
 	// Add each video
 	for _, video := range playlist.Videos {
-		buffer.WriteString(fmt.Sprintf("#EXTINF:-1,%s - %s\n", video.ChannelTitle, video.Title))
+		// EXTINF takes the length in seconds, -1 when unknown
+		duration := -1
+		if video.DurationSeconds > 0 {
+			duration = video.DurationSeconds
+		}
+		buffer.WriteString(fmt.Sprintf("#EXTINF:%d,%s - %s\n", duration, video.ChannelTitle, video.Title))
 		buffer.WriteString(fmt.Sprintf("https://www.youtube.com/watch?v=%s\n", video.ID))
 	}
 
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/jobs"
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
+)
+
+// JobService runs exports and migrations as background jobs
+type JobService struct {
+	manager          *jobs.Manager
+	exportService    *ExportService
+	migrationService *MigrationService
+	exportTimeout    time.Duration
+	migrationTimeout time.Duration
+	store            store.Store
+}
+
+// NewJobService creates a new JobService. The timeouts bound how long each
+// export and migration job may run. When st is not nil, a record of each job
+// is kept there so its final state can still be queried after a restart.
+func NewJobService(manager *jobs.Manager, exportService *ExportService, migrationService *MigrationService, st store.Store, exportTimeout, migrationTimeout time.Duration) *JobService {
+	s := &JobService{
+		manager:          manager,
+		exportService:    exportService,
+		migrationService: migrationService,
+		exportTimeout:    exportTimeout,
+		migrationTimeout: migrationTimeout,
+		store:            st,
+	}
+	if st != nil {
+		manager.OnStateChange(s.saveJob)
+	}
+	return s
+}
+
+// CreateJob validates a job request and queues it for owner. The job runs
+// with the caller's access token after the request has returned.
+func (s *JobService) CreateJob(accessToken, owner string, request *models.JobRequest) (*models.JobResponse, error) {
+	var fn jobs.Func
+	var timeout time.Duration
+
+	switch request.Type {
+	case models.JobTypeExport:
+		if request.PlaylistID == "" {
+			return nil, models.NewBadRequestError("Playlist ID is required", nil)
+		}
+		export := models.ExportRequest{}
+		if request.Export != nil {
+			export = *request.Export
+		}
+		if export.Format == "" {
+			export.Format = "json"
+		}
+		if !validExportFormat(export.Format) {
+			return nil, models.NewBadRequestError("Unsupported export format", nil)
+		}
+		fn = func(ctx context.Context) (interface{}, error) {
+			return s.exportService.ExportPlaylist(ctx, accessToken, request.PlaylistID, &export)
+		}
+		timeout = s.exportTimeout
+
+	case models.JobTypeMigration:
+		if request.Migration == nil || request.Migration.SourcePlaylistID == "" || request.Migration.DestinationAccessToken == "" {
+			return nil, models.NewBadRequestError("Source playlist ID and destination access token are required", nil)
+		}
+		migration := *request.Migration
+		fn = func(ctx context.Context) (interface{}, error) {
+			return s.migrationService.CopyPlaylist(ctx, accessToken, owner, &migration)
+		}
+		timeout = s.migrationTimeout
+
+	case models.JobTypeDestinationMigration:
+		if request.PlaylistID == "" || request.Target == "" {
+			return nil, models.NewBadRequestError("Playlist ID and target are required", nil)
+		}
+		if request.Destination == nil || request.Destination.DestinationAccessToken == "" {
+			return nil, models.NewBadRequestError("Destination access token is required", nil)
+		}
+		destination := *request.Destination
+		fn = func(ctx context.Context) (interface{}, error) {
+			return s.migrationService.MigrateToDestination(ctx, accessToken, owner, request.PlaylistID, request.Target, &destination)
+		}
+		timeout = s.migrationTimeout
+
+	case models.JobTypeResumeMigration:
+		if request.MigrationID == "" || request.Resume == nil || request.Resume.DestinationAccessToken == "" {
+			return nil, models.NewBadRequestError("Migration ID and destination access token are required", nil)
+		}
+		resume := *request.Resume
+		fn = func(ctx context.Context) (interface{}, error) {
+			return s.migrationService.ResumeMigration(ctx, accessToken, owner, request.MigrationID, &resume)
+		}
+		timeout = s.migrationTimeout
+
+	default:
+		return nil, models.NewBadRequestError("Job type must be export, migration, destination_migration or resume_migration", nil)
+	}
+
+	job, err := s.manager.Submit(request.Type, owner, timeout, fn)
+	if err != nil {
+		return nil, jobError(err)
+	}
+	return toJobResponse(job), nil
+}
+
+// GetJob returns the state of a job of owner. Jobs from a previous run of the
+// server are read from the store, without their result.
+func (s *JobService) GetJob(owner, id string) (*models.JobResponse, error) {
+	job, err := s.ownedJob(owner, id)
+	if errors.Is(err, jobs.ErrNotFound) && s.store != nil {
+		return s.loadJob(owner, id)
+	}
+	if err != nil {
+		return nil, jobError(err)
+	}
+	return toJobResponse(job), nil
+}
+
+// CancelJob cancels a queued or running job of owner
+func (s *JobService) CancelJob(owner, id string) (*models.JobResponse, error) {
+	if _, err := s.ownedJob(owner, id); err != nil {
+		return nil, jobError(err)
+	}
+	job, err := s.manager.Cancel(id)
+	if err != nil {
+		return nil, jobError(err)
+	}
+	return toJobResponse(job), nil
+}
+
+// JobEvents returns the events of a job of owner after afterID. See
+// jobs.Manager.Events.
+func (s *JobService) JobEvents(owner, id string, afterID int) ([]jobs.Event, bool, <-chan struct{}, error) {
+	if _, err := s.ownedJob(owner, id); err != nil {
+		return nil, false, nil, jobError(err)
+	}
+	events, finished, changed, err := s.manager.Events(id, afterID)
+	if err != nil {
+		return nil, false, nil, jobError(err)
+	}
+	return events, finished, changed, nil
+}
+
+// FailInterruptedJobs marks the job records left queued or running by a
+// previous run of the server as failed, since nothing will finish them. It
+// must be called before any job is submitted, and returns how many it marked.
+func (s *JobService) FailInterruptedJobs(ctx context.Context) (int, error) {
+	if s.store == nil {
+		return 0, nil
+	}
+
+	records, err := s.store.ListJobs(ctx, jobs.StateQueued, jobs.StateRunning)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for _, record := range records {
+		var response models.JobResponse
+		if err := json.Unmarshal(record.Data, &response); err != nil {
+			return 0, fmt.Errorf("unable to decode job %s: %w", record.ID, err)
+		}
+		response.State = jobs.StateFailed
+		response.Errors = append(response.Errors, "interrupted: the server stopped before the job finished")
+		response.FinishedAt = &now
+
+		data, err := json.Marshal(response)
+		if err != nil {
+			return 0, fmt.Errorf("unable to encode job %s: %w", record.ID, err)
+		}
+		record.State = jobs.StateFailed
+		record.Data = data
+		record.FinishedAt = &now
+		if err := s.store.SaveJob(ctx, &record); err != nil {
+			return 0, err
+		}
+	}
+	return len(records), nil
+}
+
+// ownedJob returns a job from the manager. Jobs of other owners are reported
+// as not found, so their IDs cannot be probed.
+func (s *JobService) ownedJob(owner, id string) (*jobs.Job, error) {
+	job, err := s.manager.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Owner != owner {
+		return nil, jobs.ErrNotFound
+	}
+	return job, nil
+}
+
+// saveJob stores the record of a job. Results are left out: an export result
+// holds the whole playlist and can be produced again.
+func (s *JobService) saveJob(job jobs.Job) {
+	response := toJobResponse(&job)
+	response.Result = nil
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Failed to encode job %s: %v", job.ID, err)
+		return
+	}
+
+	record := &store.Job{
+		ID:         job.ID,
+		Type:       job.Type,
+		UserID:     job.Owner,
+		State:      job.State,
+		Data:       data,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if err := s.store.SaveJob(context.Background(), record); err != nil {
+		log.Printf("Failed to save job %s: %v", job.ID, err)
+	}
+}
+
+// loadJob reads a job record of owner from the store
+func (s *JobService) loadJob(owner, id string) (*models.JobResponse, error) {
+	record, err := s.store.GetJob(context.Background(), id)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && record.UserID != owner) {
+		return nil, jobError(jobs.ErrNotFound)
+	}
+	if err != nil {
+		return nil, models.NewInternalServerError("Failed to load job", err)
+	}
+
+	var response models.JobResponse
+	if err := json.Unmarshal(record.Data, &response); err != nil {
+		return nil, models.NewInternalServerError("Failed to decode job", err)
+	}
+	return &response, nil
+}
+
+// jobError maps a jobs.Manager error to an APIError
+func jobError(err error) *models.APIError {
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		return models.NewNotFoundError("Job not found", err)
+	case errors.Is(err, jobs.ErrFinished):
+		return models.NewConflictError("Job has already finished", err)
+	case errors.Is(err, jobs.ErrQueueFull), errors.Is(err, jobs.ErrShuttingDown):
+		return models.NewServiceUnavailableError("Cannot accept new jobs right now, try again later", err)
+	default:
+		return models.NewInternalServerError("Job failed", err)
+	}
+}
+
+// validExportFormat reports whether ExportService supports a format
+func validExportFormat(format string) bool {
+	switch format {
+	case "json", "csv", "m3u":
+		return true
+	default:
+		return false
+	}
+}
+
+// toJobResponse converts a job snapshot to its API representation
+func toJobResponse(job *jobs.Job) *models.JobResponse {
+	return &models.JobResponse{
+		ID:         job.ID,
+		Type:       job.Type,
+		State:      job.State,
+		Percent:    math.Round(job.Percent()*10) / 10,
+		Total:      job.Total,
+		Processed:  job.Processed,
+		Failed:     job.Failed,
+		Errors:     job.Errors,
+		Result:     job.Result,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/jobs"
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube/youtubetest"
+)
+
+// newTestJobService creates a JobService that exports from the fake YouTube
+// server and keeps job records in st
+func newTestJobService(t *testing.T, yt *youtubetest.Server, st store.Store) (*JobService, *jobs.Manager) {
+	t.Helper()
+
+	manager := jobs.NewManager(1, 10, 0)
+	t.Cleanup(func() { manager.Shutdown(context.Background()) })
+	playlists := NewPlaylistService(NewClientFactory(youtube.WithBaseURL(yt.URL), youtube.WithoutRetries()))
+	return NewJobService(manager, NewExportService(playlists, st), nil, st, 0, 0), manager
+}
+
+// waitForJob polls a job of owner until it finishes
+func waitForJob(t *testing.T, service *JobService, owner, id string) *models.JobResponse {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := service.GetJob(owner, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.FinishedAt != nil {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return nil
+}
+
+// expectNotFound fails the test unless err is a 404 APIError
+func expectNotFound(t *testing.T, what string, err error) {
+	t.Helper()
+
+	apiErr, ok := err.(*models.APIError)
+	if !ok || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("%s: error = %v, want 404", what, err)
+	}
+}
+
+func TestJobsAreOnlyVisibleToTheirOwner(t *testing.T) {
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	yt.SeedPlaylist("PL1", "Source", 3)
+
+	st, err := store.OpenSQLite("file:" + filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	service, manager := newTestJobService(t, yt, st)
+
+	job, err := service.CreateJob("token", "user-1", &models.JobRequest{Type: models.JobTypeExport, PlaylistID: "PL1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job := waitForJob(t, service, "user-1", job.ID); job.State != jobs.StateSucceeded {
+		t.Fatalf("job %s: %v", job.State, job.Errors)
+	}
+
+	_, err = service.GetJob("user-2", job.ID)
+	expectNotFound(t, "get", err)
+	_, _, _, err = service.JobEvents("user-2", job.ID, 0)
+	expectNotFound(t, "events", err)
+	_, err = service.CancelJob("user-2", job.ID)
+	expectNotFound(t, "cancel", err)
+
+	// The owner is told the job is over instead
+	_, err = service.CancelJob("user-1", job.ID)
+	if apiErr, ok := err.(*models.APIError); !ok || apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("owner cancel: error = %v, want 409", err)
+	}
+
+	// After a restart the record is read from the store, still for its owner only
+	manager.Shutdown(context.Background())
+	restarted, _ := newTestJobService(t, yt, st)
+	if stored, err := restarted.GetJob("user-1", job.ID); err != nil || stored.State != jobs.StateSucceeded {
+		t.Errorf("stored job = %+v, %v", stored, err)
+	}
+	_, err = restarted.GetJob("user-2", job.ID)
+	expectNotFound(t, "stored get", err)
+}
+
+func TestFailInterruptedJobs(t *testing.T) {
+	ctx := context.Background()
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	st := store.NewMemoryStore()
+
+	// Records left behind by a server that stopped without finishing them
+	finished := time.Now().Add(-time.Minute)
+	records := map[string]*models.JobResponse{
+		jobs.StateQueued:    {ID: "queued", Type: models.JobTypeExport, State: jobs.StateQueued},
+		jobs.StateRunning:   {ID: "running", Type: models.JobTypeMigration, State: jobs.StateRunning, Total: 10, Processed: 4},
+		jobs.StateSucceeded: {ID: "succeeded", Type: models.JobTypeExport, State: jobs.StateSucceeded, FinishedAt: &finished},
+	}
+	for state, response := range records {
+		data, err := json.Marshal(response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		record := &store.Job{ID: response.ID, Type: response.Type, UserID: "user-1", State: state, Data: data, CreatedAt: time.Now(), FinishedAt: response.FinishedAt}
+		if err := st.SaveJob(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	service, _ := newTestJobService(t, yt, st)
+	if n, err := service.FailInterruptedJobs(ctx); err != nil || n != 2 {
+		t.Fatalf("FailInterruptedJobs = %d, %v, want 2", n, err)
+	}
+
+	for _, id := range []string{"queued", "running"} {
+		job, err := service.GetJob("user-1", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.State != jobs.StateFailed || job.FinishedAt == nil || len(job.Errors) != 1 {
+			t.Errorf("job %s: state %s, finished %v, errors %v", id, job.State, job.FinishedAt, job.Errors)
+		}
+	}
+	if job, _ := service.GetJob("user-1", "running"); job.Processed != 4 {
+		t.Errorf("progress of the interrupted job lost: %+v", job)
+	}
+	if job, _ := service.GetJob("user-1", "succeeded"); job.State != jobs.StateSucceeded || len(job.Errors) != 0 {
+		t.Errorf("finished job changed: %+v", job)
+	}
+
+	if n, err := service.FailInterruptedJobs(ctx); err != nil || n != 0 {
+		t.Errorf("second call = %d, %v, want 0", n, err)
+	}
+}
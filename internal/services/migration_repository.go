@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
+)
+
+// ErrMigrationNotFound is returned by a MigrationRepository for unknown IDs
+var ErrMigrationNotFound = errors.New("migration not found")
+
+// MigrationRepository stores migration snapshots. Save must keep its own copy
+// so callers can keep modifying the migration they passed in.
+type MigrationRepository interface {
+	Get(id string) (*models.MigrationResponse, error)
+	Save(migration *models.MigrationResponse) error
+}
+
+// MemoryMigrationRepository keeps migrations in memory. They are lost on restart.
+type MemoryMigrationRepository struct {
+	mu         sync.RWMutex
+	migrations map[string]*models.MigrationResponse
+}
+
+// NewMemoryMigrationRepository creates an empty MemoryMigrationRepository
+func NewMemoryMigrationRepository() *MemoryMigrationRepository {
+	return &MemoryMigrationRepository{
+		migrations: make(map[string]*models.MigrationResponse),
+	}
+}
+
+// Get returns a copy of the stored migration
+func (r *MemoryMigrationRepository) Get(id string) (*models.MigrationResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	migration, ok := r.migrations[id]
+	if !ok {
+		return nil, ErrMigrationNotFound
+	}
+	return copyMigration(migration), nil
+}
+
+// Save stores a copy of the migration
+func (r *MemoryMigrationRepository) Save(migration *models.MigrationResponse) error {
+	snapshot := copyMigration(migration)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.migrations[migration.ID] = snapshot
+	return nil
+}
+
+// StoreMigrationRepository keeps migrations as snapshots in a store.Store
+type StoreMigrationRepository struct {
+	store store.Store
+}
+
+// NewStoreMigrationRepository creates a StoreMigrationRepository
+func NewStoreMigrationRepository(st store.Store) *StoreMigrationRepository {
+	return &StoreMigrationRepository{store: st}
+}
+
+// Get reads a migration snapshot
+func (r *StoreMigrationRepository) Get(id string) (*models.MigrationResponse, error) {
+	snapshot, err := r.store.GetSnapshot(context.Background(), store.SnapshotMigration, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrMigrationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migration: %w", err)
+	}
+
+	var migration models.MigrationResponse
+	if err := json.Unmarshal(snapshot.Data, &migration); err != nil {
+		return nil, fmt.Errorf("unable to decode migration: %w", err)
+	}
+	return &migration, nil
+}
+
+// Save writes a migration snapshot
+func (r *StoreMigrationRepository) Save(migration *models.MigrationResponse) error {
+	data, err := json.Marshal(migration)
+	if err != nil {
+		return fmt.Errorf("unable to encode migration: %w", err)
+	}
+
+	snapshot := &store.Snapshot{
+		Kind: store.SnapshotMigration,
+		ID:   migration.ID,
+		Data: data,
+	}
+	if err := r.store.SaveSnapshot(context.Background(), snapshot); err != nil {
+		return fmt.Errorf("unable to save migration: %w", err)
+	}
+	return nil
+}
+
+// copyMigration copies a migration, its tracks and its checkpoint
+func copyMigration(migration *models.MigrationResponse) *models.MigrationResponse {
+	snapshot := *migration
+	snapshot.Tracks = make([]models.MigrationTrackResponse, len(migration.Tracks))
+	for i, track := range migration.Tracks {
+		track.Candidates = append([]models.MatchCandidateResponse(nil), track.Candidates...)
+		snapshot.Tracks[i] = track
+	}
+	if migration.Checkpoint != nil {
+		checkpoint := *migration.Checkpoint
+		checkpoint.InsertedIDs = append([]string(nil), checkpoint.InsertedIDs...)
+		snapshot.Checkpoint = &checkpoint
+	}
+	return &snapshot
+}
@@ -0,0 +1,931 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/jobs"
+	"github.com/alejpaa/playlist-migration-tool/internal/matching"
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
+	"github.com/alejpaa/playlist-migration-tool/pkg/destination"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+)
+
+// Number of search results requested per track when matching on a destination
+const destinationSearchLimit = 5
+
+// DefaultReviewThreshold is the confidence under which matches are sent to review
+const DefaultReviewThreshold = 0.8
+
+// Number of suggested candidates kept for tracks sent to review
+const reviewCandidates = 5
+
+// Number of matched tracks added to a destination playlist per request
+const destinationBatchSize = 100
+
+// Number of tracks processed between two saves of a running migration. Tracks
+// inserted after the last save are found again by reconcile when resuming.
+const checkpointInterval = destinationBatchSize
+
+// MigrationService handles copying playlists between accounts and services
+type MigrationService struct {
+	playlistService *PlaylistService
+	newClient       ClientFactory
+	destinations    map[string]destination.Factory
+	scorer          *matching.Scorer
+	repo            MigrationRepository
+	store           store.Store
+	reviewThreshold float64
+
+	// mu serialises review decisions and the start of completions. It is never
+	// held during calls to YouTube or a destination.
+	mu sync.Mutex
+
+	// active holds the IDs of the migrations running in this process
+	activeMu sync.Mutex
+	active   map[string]bool
+}
+
+// NewMigrationService creates a new MigrationService. A nil factory uses the
+// default YouTube client and a nil repository keeps migrations in memory.
+// destinations maps a target name (e.g. "spotify") to the factory used to
+// build its provider. Matches under reviewThreshold wait for manual review.
+// When st is not nil, review decisions, an audit trail and snapshots of the
+// source playlists are recorded there.
+func NewMigrationService(playlistService *PlaylistService, newClient ClientFactory, destinations map[string]destination.Factory, repo MigrationRepository, st store.Store, reviewThreshold float64) *MigrationService {
+	if newClient == nil {
+		newClient = NewClientFactory()
+	}
+	if destinations == nil {
+		destinations = make(map[string]destination.Factory)
+	}
+	if repo == nil {
+		repo = NewMemoryMigrationRepository()
+	}
+	if reviewThreshold <= 0 || reviewThreshold > 1 {
+		reviewThreshold = DefaultReviewThreshold
+	}
+	return &MigrationService{
+		playlistService: playlistService,
+		newClient:       newClient,
+		destinations:    destinations,
+		scorer:          matching.NewScorer(),
+		repo:            repo,
+		store:           st,
+		reviewThreshold: reviewThreshold,
+		active:          make(map[string]bool),
+	}
+}
+
+// CopyPlaylist reads a playlist with the source token and recreates it, with
+// the same title, description, privacy and order, under the destination token.
+// Private, deleted and unavailable videos are skipped and reported. The
+// migration belongs to owner; see GetMigration.
+func (s *MigrationService) CopyPlaylist(ctx context.Context, sourceToken, owner string, request *models.MigrationRequest) (*models.MigrationResponse, error) {
+	if !validPrivacyStatus(request.PrivacyStatus) {
+		return nil, models.NewBadRequestError("Privacy status must be public, unlisted or private", nil)
+	}
+
+	source, err := s.playlistService.GetPlaylistByID(ctx, sourceToken, request.SourcePlaylistID)
+	if err != nil {
+		return nil, err
+	}
+	savePlaylistSnapshot(ctx, s.store, source)
+
+	migration := newMigration("youtube", owner, source)
+	s.begin(migration.ID)
+	defer s.end(migration.ID)
+
+	jobs.ProgressFrom(ctx).SetTotal(migration.Total)
+	s.save(migration)
+	s.audit("migration.created", migration.ID, "youtube playlist "+source.ID)
+
+	params := youtube.PlaylistParams{
+		Title:         source.Title,
+		Description:   source.Description,
+		PrivacyStatus: source.PrivacyStatus,
+	}
+	if request.Title != "" {
+		params.Title = request.Title
+		migration.Title = request.Title
+	}
+	if request.Description != nil {
+		params.Description = *request.Description
+	}
+	if request.PrivacyStatus != "" {
+		params.PrivacyStatus = request.PrivacyStatus
+	}
+
+	dest := s.newClient(ctx, request.DestinationAccessToken)
+
+	playlist, err := dest.CreatePlaylist(ctx, params)
+	if err != nil {
+		apiErr := youtubeError(err, "Failed to create destination playlist")
+		s.finish(migration, apiErr)
+		return nil, apiErr
+	}
+	migration.DestinationPlaylistID = playlist.ID
+	s.save(migration)
+
+	s.copyTracks(ctx, migration, dest, indexVideos(source.Videos))
+	return migration, nil
+}
+
+// MigrateToDestination reads a playlist with the source token and rebuilds it
+// on another service. Each video is searched on the destination and the
+// candidates are ranked by match confidence. Confident matches are added
+// straight away; the rest wait in the review queue and the migration stays in
+// review until CompleteMigration. Videos without a match are skipped. The
+// migration belongs to owner.
+func (s *MigrationService) MigrateToDestination(ctx context.Context, sourceToken, owner, playlistID, target string, request *models.DestinationMigrationRequest) (*models.MigrationResponse, error) {
+	newDestination, ok := s.destinations[target]
+	if !ok {
+		return nil, models.NewBadRequestError("Unsupported migration target: "+target, nil)
+	}
+
+	source, err := s.playlistService.GetPlaylistByID(ctx, sourceToken, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	savePlaylistSnapshot(ctx, s.store, source)
+
+	migration := newMigration(target, owner, source)
+	s.begin(migration.ID)
+	defer s.end(migration.ID)
+
+	jobs.ProgressFrom(ctx).SetTotal(migration.Total)
+	s.save(migration)
+	s.audit("migration.created", migration.ID, target+" playlist from "+source.ID)
+
+	params := destination.PlaylistParams{
+		Name:        source.Title,
+		Description: source.Description,
+		Public:      source.PrivacyStatus == youtube.PrivacyPublic,
+	}
+	if request.Title != "" {
+		params.Name = request.Title
+		migration.Title = request.Title
+	}
+	if request.Description != nil {
+		params.Description = *request.Description
+	}
+	if request.Public != nil {
+		params.Public = *request.Public
+	}
+
+	dest := newDestination(request.DestinationAccessToken)
+
+	playlist, err := dest.CreatePlaylist(ctx, params)
+	if err != nil {
+		apiErr := destinationError(err, "Failed to create destination playlist")
+		s.finish(migration, apiErr)
+		return nil, apiErr
+	}
+	migration.DestinationPlaylistID = playlist.ID
+	s.save(migration)
+
+	s.migrateTracks(ctx, migration, dest, indexVideos(source.Videos))
+	return migration, nil
+}
+
+// ResumeMigration continues a migration that stopped before processing every
+// track, because it failed or the server restarted. The destination playlist
+// is read first and tracks already in it are marked as inserted, so resuming
+// never adds a track twice. Resuming a completed migration returns it unchanged.
+func (s *MigrationService) ResumeMigration(ctx context.Context, sourceToken, owner, id string, request *models.ResumeMigrationRequest) (*models.MigrationResponse, error) {
+	// Check the owner first, so others get a 404 even while it runs
+	if _, err := s.GetMigration(owner, id); err != nil {
+		return nil, err
+	}
+	if !s.begin(id) {
+		return nil, models.NewConflictError("Migration is already running", nil)
+	}
+	defer s.end(id)
+
+	// Read it again now that no other run can change it
+	migration, err := s.GetMigration(owner, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case migration.Status == models.MigrationStatusCompleted:
+		return migration, nil
+	case migration.Status == models.MigrationStatusReview:
+		return nil, models.NewConflictError("Migration is awaiting review, complete it instead", nil)
+	case migration.DestinationPlaylistID == "":
+		return nil, models.NewConflictError("Migration stopped before creating its destination playlist, start a new one", nil)
+	}
+
+	source, err := s.playlistService.GetPlaylistByID(ctx, sourceToken, migration.SourcePlaylistID)
+	if err != nil {
+		return nil, err
+	}
+	savePlaylistSnapshot(ctx, s.store, source)
+	videos := indexVideos(source.Videos)
+
+	var resume func()
+	if migration.Target == "youtube" {
+		dest := s.newClient(ctx, request.DestinationAccessToken)
+		items, err := dest.ListAllPlaylistItems(ctx, migration.DestinationPlaylistID)
+		if err != nil {
+			return nil, youtubeError(err, "Failed to read destination playlist")
+		}
+
+		present := make(map[string][]string, len(items))
+		for _, item := range items {
+			videoID := item.Snippet.ResourceID.VideoID
+			present[videoID] = append(present[videoID], item.ID)
+		}
+		reconcile(migration, present, func(track *models.MigrationTrackResponse) string { return track.SourceID })
+
+		resume = func() { s.copyTracks(ctx, migration, dest, videos) }
+	} else {
+		newDestination, ok := s.destinations[migration.Target]
+		if !ok {
+			return nil, models.NewBadRequestError("Unsupported migration target: "+migration.Target, nil)
+		}
+
+		dest := newDestination(request.DestinationAccessToken)
+		trackIDs, err := dest.PlaylistTrackIDs(ctx, migration.DestinationPlaylistID)
+		if err != nil {
+			return nil, destinationError(err, "Failed to read destination playlist")
+		}
+
+		present := make(map[string][]string, len(trackIDs))
+		for _, trackID := range trackIDs {
+			present[trackID] = append(present[trackID], trackID)
+		}
+		reconcile(migration, present, func(track *models.MigrationTrackResponse) string { return track.DestinationID })
+
+		resume = func() { s.migrateTracks(ctx, migration, dest, videos) }
+	}
+
+	remaining := 0
+	for i := range migration.Tracks {
+		if resumable(&migration.Tracks[i]) {
+			remaining++
+		}
+	}
+	jobs.ProgressFrom(ctx).SetTotal(remaining)
+
+	migration.Status = models.MigrationStatusRunning
+	migration.Error = ""
+	migration.FinishedAt = nil
+	s.save(migration)
+	log.Printf("Resuming migration %s at position %d: %d tracks left", migration.ID, migration.Checkpoint.Position, remaining)
+	s.audit("migration.resumed", migration.ID, fmt.Sprintf("%d tracks left", remaining))
+
+	resume()
+	return migration, nil
+}
+
+// copyTracks inserts every track that is still pending, or failed before, into
+// the destination YouTube playlist. Items are appended, so positions match the
+// source as long as no earlier track has to be retried.
+func (s *MigrationService) copyTracks(ctx context.Context, migration *models.MigrationResponse, dest *youtube.Client, videos map[string]*models.VideoResponse) {
+	processed := 0
+	for i := range migration.Tracks {
+		track := &migration.Tracks[i]
+		if !resumable(track) {
+			continue
+		}
+		processed++
+		if processed%checkpointInterval == 0 {
+			s.save(migration)
+		}
+
+		video, ok := videos[track.SourceID]
+		if !ok {
+			s.skipTrack(ctx, migration, track, "Video is no longer in the source playlist")
+			continue
+		}
+		if reason := skipReason(video); reason != "" {
+			s.skipTrack(ctx, migration, track, reason)
+			continue
+		}
+
+		item, err := dest.InsertPlaylistItem(ctx, migration.DestinationPlaylistID, track.SourceID, nil)
+		if err != nil {
+			s.failTracks(ctx, migration, youtubeError(err, "Failed to insert video").Message, track)
+			if isFatalYouTubeError(err) {
+				s.finish(migration, err)
+				return
+			}
+			continue
+		}
+
+		track.Status = models.TrackStatusInserted
+		track.DestinationID = item.ID
+		track.Reason = ""
+		progress := jobs.ProgressFrom(ctx)
+		progress.Advance(1)
+		progress.Emit(jobs.EventTrackInserted, *track)
+	}
+
+	s.finish(migration, nil)
+}
+
+// migrateTracks matches every track that is still pending, or failed before,
+// on the destination and adds the matches in source order. Tracks matched
+// before the migration stopped are added without searching again.
+func (s *MigrationService) migrateTracks(ctx context.Context, migration *models.MigrationResponse, dest destination.Destination, videos map[string]*models.VideoResponse) {
+	var matched []int
+	processed := 0
+	for i := range migration.Tracks {
+		track := &migration.Tracks[i]
+		if !resumable(track) {
+			continue
+		}
+		processed++
+		if processed%checkpointInterval == 0 {
+			s.save(migration)
+		}
+		if track.DestinationID != "" {
+			matched = append(matched, i)
+			continue
+		}
+
+		video, ok := videos[track.SourceID]
+		if !ok {
+			s.skipTrack(ctx, migration, track, "Video is no longer in the source playlist")
+			continue
+		}
+		if reason := skipReason(video); reason != "" {
+			s.skipTrack(ctx, migration, track, reason)
+			continue
+		}
+
+		results, err := dest.SearchTracks(ctx, searchQuery(video), destinationSearchLimit)
+		if err != nil {
+			s.failTracks(ctx, migration, destinationError(err, "Failed to search destination").Message, track)
+			if isFatalDestinationError(err) {
+				s.finish(migration, err)
+				return
+			}
+			continue
+		}
+		if len(results) == 0 {
+			s.skipTrack(ctx, migration, track, "No match found on "+migration.Target)
+			continue
+		}
+
+		ranked := s.scorer.Rank(matching.NewSource(video), results)
+		track.Confidence = ranked[0].Confidence
+		track.Reason = ""
+		if track.Confidence < s.reviewThreshold {
+			track.Status = models.TrackStatusReview
+			track.Candidates = toCandidateResponses(ranked)
+		} else {
+			track.Status = models.TrackStatusPending
+			track.DestinationID = ranked[0].Track.ID
+			matched = append(matched, i)
+		}
+		jobs.ProgressFrom(ctx).Emit(jobs.EventTrackMatched, *track)
+	}
+
+	if err := s.addTracks(ctx, migration, dest, matched); err != nil {
+		s.finish(migration, err)
+		return
+	}
+
+	s.finish(migration, nil)
+}
+
+// GetReviewQueue returns the tracks of a migration of owner waiting for review
+func (s *MigrationService) GetReviewQueue(owner, id string) (*models.ReviewQueueResponse, error) {
+	migration, err := s.GetMigration(owner, id)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := &models.ReviewQueueResponse{
+		MigrationID:   migration.ID,
+		Status:        migration.Status,
+		PendingReview: migration.PendingReview,
+		Tracks:        []models.MigrationTrackResponse{},
+	}
+	for _, track := range migration.Tracks {
+		if track.Status == models.TrackStatusReview {
+			queue.Tracks = append(queue.Tracks, track)
+		}
+	}
+	return queue, nil
+}
+
+// ReviewTrack records a decision for a track waiting for review: accept the
+// best candidate, select another candidate, supply a manual destination ID or
+// skip the track
+func (s *MigrationService) ReviewTrack(owner, id string, position int, request *models.ReviewDecisionRequest) (*models.MigrationTrackResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	migration, err := s.GetMigration(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if migration.Status != models.MigrationStatusReview {
+		return nil, models.NewConflictError("Migration is not awaiting review", nil)
+	}
+	if position < 0 || position >= len(migration.Tracks) {
+		return nil, models.NewNotFoundError("Track not found", nil)
+	}
+
+	track := &migration.Tracks[position]
+	if track.Status != models.TrackStatusReview {
+		return nil, models.NewConflictError("Track is not awaiting review", nil)
+	}
+
+	switch request.Action {
+	case models.ReviewActionAccept:
+		if len(track.Candidates) == 0 {
+			return nil, models.NewConflictError("Track has no suggested candidate, match it manually or skip it", nil)
+		}
+		track.DestinationID = track.Candidates[0].ID
+	case models.ReviewActionSelect:
+		candidate := findCandidate(track.Candidates, request.DestinationID)
+		if candidate == nil {
+			return nil, models.NewBadRequestError("Destination ID must be one of the suggested candidates", nil)
+		}
+		track.DestinationID = candidate.ID
+		track.Confidence = candidate.Confidence
+	case models.ReviewActionManual:
+		if request.DestinationID == "" {
+			return nil, models.NewBadRequestError("Destination ID is required for a manual match", nil)
+		}
+		newDestination, ok := s.destinations[migration.Target]
+		if !ok {
+			return nil, models.NewBadRequestError("Unsupported migration target: "+migration.Target, nil)
+		}
+		// Users paste URIs and share links as often as bare IDs
+		destinationID, err := newDestination("").ParseTrackID(request.DestinationID)
+		if err != nil {
+			return nil, models.NewBadRequestError("Destination ID must be a track ID, URI or URL on "+migration.Target, err)
+		}
+		track.DestinationID = destinationID
+		track.Confidence = 0
+		track.Reason = "Matched manually"
+	case models.ReviewActionSkip:
+		track.Status = models.TrackStatusSkipped
+		track.Reason = "Skipped in review"
+	default:
+		return nil, models.NewBadRequestError("Action must be accept, select, manual or skip", nil)
+	}
+
+	if track.Status == models.TrackStatusReview {
+		track.Status = models.TrackStatusApproved
+	}
+	s.save(migration)
+	s.recordDecision(migration.ID, position, track, request.Action)
+
+	return track, nil
+}
+
+// CompleteMigration adds the tracks approved in review to the destination
+// playlist and finalises the migration. It fails with a conflict while tracks
+// are still awaiting review, unless force is set, in which case they are skipped.
+func (s *MigrationService) CompleteMigration(ctx context.Context, owner, id string, request *models.CompleteMigrationRequest) (*models.MigrationResponse, error) {
+	if _, err := s.GetMigration(owner, id); err != nil {
+		return nil, err
+	}
+	if !s.begin(id) {
+		return nil, models.NewConflictError("Migration is already running", nil)
+	}
+	defer s.end(id)
+
+	migration, newDestination, approved, err := s.startCompletion(owner, id, request.Force)
+	if err != nil {
+		return nil, err
+	}
+
+	dest := newDestination(request.DestinationAccessToken)
+	err = s.addTracks(ctx, migration, dest, approved)
+	s.finish(migration, err)
+	s.audit("migration.completed", migration.ID, fmt.Sprintf("%d approved tracks, status %s", len(approved), migration.Status))
+	return migration, nil
+}
+
+// startCompletion checks that a migration can be completed and moves it out
+// of review, so no decision can change it while its approved tracks, whose
+// indexes are returned, are being added. Holding mu only for this step keeps
+// other migrations' reviews from waiting on the destination.
+func (s *MigrationService) startCompletion(owner, id string, force bool) (*models.MigrationResponse, destination.Factory, []int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	migration, err := s.GetMigration(owner, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if migration.Status != models.MigrationStatusReview {
+		return nil, nil, nil, models.NewConflictError("Migration is not awaiting review", nil)
+	}
+	if migration.PendingReview > 0 && !force {
+		return nil, nil, nil, models.NewConflictError(fmt.Sprintf("%d tracks are still awaiting review", migration.PendingReview), nil)
+	}
+
+	newDestination, ok := s.destinations[migration.Target]
+	if !ok {
+		return nil, nil, nil, models.NewBadRequestError("Unsupported migration target: "+migration.Target, nil)
+	}
+
+	var approved []int
+	for i := range migration.Tracks {
+		track := &migration.Tracks[i]
+		switch track.Status {
+		case models.TrackStatusReview:
+			track.Status = models.TrackStatusSkipped
+			track.Reason = "Not reviewed before completion"
+		case models.TrackStatusApproved:
+			approved = append(approved, i)
+		}
+	}
+	migration.Status = models.MigrationStatusRunning
+	s.save(migration)
+	return migration, newDestination, approved, nil
+}
+
+// addTracks adds the tracks at the given indexes to the destination playlist
+// in batches. Failed batches are recorded on their tracks; the returned error
+// is non-nil only when a failure will affect every following batch.
+func (s *MigrationService) addTracks(ctx context.Context, migration *models.MigrationResponse, dest destination.Destination, indexes []int) error {
+	for start := 0; start < len(indexes); start += destinationBatchSize {
+		end := start + destinationBatchSize
+		if end > len(indexes) {
+			end = len(indexes)
+		}
+
+		batch := make([]*models.MigrationTrackResponse, 0, end-start)
+		trackIDs := make([]string, 0, end-start)
+		for _, i := range indexes[start:end] {
+			batch = append(batch, &migration.Tracks[i])
+			trackIDs = append(trackIDs, migration.Tracks[i].DestinationID)
+		}
+
+		if err := dest.AddTracks(ctx, migration.DestinationPlaylistID, trackIDs); err != nil {
+			s.failTracks(ctx, migration, destinationError(err, "Failed to add tracks").Message, batch...)
+			if isFatalDestinationError(err) {
+				return err
+			}
+			s.save(migration)
+			continue
+		}
+
+		progress := jobs.ProgressFrom(ctx)
+		for _, track := range batch {
+			track.Status = models.TrackStatusInserted
+			track.Reason = ""
+			progress.Emit(jobs.EventTrackInserted, *track)
+		}
+		progress.Advance(len(batch))
+		s.save(migration)
+	}
+
+	return nil
+}
+
+// GetMigration returns a migration of owner by ID. Migrations started by
+// someone else are reported as not found, so their IDs cannot be probed.
+func (s *MigrationService) GetMigration(owner, id string) (*models.MigrationResponse, error) {
+	migration, err := s.repo.Get(id)
+	if errors.Is(err, ErrMigrationNotFound) || (err == nil && migration.UserID != owner) {
+		return nil, models.NewNotFoundError("Migration not found", err)
+	}
+	if err != nil {
+		return nil, models.NewInternalServerError("Failed to load migration", err)
+	}
+	return migration, nil
+}
+
+// save updates the counters and checkpoint of a migration and stores a
+// snapshot, so its progress can be queried while it is still running and it
+// can be resumed after a restart. Running migrations are saved every
+// checkpointInterval tracks, after each batch added to a destination and when
+// they finish. A failed save is logged but does not stop the migration.
+func (s *MigrationService) save(migration *models.MigrationResponse) {
+	updateProgress(migration)
+	if err := s.repo.Save(migration); err != nil {
+		log.Printf("Failed to save migration %s: %v", migration.ID, err)
+	}
+}
+
+// recordDecision stores a review decision. Like save, a failure is only logged.
+func (s *MigrationService) recordDecision(id string, position int, track *models.MigrationTrackResponse, action string) {
+	if s.store == nil {
+		return
+	}
+
+	decision := &store.MatchDecision{
+		MigrationID:   id,
+		Position:      position,
+		SourceID:      track.SourceID,
+		DestinationID: track.DestinationID,
+		Action:        action,
+		Confidence:    track.Confidence,
+		DecidedAt:     time.Now(),
+	}
+	if err := s.store.SaveMatchDecision(context.Background(), decision); err != nil {
+		log.Printf("Failed to save review decision for migration %s track %d: %v", id, position, err)
+	}
+	s.audit("migration.reviewed", id, fmt.Sprintf("track %d: %s", position, action))
+}
+
+// audit appends an entry to the audit trail
+func (s *MigrationService) audit(action, target, details string) {
+	if s.store == nil {
+		return
+	}
+
+	entry := &store.AuditEntry{Action: action, Target: target, Details: details}
+	if err := s.store.AddAuditEntry(context.Background(), entry); err != nil {
+		log.Printf("Failed to record %s for %s: %v", action, target, err)
+	}
+}
+
+// finish sets the final status of a migration. A non-nil err means the
+// migration was aborted before every track was processed; it can be resumed.
+func (s *MigrationService) finish(migration *models.MigrationResponse, err error) {
+	updateProgress(migration)
+
+	now := time.Now()
+	migration.FinishedAt = &now
+
+	switch {
+	case err != nil:
+		migration.Status = models.MigrationStatusFailed
+		migration.Error = err.Error()
+	case migration.PendingReview > 0:
+		migration.Status = models.MigrationStatusReview
+		migration.FinishedAt = nil
+	case migration.Failed > 0:
+		migration.Status = models.MigrationStatusPartial
+	default:
+		migration.Status = models.MigrationStatusCompleted
+	}
+
+	s.save(migration)
+
+	log.Printf("Migration %s %s: %d/%d processed, %d inserted, %d skipped, %d failed",
+		migration.ID, migration.Status, migration.Processed, migration.Total,
+		migration.Inserted, migration.Skipped, migration.Failed)
+}
+
+// skipTrack marks a track as skipped with the given reason
+func (s *MigrationService) skipTrack(ctx context.Context, migration *models.MigrationResponse, track *models.MigrationTrackResponse, reason string) {
+	track.Status = models.TrackStatusSkipped
+	track.Reason = reason
+	progress := jobs.ProgressFrom(ctx)
+	progress.Advance(1)
+	progress.Emit(jobs.EventTrackSkipped, *track)
+}
+
+// failTracks marks tracks as failed with the given reason
+func (s *MigrationService) failTracks(ctx context.Context, migration *models.MigrationResponse, reason string, tracks ...*models.MigrationTrackResponse) {
+	progress := jobs.ProgressFrom(ctx)
+	for _, track := range tracks {
+		track.Status = models.TrackStatusFailed
+		track.Reason = reason
+		progress.Emit(jobs.EventTrackFailed, *track)
+	}
+	progress.Fail(len(tracks), reason)
+}
+
+// begin marks a migration as running in this process. It returns false when
+// it already is.
+func (s *MigrationService) begin(id string) bool {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+
+	if s.active[id] {
+		return false
+	}
+	s.active[id] = true
+	return true
+}
+
+// end marks a migration as no longer running in this process
+func (s *MigrationService) end(id string) {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+	delete(s.active, id)
+}
+
+// newMigration creates a migration of a source playlist for owner with every
+// track pending
+func newMigration(target, owner string, source *models.PlaylistDetailResponse) *models.MigrationResponse {
+	migration := &models.MigrationResponse{
+		ID:               newID(),
+		Target:           target,
+		UserID:           owner,
+		Status:           models.MigrationStatusRunning,
+		SourcePlaylistID: source.ID,
+		Title:            source.Title,
+		Total:            len(source.Videos),
+		Tracks:           make([]models.MigrationTrackResponse, len(source.Videos)),
+		StartedAt:        time.Now(),
+	}
+	for i, video := range source.Videos {
+		migration.Tracks[i] = models.MigrationTrackResponse{
+			Position: i,
+			SourceID: video.ID,
+			Title:    video.Title,
+			Status:   models.TrackStatusPending,
+		}
+	}
+	return migration
+}
+
+// updateProgress recomputes the counters of a migration from its tracks and
+// records a checkpoint
+func updateProgress(migration *models.MigrationResponse) {
+	migration.Processed, migration.Inserted, migration.Skipped, migration.Failed, migration.PendingReview = 0, 0, 0, 0, 0
+
+	checkpoint := &models.MigrationCheckpoint{
+		Position:              len(migration.Tracks),
+		DestinationPlaylistID: migration.DestinationPlaylistID,
+		InsertedIDs:           []string{},
+		UpdatedAt:             time.Now(),
+	}
+
+	for i := range migration.Tracks {
+		track := &migration.Tracks[i]
+		switch track.Status {
+		case models.TrackStatusInserted:
+			migration.Inserted++
+			checkpoint.InsertedIDs = append(checkpoint.InsertedIDs, track.SourceID)
+		case models.TrackStatusSkipped:
+			migration.Skipped++
+		case models.TrackStatusFailed:
+			migration.Failed++
+		case models.TrackStatusReview:
+			migration.PendingReview++
+		}
+		if resumable(track) && track.Position < checkpoint.Position {
+			checkpoint.Position = track.Position
+		}
+	}
+	migration.Processed = migration.Inserted + migration.Skipped + migration.Failed
+	migration.Checkpoint = checkpoint
+}
+
+// resumable reports whether a track still has to be added to the destination
+func resumable(track *models.MigrationTrackResponse) bool {
+	switch track.Status {
+	case models.TrackStatusPending, models.TrackStatusFailed, models.TrackStatusApproved:
+		return true
+	default:
+		return false
+	}
+}
+
+// reconcile marks tracks that are already in the destination playlist as
+// inserted. present maps the key of each track in the destination (see key)
+// to its destination IDs. Tracks recorded as inserted use up their entries
+// first, so a song that appears twice in the source is still added twice.
+func reconcile(migration *models.MigrationResponse, present map[string][]string, key func(*models.MigrationTrackResponse) string) {
+	take := func(k string) (string, bool) {
+		ids := present[k]
+		if k == "" || len(ids) == 0 {
+			return "", false
+		}
+		present[k] = ids[1:]
+		return ids[0], true
+	}
+
+	for i := range migration.Tracks {
+		if track := &migration.Tracks[i]; track.Status == models.TrackStatusInserted {
+			take(key(track))
+		}
+	}
+
+	for i := range migration.Tracks {
+		track := &migration.Tracks[i]
+		if !resumable(track) {
+			continue
+		}
+		if id, ok := take(key(track)); ok {
+			track.Status = models.TrackStatusInserted
+			track.DestinationID = id
+			track.Reason = ""
+		}
+	}
+}
+
+// indexVideos maps source videos by video ID
+func indexVideos(videos []models.VideoResponse) map[string]*models.VideoResponse {
+	index := make(map[string]*models.VideoResponse, len(videos))
+	for i := range videos {
+		index[videos[i].ID] = &videos[i]
+	}
+	return index
+}
+
+// toCandidateResponses converts the best ranked matches to review candidates
+func toCandidateResponses(matches []matching.Match) []models.MatchCandidateResponse {
+	if len(matches) > reviewCandidates {
+		matches = matches[:reviewCandidates]
+	}
+
+	candidates := make([]models.MatchCandidateResponse, len(matches))
+	for i, match := range matches {
+		factors := make([]string, len(match.Factors))
+		for j, factor := range match.Factors {
+			factors[j] = factor.Explanation
+		}
+		candidates[i] = models.MatchCandidateResponse{
+			ID:              match.Track.ID,
+			Title:           match.Track.Title,
+			Artists:         match.Track.Artists,
+			Album:           match.Track.Album,
+			DurationSeconds: int(match.Track.Duration.Seconds()),
+			Confidence:      match.Confidence,
+			Factors:         factors,
+		}
+	}
+	return candidates
+}
+
+// findCandidate returns the candidate with the given destination ID, or nil
+func findCandidate(candidates []models.MatchCandidateResponse, id string) *models.MatchCandidateResponse {
+	for i := range candidates {
+		if candidates[i].ID == id {
+			return &candidates[i]
+		}
+	}
+	return nil
+}
+
+// searchQuery builds the destination search query for a source video
+func searchQuery(video *models.VideoResponse) string {
+	return matching.Parse(video.Title, video.ChannelTitle).String()
+}
+
+// skipReason explains why a source video cannot be migrated, or returns ""
+func skipReason(video *models.VideoResponse) string {
+	switch video.Availability {
+	case models.AvailabilityPrivate:
+		return "Video is private"
+	case models.AvailabilityDeleted:
+		return "Video was deleted"
+	case models.AvailabilityUnavailable:
+		return "Video is unavailable"
+	default:
+		return ""
+	}
+}
+
+// isFatalYouTubeError reports whether an error will affect every following
+// call (expired token, exhausted quota or cancelled request)
+func isFatalYouTubeError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var quotaErr *youtube.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		return true
+	}
+
+	// No token could be obtained for the session
+	var sessionErr *models.APIError
+	if errors.As(err, &sessionErr) || isTokenRefreshError(err) {
+		return true
+	}
+
+	apiErr, ok := youtube.AsAPIError(err)
+	return ok && (apiErr.IsAuthError() || apiErr.IsQuotaExceeded())
+}
+
+// isFatalDestinationError reports whether a destination error will affect
+// every following call (rejected token, cancelled request, or a rate limit
+// that outlasted the retries of the destination client)
+func isFatalDestinationError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var statusErr destination.StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+
+	status := statusErr.HTTPStatus()
+	return status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusTooManyRequests
+}
+
+// newID returns a random identifier for migrations and other resources
+func newID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
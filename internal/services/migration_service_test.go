@@ -0,0 +1,753 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/matching"
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/pkg/destination"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube/youtubetest"
+)
+
+// countingRepository counts the saves made to a MigrationRepository
+type countingRepository struct {
+	MigrationRepository
+
+	mu    sync.Mutex
+	saves int
+}
+
+func (r *countingRepository) Save(migration *models.MigrationResponse) error {
+	r.mu.Lock()
+	r.saves++
+	r.mu.Unlock()
+	return r.MigrationRepository.Save(migration)
+}
+
+func (r *countingRepository) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.saves
+}
+
+// fakeDestination is an in-memory destination whose catalogue has one track
+// per search query. Its track URIs have the form fake:track:ID.
+type fakeDestination struct {
+	mu        sync.Mutex
+	catalogue map[string]destination.Track
+	playlists map[string][]string
+	addCalls  int
+	failAdds  error // Returned by every AddTracks call when set
+}
+
+func newFakeDestination() *fakeDestination {
+	return &fakeDestination{
+		catalogue: make(map[string]destination.Track),
+		playlists: make(map[string][]string),
+	}
+}
+
+// addSeededTracks adds the tracks of a playlist seeded with SeedPlaylist to the catalogue
+func (d *fakeDestination) addSeededTracks(playlistID string, n int) {
+	for i := 0; i < n; i++ {
+		title := fmt.Sprintf("Artist %d - Song %d", i, i)
+		d.catalogue[matching.Parse(title, "Channel UC-test").String()] = destination.Track{
+			ID:       fmt.Sprintf("%s-track-%d", playlistID, i),
+			Title:    fmt.Sprintf("Song %d", i),
+			Artists:  []string{fmt.Sprintf("Artist %d", i)},
+			Duration: 210 * time.Second,
+		}
+	}
+}
+
+func (d *fakeDestination) Name() string { return "fake" }
+
+func (d *fakeDestination) SearchTracks(ctx context.Context, query string, limit int) ([]destination.Track, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if track, ok := d.catalogue[query]; ok {
+		return []destination.Track{track}, nil
+	}
+	return nil, nil
+}
+
+func (d *fakeDestination) CreatePlaylist(ctx context.Context, params destination.PlaylistParams) (*destination.Playlist, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	id := fmt.Sprintf("fake-playlist-%d", len(d.playlists)+1)
+	d.playlists[id] = []string{}
+	return &destination.Playlist{ID: id, Name: params.Name}, nil
+}
+
+func (d *fakeDestination) AddTracks(ctx context.Context, playlistID string, trackIDs []string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.addCalls++
+	if d.failAdds != nil {
+		return d.failAdds
+	}
+	d.playlists[playlistID] = append(d.playlists[playlistID], trackIDs...)
+	return nil
+}
+
+func (d *fakeDestination) PlaylistTrackIDs(ctx context.Context, playlistID string) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.playlists[playlistID]...), nil
+}
+
+func (d *fakeDestination) ParseTrackID(ref string) (string, error) {
+	id := strings.TrimPrefix(ref, "fake:track:")
+	if id == "" || strings.ContainsAny(id, ":/") {
+		return "", fmt.Errorf("%w: %q", destination.ErrInvalidTrackID, ref)
+	}
+	return id, nil
+}
+
+// fakeStatusError is a destination error with an HTTP status
+type fakeStatusError int
+
+func (e fakeStatusError) Error() string   { return fmt.Sprintf("status %d", int(e)) }
+func (e fakeStatusError) HTTPStatus() int { return int(e) }
+
+// newTestMigrationService creates a MigrationService reading from and copying
+// to the fake YouTube server, with dest registered as the "fake" target
+func newTestMigrationService(yt *youtubetest.Server, dest *fakeDestination) (*MigrationService, *countingRepository) {
+	clients := NewClientFactory(youtube.WithBaseURL(yt.URL), youtube.WithoutRetries())
+	destinations := map[string]destination.Factory{
+		"fake": func(string) destination.Destination { return dest },
+	}
+	repo := &countingRepository{MigrationRepository: NewMemoryMigrationRepository()}
+	return NewMigrationService(NewPlaylistService(clients), clients, destinations, repo, nil, 0), repo
+}
+
+// interrupt rewrites a stored migration as if the server had stopped right
+// after its last checkpoint: the tracks added since then are pending again
+func interrupt(t *testing.T, repo MigrationRepository, id string, lost int) {
+	t.Helper()
+
+	migration, err := repo.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := len(migration.Tracks) - 1; i >= 0 && lost > 0; i-- {
+		if track := &migration.Tracks[i]; track.Status == models.TrackStatusInserted {
+			track.Status = models.TrackStatusPending
+			if migration.Target == "youtube" {
+				track.DestinationID = ""
+			}
+			lost--
+		}
+	}
+	migration.Status = models.MigrationStatusFailed
+	if err := repo.Save(migration); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// seedCopySource adds playlist SRC to the fake server: five videos of which
+// the second is private and the fourth was deleted
+func seedCopySource(yt *youtubetest.Server) {
+	playlist := youtubetest.NewPlaylist("SRC", "Road trip", "UC-source")
+	playlist.Snippet.Description = "Songs for the road"
+	playlist.Status.PrivacyStatus = youtube.PrivacyUnlisted
+	yt.AddPlaylist(playlist)
+
+	for i := 0; i < 5; i++ {
+		id, title := fmt.Sprintf("video-%d", i), fmt.Sprintf("Artist %d - Song %d", i, i)
+		switch i {
+		case 1:
+			// videos.list does not return private or deleted videos
+			title = "Private video"
+		case 3:
+			title = "Deleted video"
+		default:
+			yt.AddVideos(youtubetest.NewVideo(id, title, "Channel", "PT3M"))
+		}
+		yt.AddPlaylistItems("SRC", youtubetest.NewPlaylistItem(id, title, "Channel"))
+	}
+}
+
+func TestCopyPlaylist(t *testing.T) {
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	seedCopySource(yt)
+	clients := NewClientFactory(youtube.WithBaseURL(yt.URL), youtube.WithoutRetries())
+	service := NewMigrationService(NewPlaylistService(clients), clients, nil, nil, nil, 0)
+
+	migration, err := service.CopyPlaylist(context.Background(), "source-token", "owner", &models.MigrationRequest{
+		SourcePlaylistID:       "SRC",
+		DestinationAccessToken: "destination-token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if migration.Status != models.MigrationStatusCompleted {
+		t.Errorf("status = %q, want completed (%s)", migration.Status, migration.Error)
+	}
+	if migration.Total != 5 || migration.Processed != 5 || migration.Inserted != 3 || migration.Skipped != 2 || migration.Failed != 0 {
+		t.Errorf("counts = %d total, %d processed, %d inserted, %d skipped, %d failed, want 5, 5, 3, 2, 0",
+			migration.Total, migration.Processed, migration.Inserted, migration.Skipped, migration.Failed)
+	}
+
+	wantTracks := []struct {
+		status string
+		reason string
+	}{
+		{status: models.TrackStatusInserted},
+		{status: models.TrackStatusSkipped, reason: "Video is private"},
+		{status: models.TrackStatusInserted},
+		{status: models.TrackStatusSkipped, reason: "Video was deleted"},
+		{status: models.TrackStatusInserted},
+	}
+	for i, want := range wantTracks {
+		track := migration.Tracks[i]
+		if track.Position != i || track.SourceID != fmt.Sprintf("video-%d", i) {
+			t.Errorf("track %d = %+v", i, track)
+		}
+		if track.Status != want.status || track.Reason != want.reason {
+			t.Errorf("track %d is %s (%q), want %s (%q)", i, track.Status, track.Reason, want.status, want.reason)
+		}
+		if (track.Status == models.TrackStatusInserted) != (track.DestinationID != "") {
+			t.Errorf("track %d has destination ID %q", i, track.DestinationID)
+		}
+	}
+
+	copied, _, ok := yt.Playlist(migration.DestinationPlaylistID)
+	if !ok {
+		t.Fatalf("destination playlist %q was not created", migration.DestinationPlaylistID)
+	}
+	if copied.Snippet.Title != "Road trip" || copied.Snippet.Description != "Songs for the road" || copied.Status.PrivacyStatus != youtube.PrivacyUnlisted {
+		t.Errorf("copied playlist = %q/%q/%q, want the source title, description and privacy",
+			copied.Snippet.Title, copied.Snippet.Description, copied.Status.PrivacyStatus)
+	}
+	if got := videoOrder(t, yt, migration.DestinationPlaylistID); fmt.Sprint(got) != "[video-0 video-2 video-4]" {
+		t.Errorf("copied videos = %v, want [video-0 video-2 video-4]", got)
+	}
+
+	stored, err := service.GetMigration("owner", migration.ID)
+	if err != nil || stored.Status != migration.Status || stored.Inserted != 3 {
+		t.Errorf("GetMigration = %+v, %v", stored, err)
+	}
+}
+
+func TestCopyPlaylistOverrides(t *testing.T) {
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	seedCopySource(yt)
+	clients := NewClientFactory(youtube.WithBaseURL(yt.URL), youtube.WithoutRetries())
+	service := NewMigrationService(NewPlaylistService(clients), clients, nil, nil, nil, 0)
+	description := ""
+
+	migration, err := service.CopyPlaylist(context.Background(), "source-token", "owner", &models.MigrationRequest{
+		SourcePlaylistID:       "SRC",
+		DestinationAccessToken: "destination-token",
+		Title:                  "Road trip (copy)",
+		Description:            &description,
+		PrivacyStatus:          youtube.PrivacyPrivate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	copied, _, _ := yt.Playlist(migration.DestinationPlaylistID)
+	if copied.Snippet.Title != "Road trip (copy)" || copied.Snippet.Description != "" || copied.Status.PrivacyStatus != youtube.PrivacyPrivate {
+		t.Errorf("copied playlist = %q/%q/%q, want the requested values",
+			copied.Snippet.Title, copied.Snippet.Description, copied.Status.PrivacyStatus)
+	}
+	if migration.Title != "Road trip (copy)" {
+		t.Errorf("migration title = %q", migration.Title)
+	}
+}
+
+func TestResumeYouTubeMigrationAddsNoDuplicates(t *testing.T) {
+	ctx := context.Background()
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	yt.SeedPlaylist("PL1", "Source", 10)
+	// A video that appears twice in the source must still be copied twice
+	yt.AddPlaylistItems("PL1", youtubetest.NewPlaylistItem("PL1-video-2", "Artist 2 - Song 2", "Channel UC-test"))
+	service, repo := newTestMigrationService(yt, newFakeDestination())
+
+	// Reading the source (3 units) and creating the playlist (50) leave room
+	// for 6 inserts before the quota runs out
+	yt.SetQuotaLimit(3 + youtubetest.WriteCost*7)
+	migration, err := service.CopyPlaylist(ctx, "source", "user-1", &models.MigrationRequest{
+		SourcePlaylistID:       "PL1",
+		DestinationAccessToken: "destination",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migration.Status != models.MigrationStatusFailed || migration.Inserted != 6 {
+		t.Fatalf("migration %s with %d inserted, want failed with 6", migration.Status, migration.Inserted)
+	}
+
+	// The last 4 inserts never reached a checkpoint
+	interrupt(t, repo, migration.ID, 4)
+	yt.SetQuotaLimit(0)
+
+	for i := 0; i < 2; i++ {
+		migration, err = service.ResumeMigration(ctx, "source", "user-1", migration.ID, &models.ResumeMigrationRequest{DestinationAccessToken: "destination"})
+		if err != nil {
+			t.Fatalf("resume %d: %v", i+1, err)
+		}
+		if migration.Status != models.MigrationStatusCompleted || migration.Inserted != 11 {
+			t.Fatalf("resume %d: migration %s with %d inserted, want completed with 11", i+1, migration.Status, migration.Inserted)
+		}
+	}
+
+	want := make([]string, 0, 11)
+	for i := 0; i < 10; i++ {
+		want = append(want, fmt.Sprintf("PL1-video-%d", i))
+	}
+	want = append(want, "PL1-video-2")
+	if got := videoOrder(t, yt, migration.DestinationPlaylistID); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("destination playlist = %v, want %v", got, want)
+	}
+	for _, track := range migration.Tracks {
+		if track.DestinationID == "" {
+			t.Errorf("track %d has no destination item", track.Position)
+		}
+	}
+}
+
+func TestResumeDestinationMigrationAddsNoDuplicates(t *testing.T) {
+	ctx := context.Background()
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	yt.SeedPlaylist("PL1", "Source", 10)
+	dest := newFakeDestination()
+	dest.addSeededTracks("PL1", 10)
+	service, repo := newTestMigrationService(yt, dest)
+
+	migration, err := service.MigrateToDestination(ctx, "source", "user-1", "PL1", "fake", &models.DestinationMigrationRequest{DestinationAccessToken: "destination"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migration.Status != models.MigrationStatusCompleted || migration.Inserted != 10 {
+		t.Fatalf("migration %s with %d inserted, want completed with 10", migration.Status, migration.Inserted)
+	}
+
+	// The batch was added, but the server stopped before saving it
+	interrupt(t, repo, migration.ID, 10)
+	for i := 0; i < 2; i++ {
+		migration, err = service.ResumeMigration(ctx, "source", "user-1", migration.ID, &models.ResumeMigrationRequest{DestinationAccessToken: "destination"})
+		if err != nil {
+			t.Fatalf("resume %d: %v", i+1, err)
+		}
+		if migration.Status != models.MigrationStatusCompleted || migration.Inserted != 10 {
+			t.Fatalf("resume %d: migration %s with %d inserted, want completed with 10", i+1, migration.Status, migration.Inserted)
+		}
+	}
+
+	trackIDs, _ := dest.PlaylistTrackIDs(ctx, migration.DestinationPlaylistID)
+	if len(trackIDs) != 10 {
+		t.Errorf("destination playlist has %d tracks, want 10: %v", len(trackIDs), trackIDs)
+	}
+	if dest.addCalls != 1 {
+		t.Errorf("AddTracks called %d times, want 1", dest.addCalls)
+	}
+}
+
+func TestResumeAfterFailedBatch(t *testing.T) {
+	ctx := context.Background()
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	yt.SeedPlaylist("PL1", "Source", 5)
+	dest := newFakeDestination()
+	dest.addSeededTracks("PL1", 5)
+	dest.failAdds = fakeStatusError(403)
+	service, _ := newTestMigrationService(yt, dest)
+
+	migration, err := service.MigrateToDestination(ctx, "source", "user-1", "PL1", "fake", &models.DestinationMigrationRequest{DestinationAccessToken: "destination"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migration.Status != models.MigrationStatusFailed || migration.Failed != 5 {
+		t.Fatalf("migration %s with %d failed, want failed with 5", migration.Status, migration.Failed)
+	}
+
+	// Matches are kept, so resuming adds them without searching again
+	dest.failAdds = nil
+	migration, err = service.ResumeMigration(ctx, "source", "user-1", migration.ID, &models.ResumeMigrationRequest{DestinationAccessToken: "destination"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migration.Status != models.MigrationStatusCompleted || migration.Inserted != 5 {
+		t.Errorf("migration %s with %d inserted, want completed with 5", migration.Status, migration.Inserted)
+	}
+	if trackIDs, _ := dest.PlaylistTrackIDs(ctx, migration.DestinationPlaylistID); len(trackIDs) != 5 {
+		t.Errorf("destination playlist has %d tracks, want 5", len(trackIDs))
+	}
+}
+
+func TestMigrationSavesOncePerBatch(t *testing.T) {
+	const total = 250
+
+	tests := []struct {
+		name    string
+		migrate func(service *MigrationService) (*models.MigrationResponse, error)
+	}{
+		{
+			name: "youtube",
+			migrate: func(service *MigrationService) (*models.MigrationResponse, error) {
+				return service.CopyPlaylist(context.Background(), "source", "user-1", &models.MigrationRequest{
+					SourcePlaylistID:       "PL1",
+					DestinationAccessToken: "destination",
+				})
+			},
+		},
+		{
+			name: "destination",
+			migrate: func(service *MigrationService) (*models.MigrationResponse, error) {
+				return service.MigrateToDestination(context.Background(), "source", "user-1", "PL1", "fake",
+					&models.DestinationMigrationRequest{DestinationAccessToken: "destination"})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yt := youtubetest.NewServer()
+			defer yt.Close()
+			yt.SeedPlaylist("PL1", "Source", total)
+			dest := newFakeDestination()
+			dest.addSeededTracks("PL1", total)
+			service, repo := newTestMigrationService(yt, dest)
+
+			migration, err := tt.migrate(service)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if migration.Status != models.MigrationStatusCompleted || migration.Inserted != total {
+				t.Fatalf("migration %s with %d inserted, want completed with %d", migration.Status, migration.Inserted, total)
+			}
+
+			// Created, playlist created, 2 checkpoints, up to 3 batches and finished
+			if saves := repo.count(); saves > 8 {
+				t.Errorf("migration of %d tracks saved %d times, want at most 8", total, saves)
+			}
+			stored, err := service.GetMigration("user-1", migration.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Status != models.MigrationStatusCompleted || stored.Inserted != total {
+				t.Errorf("stored migration %s with %d inserted", stored.Status, stored.Inserted)
+			}
+		})
+	}
+}
+
+// blockingDestination is a fakeDestination whose AddTracks waits for release
+type blockingDestination struct {
+	*fakeDestination
+	adding  chan struct{} // Receives a value when AddTracks is called
+	release chan struct{}
+}
+
+func (d *blockingDestination) AddTracks(ctx context.Context, playlistID string, trackIDs []string) error {
+	d.adding <- struct{}{}
+	<-d.release
+	return d.fakeDestination.AddTracks(ctx, playlistID, trackIDs)
+}
+
+// saveReviewMigration stores a migration of owner to the fake target with
+// two tracks awaiting review. Each track has two candidates, ID-track-N and
+// ID-track-N-alt.
+func saveReviewMigration(t *testing.T, repo MigrationRepository, id, owner string) {
+	t.Helper()
+
+	migration := &models.MigrationResponse{
+		ID:                    id,
+		Target:                "fake",
+		UserID:                owner,
+		Status:                models.MigrationStatusReview,
+		SourcePlaylistID:      "PL1",
+		DestinationPlaylistID: "fake-playlist-" + id,
+		Total:                 2,
+		StartedAt:             time.Now(),
+	}
+	for i := 0; i < 2; i++ {
+		migration.Tracks = append(migration.Tracks, models.MigrationTrackResponse{
+			Position: i,
+			SourceID: fmt.Sprintf("video-%d", i),
+			Status:   models.TrackStatusReview,
+			Candidates: []models.MatchCandidateResponse{
+				{ID: fmt.Sprintf("%s-track-%d", id, i), Confidence: 0.7},
+				{ID: fmt.Sprintf("%s-track-%d-alt", id, i), Confidence: 0.6},
+			},
+		})
+	}
+	updateProgress(migration)
+	if err := repo.Save(migration); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrationsAreOnlyVisibleToTheirOwner(t *testing.T) {
+	ctx := context.Background()
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	service, repo := newTestMigrationService(yt, newFakeDestination())
+	saveReviewMigration(t, repo, "m1", "user-1")
+
+	accept := &models.ReviewDecisionRequest{Action: models.ReviewActionAccept}
+	complete := &models.CompleteMigrationRequest{DestinationAccessToken: "destination", Force: true}
+	calls := map[string]func(owner string) error{
+		"get": func(owner string) error {
+			_, err := service.GetMigration(owner, "m1")
+			return err
+		},
+		"review queue": func(owner string) error {
+			_, err := service.GetReviewQueue(owner, "m1")
+			return err
+		},
+		"review": func(owner string) error {
+			_, err := service.ReviewTrack(owner, "m1", 0, accept)
+			return err
+		},
+		"resume": func(owner string) error {
+			_, err := service.ResumeMigration(ctx, "source", owner, "m1", &models.ResumeMigrationRequest{DestinationAccessToken: "destination"})
+			return err
+		},
+		"complete": func(owner string) error {
+			_, err := service.CompleteMigration(ctx, owner, "m1", complete)
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			expectNotFound(t, "someone else", call("user-2"))
+			expectNotFound(t, "no owner", call(""))
+		})
+	}
+
+	// The owner still gets to review and complete it
+	for _, name := range []string{"get", "review queue", "review", "complete"} {
+		if err := calls[name]("user-1"); err != nil {
+			t.Errorf("owner %s: %v", name, err)
+		}
+	}
+	if migration, _ := service.GetMigration("user-1", "m1"); migration.Status != models.MigrationStatusCompleted {
+		t.Errorf("migration %s, want completed", migration.Status)
+	}
+}
+
+func TestCompleteMigrationDoesNotBlockOtherReviews(t *testing.T) {
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	dest := &blockingDestination{
+		fakeDestination: newFakeDestination(),
+		adding:          make(chan struct{}),
+		release:         make(chan struct{}),
+	}
+	clients := NewClientFactory(youtube.WithBaseURL(yt.URL), youtube.WithoutRetries())
+	destinations := map[string]destination.Factory{
+		"fake": func(string) destination.Destination { return dest },
+	}
+	repo := NewMemoryMigrationRepository()
+	service := NewMigrationService(NewPlaylistService(clients), clients, destinations, repo, nil, 0)
+	saveReviewMigration(t, repo, "m1", "user-1")
+	saveReviewMigration(t, repo, "m2", "user-2")
+	if _, err := service.ReviewTrack("user-1", "m1", 0, &models.ReviewDecisionRequest{Action: models.ReviewActionAccept}); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := service.CompleteMigration(context.Background(), "user-1", "m1",
+			&models.CompleteMigrationRequest{DestinationAccessToken: "destination", Force: true})
+		done <- err
+	}()
+	<-dest.adding
+
+	// While m1 waits on the destination, m2 can still be reviewed, and m1
+	// can no longer be changed
+	reviewed := make(chan error, 1)
+	go func() {
+		_, err := service.ReviewTrack("user-2", "m2", 0, &models.ReviewDecisionRequest{Action: models.ReviewActionSkip})
+		reviewed <- err
+	}()
+	select {
+	case err := <-reviewed:
+		if err != nil {
+			t.Errorf("review of another migration: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("review of another migration waited for the destination")
+	}
+
+	_, err := service.ReviewTrack("user-1", "m1", 1, &models.ReviewDecisionRequest{Action: models.ReviewActionAccept})
+	if apiErr, ok := err.(*models.APIError); !ok || apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("review of the completing migration: error = %v, want 409", err)
+	}
+	_, err = service.CompleteMigration(context.Background(), "user-1", "m1",
+		&models.CompleteMigrationRequest{DestinationAccessToken: "destination", Force: true})
+	if apiErr, ok := err.(*models.APIError); !ok || apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("second completion: error = %v, want 409", err)
+	}
+
+	close(dest.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if migration, _ := service.GetMigration("user-1", "m1"); migration.Status != models.MigrationStatusCompleted {
+		t.Errorf("migration %s, want completed", migration.Status)
+	}
+}
+
+func TestReviewTrack(t *testing.T) {
+	tests := []struct {
+		name         string
+		request      models.ReviewDecisionRequest
+		noCandidates bool
+		wantErr      int
+		wantStatus   string
+		wantID       string
+		wantReason   string
+		wantSkipped  int
+	}{
+		{
+			name:       "accept the best candidate",
+			request:    models.ReviewDecisionRequest{Action: models.ReviewActionAccept},
+			wantStatus: models.TrackStatusApproved,
+			wantID:     "m1-track-0",
+		},
+		{
+			name:         "accept without candidates",
+			request:      models.ReviewDecisionRequest{Action: models.ReviewActionAccept},
+			noCandidates: true,
+			wantErr:      http.StatusConflict,
+		},
+		{
+			name:       "select another candidate",
+			request:    models.ReviewDecisionRequest{Action: models.ReviewActionSelect, DestinationID: "m1-track-0-alt"},
+			wantStatus: models.TrackStatusApproved,
+			wantID:     "m1-track-0-alt",
+		},
+		{
+			name:    "select a track that is not a candidate",
+			request: models.ReviewDecisionRequest{Action: models.ReviewActionSelect, DestinationID: "m1-track-1"},
+			wantErr: http.StatusBadRequest,
+		},
+		{
+			name:       "manual ID",
+			request:    models.ReviewDecisionRequest{Action: models.ReviewActionManual, DestinationID: "chosen"},
+			wantStatus: models.TrackStatusApproved,
+			wantID:     "chosen",
+			wantReason: "Matched manually",
+		},
+		{
+			name:       "manual URI is normalised",
+			request:    models.ReviewDecisionRequest{Action: models.ReviewActionManual, DestinationID: "fake:track:chosen"},
+			wantStatus: models.TrackStatusApproved,
+			wantID:     "chosen",
+			wantReason: "Matched manually",
+		},
+		{
+			name:    "manual URI of another service",
+			request: models.ReviewDecisionRequest{Action: models.ReviewActionManual, DestinationID: "spotify:track:chosen"},
+			wantErr: http.StatusBadRequest,
+		},
+		{
+			name:    "manual without an ID",
+			request: models.ReviewDecisionRequest{Action: models.ReviewActionManual},
+			wantErr: http.StatusBadRequest,
+		},
+		{
+			name:        "skip",
+			request:     models.ReviewDecisionRequest{Action: models.ReviewActionSkip},
+			wantStatus:  models.TrackStatusSkipped,
+			wantReason:  "Skipped in review",
+			wantSkipped: 1,
+		},
+		{
+			name:    "unknown action",
+			request: models.ReviewDecisionRequest{Action: "maybe"},
+			wantErr: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yt := youtubetest.NewServer()
+			defer yt.Close()
+			service, repo := newTestMigrationService(yt, newFakeDestination())
+			saveReviewMigration(t, repo, "m1", "user-1")
+			if tt.noCandidates {
+				migration, _ := repo.Get("m1")
+				migration.Tracks[0].Candidates = nil
+				repo.Save(migration)
+			}
+
+			track, err := service.ReviewTrack("user-1", "m1", 0, &tt.request)
+			stored, _ := service.GetMigration("user-1", "m1")
+			if tt.wantErr != 0 {
+				expectAPIError(t, err, tt.wantErr)
+				if stored.Tracks[0].Status != models.TrackStatusReview || stored.PendingReview != 2 {
+					t.Errorf("track %s with %d pending, want it still in review", stored.Tracks[0].Status, stored.PendingReview)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if track.Status != tt.wantStatus || track.DestinationID != tt.wantID || track.Reason != tt.wantReason {
+				t.Errorf("track = %s %q (%q), want %s %q (%q)", track.Status, track.DestinationID, track.Reason, tt.wantStatus, tt.wantID, tt.wantReason)
+			}
+			if stored.Tracks[0].Status != tt.wantStatus || stored.PendingReview != 1 || stored.Skipped != tt.wantSkipped {
+				t.Errorf("stored track %s with %d pending and %d skipped", stored.Tracks[0].Status, stored.PendingReview, stored.Skipped)
+			}
+
+			// A track can only be reviewed once
+			_, err = service.ReviewTrack("user-1", "m1", 0, &tt.request)
+			expectAPIError(t, err, http.StatusConflict)
+		})
+	}
+}
+
+func TestCompleteMigrationWithPendingReviews(t *testing.T) {
+	ctx := context.Background()
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+	dest := newFakeDestination()
+	service, repo := newTestMigrationService(yt, dest)
+	saveReviewMigration(t, repo, "m1", "user-1")
+
+	if _, err := service.ReviewTrack("user-1", "m1", 0, &models.ReviewDecisionRequest{Action: models.ReviewActionAccept}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := service.CompleteMigration(ctx, "user-1", "m1", &models.CompleteMigrationRequest{DestinationAccessToken: "destination"})
+	expectAPIError(t, err, http.StatusConflict)
+	if stored, _ := service.GetMigration("user-1", "m1"); stored.Status != models.MigrationStatusReview || stored.PendingReview != 1 {
+		t.Errorf("migration %s with %d pending, want it still in review", stored.Status, stored.PendingReview)
+	}
+
+	// Forcing skips the track that was not reviewed
+	migration, err := service.CompleteMigration(ctx, "user-1", "m1", &models.CompleteMigrationRequest{DestinationAccessToken: "destination", Force: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migration.Status != models.MigrationStatusCompleted || migration.Inserted != 1 || migration.Skipped != 1 || migration.PendingReview != 0 {
+		t.Errorf("migration %s with %d inserted, %d skipped and %d pending, want completed with 1, 1 and 0",
+			migration.Status, migration.Inserted, migration.Skipped, migration.PendingReview)
+	}
+	if track := migration.Tracks[1]; track.Status != models.TrackStatusSkipped || track.Reason != "Not reviewed before completion" {
+		t.Errorf("unreviewed track %s (%q)", track.Status, track.Reason)
+	}
+	if got := dest.playlists["fake-playlist-m1"]; fmt.Sprint(got) != "[m1-track-0]" {
+		t.Errorf("destination playlist = %v, want [m1-track-0]", got)
+	}
+}
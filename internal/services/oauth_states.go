@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
+	"golang.org/x/oauth2"
+)
+
+// DefaultOAuthStateTTL is how long a user has to finish an authorization
+const DefaultOAuthStateTTL = 10 * time.Minute
+
+// OAuthStates issues the state parameter and PKCE verifier of each
+// authorization. A state is random, bound to a server-side record and can be
+// used once, so a callback cannot be forged or replayed. It is also bound to
+// a nonce kept in a cookie of the browser that started the sign-in, so a
+// valid callback link cannot be sent to someone else (login CSRF).
+type OAuthStates struct {
+	store store.Store
+	ttl   time.Duration
+}
+
+// NewOAuthStates creates a new OAuthStates. States expire after ttl.
+func NewOAuthStates(st store.Store, ttl time.Duration) *OAuthStates {
+	if ttl <= 0 {
+		ttl = DefaultOAuthStateTTL
+	}
+	return &OAuthStates{
+		store: st,
+		ttl:   ttl,
+	}
+}
+
+// Begin records a new authorization for provider. It returns the state, the
+// browser nonce to set as a cookie and the PKCE challenge option to add to
+// the auth URL.
+func (s *OAuthStates) Begin(ctx context.Context, provider string) (string, string, oauth2.AuthCodeOption, error) {
+	state, err := randomSecret()
+	if err != nil {
+		return "", "", nil, models.NewInternalServerError("Unable to create OAuth state", err)
+	}
+	nonce, err := randomSecret()
+	if err != nil {
+		return "", "", nil, models.NewInternalServerError("Unable to create OAuth state", err)
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	now := time.Now()
+	record := &store.OAuthState{
+		ID:           secretHash(state),
+		Provider:     provider,
+		CodeVerifier: verifier,
+		BrowserHash:  secretHash(nonce),
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(s.ttl),
+	}
+	if err := s.store.SaveOAuthState(ctx, record); err != nil {
+		return "", "", nil, models.NewInternalServerError("Unable to create OAuth state", err)
+	}
+
+	return state, nonce, oauth2.S256ChallengeOption(verifier), nil
+}
+
+// Complete consumes the state returned to the callback. nonce is the cookie
+// of the browser completing the sign-in. It returns the PKCE verifier option
+// to add to the code exchange, or a 400 when the state is missing, unknown,
+// already used, expired, was issued for another provider or to another browser.
+func (s *OAuthStates) Complete(ctx context.Context, provider, state, nonce string) (oauth2.AuthCodeOption, error) {
+	if state == "" {
+		return nil, models.NewBadRequestError("State is required", nil)
+	}
+
+	record, err := s.store.ConsumeOAuthState(ctx, secretHash(state))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, models.NewBadRequestError("Invalid or already used state, start the sign-in again", nil)
+	}
+	if err != nil {
+		return nil, models.NewInternalServerError("Unable to load OAuth state", err)
+	}
+
+	if record.Provider != provider {
+		return nil, models.NewBadRequestError("State was issued for another provider", nil)
+	}
+	if time.Now().After(record.ExpiresAt) {
+		return nil, models.NewBadRequestError("State expired, start the sign-in again", nil)
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(secretHash(nonce)), []byte(record.BrowserHash)) != 1 {
+		return nil, models.NewBadRequestError("Sign-in was started in another browser, start it again", nil)
+	}
+
+	return oauth2.VerifierOption(record.CodeVerifier), nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
+)
+
+func TestOAuthStatesComplete(t *testing.T) {
+	stores := map[string]func(t *testing.T) store.Store{
+		"memory": func(t *testing.T) store.Store { return store.NewMemoryStore() },
+		"sqlite": func(t *testing.T) store.Store {
+			st, err := store.OpenSQLite("file:" + filepath.Join(t.TempDir(), "test.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { st.Close() })
+			return st
+		},
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		nonce    func(issued string) string
+		ttl      time.Duration
+		wait     time.Duration
+		wantErr  bool
+	}{
+		{name: "same browser", provider: store.ProviderYouTube, nonce: func(n string) string { return n }},
+		{name: "no cookie", provider: store.ProviderYouTube, nonce: func(string) string { return "" }, wantErr: true},
+		{name: "another browser", provider: store.ProviderYouTube, nonce: func(string) string { return "attacker" }, wantErr: true},
+		{name: "another provider", provider: store.ProviderSpotify, nonce: func(n string) string { return n }, wantErr: true},
+		{name: "expired", provider: store.ProviderYouTube, nonce: func(n string) string { return n }, ttl: time.Millisecond, wait: 5 * time.Millisecond, wantErr: true},
+	}
+
+	for storeName, open := range stores {
+		for _, tt := range tests {
+			t.Run(storeName+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				states := NewOAuthStates(open(t), tt.ttl)
+
+				state, nonce, challenge, err := states.Begin(ctx, store.ProviderYouTube)
+				if err != nil {
+					t.Fatalf("Begin: %v", err)
+				}
+				if state == "" || nonce == "" || challenge == nil {
+					t.Fatalf("Begin returned empty values")
+				}
+				time.Sleep(tt.wait)
+
+				verifier, err := states.Complete(ctx, tt.provider, state, tt.nonce(nonce))
+				if tt.wantErr {
+					apiErr, ok := err.(*models.APIError)
+					if !ok || apiErr.StatusCode != http.StatusBadRequest {
+						t.Fatalf("Complete error = %v, want a 400", err)
+					}
+					return
+				}
+				if err != nil || verifier == nil {
+					t.Fatalf("Complete = %v, %v", verifier, err)
+				}
+
+				// A state can only be used once
+				if _, err := states.Complete(ctx, tt.provider, state, nonce); err == nil {
+					t.Fatal("replayed state was accepted")
+				}
+			})
+		}
+	}
+}
@@ -1,23 +1,49 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
 	"time"
 
+	"github.com/alejpaa/playlist-migration-tool/internal/jobs"
+	"github.com/alejpaa/playlist-migration-tool/internal/matching"
 	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
 	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
 )
 
+// ClientFactory builds a YouTube client for the given access token. ctx is
+// the context of the request or job the client is built for.
+type ClientFactory func(ctx context.Context, accessToken string) *youtube.Client
+
+// NewClientFactory returns a ClientFactory that applies the given options to every client
+func NewClientFactory(opts ...youtube.Option) ClientFactory {
+	return func(ctx context.Context, accessToken string) *youtube.Client {
+		return youtube.NewClient(accessToken, opts...)
+	}
+}
+
 // PlaylistService handles playlist-related business logic
-type PlaylistService struct{}
+type PlaylistService struct {
+	newClient ClientFactory
+}
 
-// NewPlaylistService creates a new PlaylistService
-func NewPlaylistService() *PlaylistService {
-	return &PlaylistService{}
+// NewPlaylistService creates a new PlaylistService. A nil factory uses the
+// default YouTube client.
+func NewPlaylistService(newClient ClientFactory) *PlaylistService {
+	if newClient == nil {
+		newClient = NewClientFactory()
+	}
+	return &PlaylistService{
+		newClient: newClient,
+	}
 }
 
 // GetPlaylists retrieves user's playlists
-func (s *PlaylistService) GetPlaylists(accessToken string, maxResults int, pageToken string) (*models.PlaylistsResponse, error) {
-	client := youtube.NewClient(accessToken)
+func (s *PlaylistService) GetPlaylists(ctx context.Context, accessToken string, maxResults int, pageToken string) (*models.PlaylistsResponse, error) {
+	client := s.newClient(ctx, accessToken)
 
 	options := &youtube.ListPlaylistsOptions{
 		Part:       "snippet,status,contentDetails",
@@ -26,35 +52,15 @@ func (s *PlaylistService) GetPlaylists(accessToken string, maxResults int, pageT
 		PageToken:  pageToken,
 	}
 
-	response, err := client.ListPlaylists(options)
+	response, err := client.ListPlaylists(ctx, options)
 	if err != nil {
-		return nil, models.NewInternalServerError("Failed to fetch playlists", err)
+		return nil, youtubeError(err, "Failed to fetch playlists")
 	}
 
 	// Convert YouTube API response to our internal model
 	playlists := make([]models.PlaylistResponse, len(response.Items))
 	for i, item := range response.Items {
-		createdAt, _ := time.Parse(time.RFC3339, item.Snippet.PublishedAt)
-
-		thumbnailURL := ""
-		if item.Snippet.Thumbnails != nil {
-			if medium, ok := item.Snippet.Thumbnails["medium"]; ok {
-				thumbnailURL = medium.URL
-			} else if def, ok := item.Snippet.Thumbnails["default"]; ok {
-				thumbnailURL = def.URL
-			}
-		}
-
-		playlists[i] = models.PlaylistResponse{
-			ID:            item.ID,
-			Title:         item.Snippet.Title,
-			Description:   item.Snippet.Description,
-			VideoCount:    item.ContentDetails.ItemCount,
-			PrivacyStatus: item.Status.PrivacyStatus,
-			CreatedAt:     createdAt,
-			ChannelTitle:  item.Snippet.ChannelTitle,
-			ThumbnailURL:  thumbnailURL,
-		}
+		playlists[i] = toPlaylistResponse(&item)
 	}
 
 	return &models.PlaylistsResponse{
@@ -65,108 +71,387 @@ func (s *PlaylistService) GetPlaylists(accessToken string, maxResults int, pageT
 	}, nil
 }
 
-// GetPlaylistByID retrieves a specific playlist with its videos
-func (s *PlaylistService) GetPlaylistByID(accessToken, playlistID string) (*models.PlaylistDetailResponse, error) {
-	client := youtube.NewClient(accessToken)
+// GetPlaylistByID retrieves a specific playlist with all of its videos
+func (s *PlaylistService) GetPlaylistByID(ctx context.Context, accessToken, playlistID string) (*models.PlaylistDetailResponse, error) {
+	client := s.newClient(ctx, accessToken)
 
 	// Get playlist info
-	playlist, err := client.GetPlaylistByID(playlistID)
+	playlist, err := client.GetPlaylistByID(ctx, playlistID)
 	if err != nil {
-		return nil, models.NewNotFoundError("Playlist not found", err)
+		return nil, youtubeError(err, "Failed to fetch playlist")
 	}
 
-	// Get playlist items
-	items, err := client.ListPlaylistItems(playlistID, 50)
+	// Get every playlist item, following pagination
+	items, err := client.ListAllPlaylistItems(ctx, playlistID)
 	if err != nil {
-		return nil, models.NewInternalServerError("Failed to fetch playlist items", err)
+		return nil, youtubeError(err, "Failed to fetch playlist items")
 	}
 
-	// Convert playlist to our model
-	createdAt, _ := time.Parse(time.RFC3339, playlist.Snippet.PublishedAt)
+	// Enrich videos with durations, statistics and availability
+	videos := toVideoResponses(items)
+	if err := s.enrichVideos(ctx, client, videos); err != nil {
+		return nil, youtubeError(err, "Failed to fetch video details")
+	}
 
-	thumbnailURL := ""
-	if playlist.Snippet.Thumbnails != nil {
-		if medium, ok := playlist.Snippet.Thumbnails["medium"]; ok {
-			thumbnailURL = medium.URL
-		} else if def, ok := playlist.Snippet.Thumbnails["default"]; ok {
-			thumbnailURL = def.URL
-		}
+	progress := jobs.ProgressFrom(ctx)
+	for _, video := range videos {
+		progress.Emit(jobs.EventTrackFetched, video)
 	}
 
-	// Convert videos to our model
-	videos := make([]models.VideoResponse, len(items.Items))
-	for i, item := range items.Items {
-		addedAt, _ := time.Parse(time.RFC3339, item.Snippet.PublishedAt)
+	return &models.PlaylistDetailResponse{
+		PlaylistResponse: toPlaylistResponse(playlist),
+		Videos:           videos,
+	}, nil
+}
 
-		videoThumbnailURL := ""
-		if item.Snippet.Thumbnails != nil {
-			if medium, ok := item.Snippet.Thumbnails["medium"]; ok {
-				videoThumbnailURL = medium.URL
-			} else if def, ok := item.Snippet.Thumbnails["default"]; ok {
-				videoThumbnailURL = def.URL
-			}
+// GetPlaylistSongs obtiene las canciones de una playlist. Si all es true se
+// recorren todas las páginas; si no, se devuelve solo la página indicada por pageToken.
+func (s *PlaylistService) GetPlaylistSongs(ctx context.Context, accessToken, playlistID string, maxResults int, pageToken string, all bool) (*models.PlaylistSongsResponse, error) {
+	client := s.newClient(ctx, accessToken)
+
+	if all {
+		items, err := client.ListAllPlaylistItems(ctx, playlistID)
+		if err != nil {
+			return nil, youtubeError(err, "Failed to fetch playlist songs")
 		}
 
-		videos[i] = models.VideoResponse{
-			ID:           item.Snippet.ResourceID.VideoID,
-			Title:        item.Snippet.Title,
-			Description:  item.Snippet.Description,
-			ChannelTitle: item.Snippet.ChannelTitle,
-			Position:     item.Snippet.Position,
-			AddedAt:      addedAt,
-			ThumbnailURL: videoThumbnailURL,
+		songs := toVideoResponses(items)
+		if err := s.enrichVideos(ctx, client, songs); err != nil {
+			return nil, youtubeError(err, "Failed to fetch video details")
 		}
+
+		return &models.PlaylistSongsResponse{
+			PlaylistID: playlistID,
+			Songs:      songs,
+			TotalCount: len(songs),
+		}, nil
 	}
 
-	return &models.PlaylistDetailResponse{
-		PlaylistResponse: models.PlaylistResponse{
-			ID:            playlist.ID,
-			Title:         playlist.Snippet.Title,
-			Description:   playlist.Snippet.Description,
-			VideoCount:    playlist.ContentDetails.ItemCount,
-			PrivacyStatus: playlist.Status.PrivacyStatus,
-			CreatedAt:     createdAt,
-			ChannelTitle:  playlist.Snippet.ChannelTitle,
-			ThumbnailURL:  thumbnailURL,
-		},
-		Videos: videos,
+	// Get a single page of playlist items
+	page, err := client.ListPlaylistItems(ctx, &youtube.ListPlaylistItemsOptions{
+		Part:       "snippet",
+		PlaylistID: playlistID,
+		MaxResults: maxResults,
+		PageToken:  pageToken,
+	})
+	if err != nil {
+		return nil, youtubeError(err, "Failed to fetch playlist songs")
+	}
+
+	songs := toVideoResponses(page.Items)
+	if err := s.enrichVideos(ctx, client, songs); err != nil {
+		return nil, youtubeError(err, "Failed to fetch video details")
+	}
+
+	return &models.PlaylistSongsResponse{
+		PlaylistID:    playlistID,
+		Songs:         songs,
+		TotalCount:    page.PageInfo.TotalResults,
+		NextPageToken: page.NextPageToken,
+		PrevPageToken: page.PrevPageToken,
 	}, nil
 }
 
-// GetPlaylistSongs obtiene solo las canciones de una playlist
-func (s *PlaylistService) GetPlaylistSongs(accessToken, playlistID string, maxResults int) ([]models.VideoResponse, error) {
-	client := youtube.NewClient(accessToken)
+// GetPlaylistTracks retrieves every video of a playlist parsed into artist
+// and track, ready to be searched on other services
+func (s *PlaylistService) GetPlaylistTracks(ctx context.Context, accessToken, playlistID string) (*models.PlaylistTracksResponse, error) {
+	playlist, err := s.GetPlaylistByID(ctx, accessToken, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := make([]models.TrackResponse, len(playlist.Videos))
+	for i, video := range playlist.Videos {
+		query := matching.Parse(video.Title, video.ChannelTitle)
+		tracks[i] = models.TrackResponse{
+			VideoID:         video.ID,
+			VideoTitle:      video.Title,
+			ChannelTitle:    video.ChannelTitle,
+			Artist:          query.Artist,
+			Title:           query.Title,
+			Featured:        query.Featured,
+			Query:           query.String(),
+			DurationSeconds: video.DurationSeconds,
+			Position:        video.Position,
+		}
+	}
 
-	// Get playlist items
-	items, err := client.ListPlaylistItems(playlistID, maxResults)
+	return &models.PlaylistTracksResponse{
+		PlaylistID: playlist.ID,
+		Tracks:     tracks,
+		TotalCount: len(tracks),
+	}, nil
+}
+
+// CreatePlaylist creates a playlist on the authenticated user's channel
+func (s *PlaylistService) CreatePlaylist(ctx context.Context, accessToken string, request *models.CreatePlaylistRequest) (*models.PlaylistResponse, error) {
+	if !validPrivacyStatus(request.PrivacyStatus) {
+		return nil, models.NewBadRequestError("Privacy status must be public, unlisted or private", nil)
+	}
+
+	client := s.newClient(ctx, accessToken)
+
+	playlist, err := client.CreatePlaylist(ctx, youtube.PlaylistParams{
+		Title:         request.Title,
+		Description:   request.Description,
+		PrivacyStatus: request.PrivacyStatus,
+	})
 	if err != nil {
-		return nil, models.NewInternalServerError("Failed to fetch playlist songs", err)
+		return nil, youtubeError(err, "Failed to create playlist")
 	}
 
-	// Convert videos to our model
-	videos := make([]models.VideoResponse, len(items.Items))
-	for i, item := range items.Items {
-		addedAt, _ := time.Parse(time.RFC3339, item.Snippet.PublishedAt)
+	response := toPlaylistResponse(playlist)
+	return &response, nil
+}
+
+// UpdatePlaylist applies a partial update to a playlist's title, description or privacy
+func (s *PlaylistService) UpdatePlaylist(ctx context.Context, accessToken, playlistID string, request *models.UpdatePlaylistRequest) (*models.PlaylistResponse, error) {
+	if request.PrivacyStatus != nil && !validPrivacyStatus(*request.PrivacyStatus) {
+		return nil, models.NewBadRequestError("Privacy status must be public, unlisted or private", nil)
+	}
+	if request.Title != nil && *request.Title == "" {
+		return nil, models.NewBadRequestError("Title cannot be empty", nil)
+	}
+
+	client := s.newClient(ctx, accessToken)
 
-		videoThumbnailURL := ""
-		if item.Snippet.Thumbnails != nil {
-			if medium, ok := item.Snippet.Thumbnails["medium"]; ok {
-				videoThumbnailURL = medium.URL
-			} else if def, ok := item.Snippet.Thumbnails["default"]; ok {
-				videoThumbnailURL = def.URL
-			}
+	// playlists.update replaces the whole snippet, so start from the current values
+	current, err := client.GetPlaylistByID(ctx, playlistID)
+	if err != nil {
+		return nil, youtubeError(err, "Failed to fetch playlist")
+	}
+
+	params := youtube.PlaylistParams{
+		Title:           current.Snippet.Title,
+		Description:     current.Snippet.Description,
+		PrivacyStatus:   current.Status.PrivacyStatus,
+		DefaultLanguage: current.Snippet.DefaultLanguage,
+	}
+	if request.Title != nil {
+		params.Title = *request.Title
+	}
+	if request.Description != nil {
+		params.Description = *request.Description
+	}
+	if request.PrivacyStatus != nil {
+		params.PrivacyStatus = *request.PrivacyStatus
+	}
+
+	playlist, err := client.UpdatePlaylist(ctx, playlistID, params)
+	if err != nil {
+		return nil, youtubeError(err, "Failed to update playlist")
+	}
+
+	response := toPlaylistResponse(playlist)
+	return &response, nil
+}
+
+// DeletePlaylist deletes a playlist
+func (s *PlaylistService) DeletePlaylist(ctx context.Context, accessToken, playlistID string) error {
+	client := s.newClient(ctx, accessToken)
+
+	if err := client.DeletePlaylist(ctx, playlistID); err != nil {
+		return youtubeError(err, "Failed to delete playlist")
+	}
+	return nil
+}
+
+// AddPlaylistItem adds a video to a playlist, at the end unless a position is given
+func (s *PlaylistService) AddPlaylistItem(ctx context.Context, accessToken, playlistID string, request *models.AddPlaylistItemRequest) (*models.VideoResponse, error) {
+	if request.Position != nil && *request.Position < 0 {
+		return nil, models.NewBadRequestError("Position must be zero or greater", nil)
+	}
+
+	client := s.newClient(ctx, accessToken)
+
+	item, err := client.InsertPlaylistItem(ctx, playlistID, request.VideoID, request.Position)
+	if err != nil {
+		return nil, youtubeError(err, "Failed to add video to playlist")
+	}
+
+	return &toVideoResponses([]youtube.PlaylistItem{*item})[0], nil
+}
+
+// MovePlaylistItem moves a playlist item to a new position
+func (s *PlaylistService) MovePlaylistItem(ctx context.Context, accessToken, playlistID, itemID string, request *models.MovePlaylistItemRequest) (*models.VideoResponse, error) {
+	if *request.Position < 0 {
+		return nil, models.NewBadRequestError("Position must be zero or greater", nil)
+	}
+
+	client := s.newClient(ctx, accessToken)
+
+	item, err := client.UpdatePlaylistItem(ctx, itemID, playlistID, request.VideoID, *request.Position)
+	if err != nil {
+		return nil, youtubeError(err, "Failed to move playlist item")
+	}
+
+	return &toVideoResponses([]youtube.PlaylistItem{*item})[0], nil
+}
+
+// RemovePlaylistItem removes an item from a playlist
+func (s *PlaylistService) RemovePlaylistItem(ctx context.Context, accessToken, itemID string) error {
+	client := s.newClient(ctx, accessToken)
+
+	if err := client.DeletePlaylistItem(ctx, itemID); err != nil {
+		return youtubeError(err, "Failed to remove playlist item")
+	}
+	return nil
+}
+
+// validPrivacyStatus reports whether status is empty or a known privacy status
+func validPrivacyStatus(status string) bool {
+	switch status {
+	case "", youtube.PrivacyPublic, youtube.PrivacyUnlisted, youtube.PrivacyPrivate:
+		return true
+	default:
+		return false
+	}
+}
+
+// enrichVideos fills durations, view counts, availability, region restrictions
+// and music category using batched videos.list calls
+func (s *PlaylistService) enrichVideos(ctx context.Context, client *youtube.Client, videos []models.VideoResponse) error {
+	ids := make([]string, 0, len(videos))
+	for _, video := range videos {
+		if video.ID != "" {
+			ids = append(ids, video.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	details, err := client.GetVideos(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]*youtube.Video, len(details))
+	for i := range details {
+		byID[details[i].ID] = &details[i]
+	}
+
+	for i := range videos {
+		detail, ok := byID[videos[i].ID]
+		if !ok {
+			// videos.list omits private and deleted videos
+			videos[i].Availability = missingVideoAvailability(videos[i].Title)
+			continue
 		}
 
+		if duration, err := detail.Duration(); err == nil {
+			videos[i].DurationSeconds = int(duration.Seconds())
+			videos[i].Duration = formatDuration(duration)
+		}
+		videos[i].ViewCount = detail.ViewCount()
+		videos[i].IsMusic = detail.IsMusic()
+		videos[i].Availability = videoAvailability(detail)
+		if restriction := detail.ContentDetails.RegionRestriction; restriction != nil {
+			videos[i].AllowedRegions = restriction.Allowed
+			videos[i].BlockedRegions = restriction.Blocked
+		}
+	}
+
+	return nil
+}
+
+// videoAvailability classifies a video returned by videos.list
+func videoAvailability(video *youtube.Video) string {
+	switch {
+	case video.Status.PrivacyStatus == "private":
+		return models.AvailabilityPrivate
+	case video.Status.UploadStatus != "" && video.Status.UploadStatus != "processed" && video.Status.UploadStatus != "uploaded":
+		return models.AvailabilityUnavailable
+	default:
+		return models.AvailabilityAvailable
+	}
+}
+
+// missingVideoAvailability classifies a playlist item whose video was not
+// returned by videos.list, using the placeholder titles YouTube gives them
+func missingVideoAvailability(title string) string {
+	switch title {
+	case "Private video":
+		return models.AvailabilityPrivate
+	case "Deleted video":
+		return models.AvailabilityDeleted
+	default:
+		return models.AvailabilityUnavailable
+	}
+}
+
+// formatDuration renders a duration as m:ss or h:mm:ss
+func formatDuration(d time.Duration) string {
+	total := int(d.Seconds())
+	hours, minutes, seconds := total/3600, (total%3600)/60, total%60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%d:%02d", minutes, seconds)
+}
+
+// toVideoResponses converts YouTube playlist items to our internal model
+func toVideoResponses(items []youtube.PlaylistItem) []models.VideoResponse {
+	videos := make([]models.VideoResponse, len(items))
+	for i, item := range items {
+		addedAt, _ := time.Parse(time.RFC3339, item.Snippet.PublishedAt)
+
 		videos[i] = models.VideoResponse{
 			ID:           item.Snippet.ResourceID.VideoID,
+			ItemID:       item.ID,
 			Title:        item.Snippet.Title,
 			Description:  item.Snippet.Description,
 			ChannelTitle: item.Snippet.ChannelTitle,
 			Position:     item.Snippet.Position,
 			AddedAt:      addedAt,
-			ThumbnailURL: videoThumbnailURL,
+			ThumbnailURL: thumbnailURL(item.Snippet.Thumbnails),
 		}
 	}
+	return videos
+}
+
+// savePlaylistSnapshot keeps a copy of a playlist read for an export or a
+// migration in st, replacing the previous one. A failure is only logged.
+func savePlaylistSnapshot(ctx context.Context, st store.Store, playlist *models.PlaylistDetailResponse) {
+	if st == nil {
+		return
+	}
+
+	data, err := json.Marshal(playlist)
+	if err == nil {
+		err = st.SaveSnapshot(ctx, &store.Snapshot{Kind: store.SnapshotPlaylist, ID: playlist.ID, Data: data})
+	}
+	if err != nil {
+		log.Printf("Failed to save snapshot of playlist %s: %v", playlist.ID, err)
+	}
+}
 
-	return videos, nil
+// toPlaylistResponse converts a YouTube playlist to our internal model
+func toPlaylistResponse(playlist *youtube.Playlist) models.PlaylistResponse {
+	createdAt, _ := time.Parse(time.RFC3339, playlist.Snippet.PublishedAt)
+
+	return models.PlaylistResponse{
+		ID:            playlist.ID,
+		Title:         playlist.Snippet.Title,
+		Description:   playlist.Snippet.Description,
+		VideoCount:    playlist.ContentDetails.ItemCount,
+		PrivacyStatus: playlist.Status.PrivacyStatus,
+		CreatedAt:     createdAt,
+		ChannelTitle:  playlist.Snippet.ChannelTitle,
+		ThumbnailURL:  thumbnailURL(playlist.Snippet.Thumbnails),
+	}
+}
+
+// thumbnailURL picks the medium thumbnail, falling back to the default one
+func thumbnailURL(thumbnails map[string]youtube.Thumbnail) string {
+	if thumbnails == nil {
+		return ""
+	}
+	if medium, ok := thumbnails["medium"]; ok {
+		return medium.URL
+	}
+	if def, ok := thumbnails["default"]; ok {
+		return def.URL
+	}
+	return ""
 }
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
+	"github.com/alejpaa/playlist-migration-tool/pkg/destination"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube/youtubetest"
+)
+
+func TestPlaylistServiceUsesClientFactory(t *testing.T) {
+	var authorization, userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/playlists" || r.URL.Query().Get("mine") != "true" {
+			http.NotFound(w, r)
+			return
+		}
+		authorization = r.Header.Get("Authorization")
+		userAgent = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"nextPageToken": "next",
+			"pageInfo": {"totalResults": 2, "resultsPerPage": 1},
+			"items": [{"id": "PL1", "snippet": {"title": "Playlist"}, "contentDetails": {"itemCount": 3}}]
+		}`))
+	}))
+	defer server.Close()
+
+	service := NewPlaylistService(NewClientFactory(
+		youtube.WithBaseURL(server.URL),
+		youtube.WithUserAgent("playlist-migration-tool/test"),
+	))
+
+	response, err := service.GetPlaylists(context.Background(), "test-token", 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Playlists) != 1 || response.Playlists[0].ID != "PL1" || response.Playlists[0].VideoCount != 3 {
+		t.Errorf("playlists = %+v, want PL1 with 3 videos", response.Playlists)
+	}
+	if response.TotalCount != 2 || response.NextPageToken != "next" {
+		t.Errorf("total_count = %d, next_page_token = %q, want 2 and next", response.TotalCount, response.NextPageToken)
+	}
+	if authorization != "Bearer test-token" {
+		t.Errorf("Authorization = %q, want Bearer test-token", authorization)
+	}
+	if userAgent != "playlist-migration-tool/test" {
+		t.Errorf("User-Agent = %q, want playlist-migration-tool/test", userAgent)
+	}
+}
+
+// newWriteTestService returns a PlaylistService backed by a fake YouTube with
+// playlist PL1 holding three videos and a fourth video that is in no playlist
+func newWriteTestService(t *testing.T) (*PlaylistService, *youtubetest.Server) {
+	t.Helper()
+
+	yt := youtubetest.NewServer()
+	t.Cleanup(yt.Close)
+	playlist := yt.SeedPlaylist("PL1", "Road trip", 3)
+	playlist.Snippet.Description = "Songs for the road"
+	yt.AddPlaylist(playlist)
+	yt.AddVideos(youtubetest.NewVideo("extra", "Artist - Extra", "Channel", "PT3M"))
+
+	return NewPlaylistService(NewClientFactory(youtube.WithBaseURL(yt.URL), youtube.WithoutRetries())), yt
+}
+
+// videoOrder returns the video IDs of a fake playlist in order
+func videoOrder(t *testing.T, yt *youtubetest.Server, playlistID string) []string {
+	t.Helper()
+
+	_, items, ok := yt.Playlist(playlistID)
+	if !ok {
+		t.Fatalf("playlist %s not found", playlistID)
+	}
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.Snippet.ResourceID.VideoID
+	}
+	return ids
+}
+
+// expectAPIError fails the test when err is not an APIError with status want
+func expectAPIError(t *testing.T, err error, want int) {
+	t.Helper()
+
+	var apiErr *models.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != want {
+		t.Fatalf("error = %v, want status %d", err, want)
+	}
+}
+
+func TestUpdatePlaylistMergesPartialUpdate(t *testing.T) {
+	service, yt := newWriteTestService(t)
+	description := "New description"
+
+	response, err := service.UpdatePlaylist(context.Background(), "test-token", "PL1", &models.UpdatePlaylistRequest{Description: &description})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	playlist, _, _ := yt.Playlist("PL1")
+	if playlist.Snippet.Title != "Road trip" || playlist.Snippet.Description != description || playlist.Status.PrivacyStatus != "public" {
+		t.Errorf("saved playlist = %q/%q/%q, want the title and privacy kept", playlist.Snippet.Title, playlist.Snippet.Description, playlist.Status.PrivacyStatus)
+	}
+	if response.Title != "Road trip" || response.Description != description {
+		t.Errorf("response = %+v", response)
+	}
+
+	private := youtube.PrivacyPrivate
+	if _, err := service.UpdatePlaylist(context.Background(), "test-token", "PL1", &models.UpdatePlaylistRequest{PrivacyStatus: &private}); err != nil {
+		t.Fatal(err)
+	}
+	playlist, _, _ = yt.Playlist("PL1")
+	if playlist.Snippet.Description != description || playlist.Status.PrivacyStatus != private {
+		t.Errorf("saved playlist = %q/%q, want the description kept", playlist.Snippet.Description, playlist.Status.PrivacyStatus)
+	}
+}
+
+func TestPlaylistWriteValidation(t *testing.T) {
+	empty, unknown := "", "friends-only"
+	negative, beyond, last := -1, 4, 3
+
+	tests := []struct {
+		name       string
+		call       func(ctx context.Context, service *PlaylistService) error
+		wantStatus int
+		wantCalls  bool
+	}{
+		{
+			name: "create with unknown privacy",
+			call: func(ctx context.Context, service *PlaylistService) error {
+				_, err := service.CreatePlaylist(ctx, "test-token", &models.CreatePlaylistRequest{Title: "New", PrivacyStatus: unknown})
+				return err
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "update with empty title",
+			call: func(ctx context.Context, service *PlaylistService) error {
+				_, err := service.UpdatePlaylist(ctx, "test-token", "PL1", &models.UpdatePlaylistRequest{Title: &empty})
+				return err
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "update with unknown privacy",
+			call: func(ctx context.Context, service *PlaylistService) error {
+				_, err := service.UpdatePlaylist(ctx, "test-token", "PL1", &models.UpdatePlaylistRequest{PrivacyStatus: &unknown})
+				return err
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "update unknown playlist",
+			call: func(ctx context.Context, service *PlaylistService) error {
+				_, err := service.UpdatePlaylist(ctx, "test-token", "missing", &models.UpdatePlaylistRequest{Title: &unknown})
+				return err
+			},
+			wantStatus: http.StatusNotFound,
+			wantCalls:  true,
+		},
+		{
+			name: "add at a negative position",
+			call: func(ctx context.Context, service *PlaylistService) error {
+				_, err := service.AddPlaylistItem(ctx, "test-token", "PL1", &models.AddPlaylistItemRequest{VideoID: "extra", Position: &negative})
+				return err
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "add past the end",
+			call: func(ctx context.Context, service *PlaylistService) error {
+				_, err := service.AddPlaylistItem(ctx, "test-token", "PL1", &models.AddPlaylistItemRequest{VideoID: "extra", Position: &beyond})
+				return err
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCalls:  true,
+		},
+		{
+			name: "move to a negative position",
+			call: func(ctx context.Context, service *PlaylistService) error {
+				_, err := service.MovePlaylistItem(ctx, "test-token", "PL1", "PL1-item-0", &models.MovePlaylistItemRequest{VideoID: "PL1-video-0", Position: &negative})
+				return err
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "move past the end",
+			call: func(ctx context.Context, service *PlaylistService) error {
+				_, err := service.MovePlaylistItem(ctx, "test-token", "PL1", "PL1-item-0", &models.MovePlaylistItemRequest{VideoID: "PL1-video-0", Position: &last})
+				return err
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCalls:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, yt := newWriteTestService(t)
+
+			expectAPIError(t, tt.call(context.Background(), service), tt.wantStatus)
+
+			calls := yt.Requests("playlists") + yt.Requests("playlistItems")
+			if tt.wantCalls != (calls > 0) {
+				t.Errorf("made %d YouTube requests", calls)
+			}
+			if got := videoOrder(t, yt, "PL1"); fmt.Sprint(got) != "[PL1-video-0 PL1-video-1 PL1-video-2]" {
+				t.Errorf("playlist changed to %v", got)
+			}
+		})
+	}
+}
+
+func TestPlaylistItemPositions(t *testing.T) {
+	ctx := context.Background()
+	service, yt := newWriteTestService(t)
+	first, middle, last := 0, 1, 2
+
+	added, err := service.AddPlaylistItem(ctx, "test-token", "PL1", &models.AddPlaylistItemRequest{VideoID: "extra", Position: &first})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.Position != 0 || added.ItemID == "" {
+		t.Errorf("added item = %+v, want position 0 with an item ID", added)
+	}
+
+	if _, err := service.MovePlaylistItem(ctx, "test-token", "PL1", added.ItemID, &models.MovePlaylistItemRequest{VideoID: "extra", Position: &last}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.AddPlaylistItem(ctx, "test-token", "PL1", &models.AddPlaylistItemRequest{VideoID: "PL1-video-2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.RemovePlaylistItem(ctx, "test-token", "PL1-item-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.MovePlaylistItem(ctx, "test-token", "PL1", "PL1-item-2", &models.MovePlaylistItemRequest{VideoID: "PL1-video-2", Position: &middle}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[PL1-video-0 PL1-video-2 extra PL1-video-2]"
+	if got := videoOrder(t, yt, "PL1"); fmt.Sprint(got) != want {
+		t.Errorf("playlist = %v, want %v", got, want)
+	}
+}
+
+func TestSourcePlaylistSnapshot(t *testing.T) {
+	tests := []struct {
+		name string
+		read func(ctx context.Context, playlists *PlaylistService, clients ClientFactory, st store.Store) error
+	}{
+		{
+			name: "export",
+			read: func(ctx context.Context, playlists *PlaylistService, clients ClientFactory, st store.Store) error {
+				_, err := NewExportService(playlists, st).ExportPlaylist(ctx, "test-token", "PL1", &models.ExportRequest{Format: "json"})
+				return err
+			},
+		},
+		{
+			name: "youtube migration",
+			read: func(ctx context.Context, playlists *PlaylistService, clients ClientFactory, st store.Store) error {
+				_, err := NewMigrationService(playlists, clients, nil, nil, st, 0).CopyPlaylist(ctx, "test-token", "user-1",
+					&models.MigrationRequest{SourcePlaylistID: "PL1", DestinationAccessToken: "destination"})
+				return err
+			},
+		},
+		{
+			name: "destination migration",
+			read: func(ctx context.Context, playlists *PlaylistService, clients ClientFactory, st store.Store) error {
+				destinations := map[string]destination.Factory{
+					"fake": func(string) destination.Destination { return newFakeDestination() },
+				}
+				_, err := NewMigrationService(playlists, clients, destinations, nil, st, 0).MigrateToDestination(ctx, "test-token", "user-1", "PL1", "fake",
+					&models.DestinationMigrationRequest{DestinationAccessToken: "destination"})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			yt := youtubetest.NewServer()
+			defer yt.Close()
+			yt.SeedPlaylist("PL1", "Road trip", 3)
+			clients := NewClientFactory(youtube.WithBaseURL(yt.URL), youtube.WithoutRetries())
+			st := store.NewMemoryStore()
+
+			if err := tt.read(ctx, NewPlaylistService(clients), clients, st); err != nil {
+				t.Fatal(err)
+			}
+
+			snapshot, err := st.GetSnapshot(ctx, store.SnapshotPlaylist, "PL1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var playlist models.PlaylistDetailResponse
+			if err := json.Unmarshal(snapshot.Data, &playlist); err != nil {
+				t.Fatal(err)
+			}
+			if playlist.Title != "Road trip" || len(playlist.Videos) != 3 || playlist.Videos[2].ID != "PL1-video-2" {
+				t.Errorf("snapshot = %q with %d videos", playlist.Title, len(playlist.Videos))
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+)
+
+// QuotaService reports YouTube Data API quota consumption
+type QuotaService struct {
+	tracker  *youtube.QuotaTracker
+	sessions *SessionService
+}
+
+// NewQuotaService creates a new QuotaService. sessions resolves session
+// credentials to the access token their requests are made with.
+func NewQuotaService(tracker *youtube.QuotaTracker, sessions *SessionService) *QuotaService {
+	return &QuotaService{
+		tracker:  tracker,
+		sessions: sessions,
+	}
+}
+
+// GetQuota returns today's quota usage overall and for the caller. userID is
+// set when the caller authenticated with a session credential; its usage is
+// then that of the user's current Google access token.
+func (s *QuotaService) GetQuota(ctx context.Context, accessToken, userID string) (*models.QuotaResponse, error) {
+	if userID != "" && s.sessions != nil {
+		source, err := s.sessions.TokenSource(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		tok, err := source.Token()
+		if err != nil {
+			return nil, youtubeError(err, "Failed to load the YouTube access token")
+		}
+		accessToken = tok.AccessToken
+	}
+
+	usage := s.tracker.Usage()
+	tokenUsage := s.tracker.TokenUsage(accessToken)
+
+	return &models.QuotaResponse{
+		Budget:    usage.Budget,
+		Used:      usage.Used,
+		Remaining: usage.Remaining,
+		ResetAt:   usage.ResetAt,
+		ByMethod:  usage.ByMethod,
+		Token: models.TokenQuotaResponse{
+			Key:      youtube.TokenKey(accessToken),
+			Used:     tokenUsage.Used,
+			ByMethod: tokenUsage.ByMethod,
+		},
+		Tokens: len(usage.ByToken),
+	}, nil
+}
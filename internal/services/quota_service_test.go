@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+	"golang.org/x/oauth2"
+)
+
+// newTestSessionService creates a SessionService over an in-memory store with
+// an OAuth client, and saves tok as the YouTube token of userID
+func newTestSessionService(t *testing.T, userID string, tok *oauth2.Token) *SessionService {
+	t.Helper()
+
+	credentials := filepath.Join(t.TempDir(), "credentials.json")
+	data := `{"installed":{"client_id":"client-id","client_secret":"secret","redirect_uris":["http://localhost/callback"]}}`
+	if err := os.WriteFile(credentials, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	st := store.NewMemoryStore()
+	tokens := store.NewTokenStore(st, store.ProviderYouTube)
+	if err := tokens.Save(context.Background(), userID, tok); err != nil {
+		t.Fatal(err)
+	}
+	return NewSessionService(st, tokens, credentials, 0)
+}
+
+func TestGetQuotaResolvesSessionToken(t *testing.T) {
+	ctx := context.Background()
+	tok := &oauth2.Token{AccessToken: "ya29.current", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}
+	sessions := newTestSessionService(t, "user-1", tok)
+	credential, _, err := sessions.CreateSession(ctx, "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := youtube.NewQuotaTracker(0)
+	for _, method := range []string{"playlists.list", "playlistItems.insert"} {
+		if err := tracker.Reserve(tok.AccessToken, method); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tracker.Reserve("ya29.someone-else", "playlists.list"); err != nil {
+		t.Fatal(err)
+	}
+	service := NewQuotaService(tracker, sessions)
+
+	tests := []struct {
+		name       string
+		credential string
+		userID     string
+		wantUsed   int
+	}{
+		{name: "session credential", credential: credential, userID: "user-1", wantUsed: 51},
+		{name: "raw access token", credential: tok.AccessToken, wantUsed: 51},
+		{name: "another access token", credential: "ya29.someone-else", wantUsed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := service.GetQuota(ctx, tt.credential, tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.Token.Used != tt.wantUsed {
+				t.Errorf("token used = %d, want %d", response.Token.Used, tt.wantUsed)
+			}
+			if response.Used != 52 || response.Tokens != 2 {
+				t.Errorf("overall: used %d by %d tokens, want 52 by 2", response.Used, response.Tokens)
+			}
+		})
+	}
+}
+
+func TestGetQuotaWithoutStoredToken(t *testing.T) {
+	sessions := newTestSessionService(t, "user-1", &oauth2.Token{AccessToken: "ya29.current", Expiry: time.Now().Add(time.Hour)})
+	service := NewQuotaService(youtube.NewQuotaTracker(0), sessions)
+
+	if _, err := service.GetQuota(context.Background(), "pmt_credential", "user-2"); err == nil {
+		t.Error("expected an error for a user without a saved token")
+	}
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
+	"github.com/alejpaa/playlist-migration-tool/pkg/auth"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+	"golang.org/x/oauth2"
+)
+
+// SessionPrefix starts every session credential, telling it apart from a raw
+// Google access token
+const SessionPrefix = "pmt_"
+
+// DefaultSessionTTL is how long a session credential stays valid
+const DefaultSessionTTL = 30 * 24 * time.Hour
+
+// SessionService issues session credentials that map to a user's stored
+// OAuth token. Clients keep the credential instead of a Google access token,
+// and the server refreshes the underlying token when it expires.
+type SessionService struct {
+	store           store.Store
+	tokens          auth.TokenStore
+	credentialsFile string
+	ttl             time.Duration
+
+	mu      sync.Mutex
+	config  *oauth2.Config                // Loaded on first use
+	sources map[string]oauth2.TokenSource // Shared by every request of a user
+}
+
+// NewSessionService creates a new SessionService. Tokens are refreshed with
+// the OAuth client in credentialsFile.
+func NewSessionService(st store.Store, tokens auth.TokenStore, credentialsFile string, ttl time.Duration) *SessionService {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	return &SessionService{
+		store:           st,
+		tokens:          tokens,
+		credentialsFile: credentialsFile,
+		ttl:             ttl,
+		sources:         make(map[string]oauth2.TokenSource),
+	}
+}
+
+// IsSessionCredential reports whether a bearer credential was issued by SessionService
+func IsSessionCredential(credential string) bool {
+	return strings.HasPrefix(credential, SessionPrefix)
+}
+
+// CreateSession issues a session credential for a user whose token has just
+// been saved, and returns it with its expiry
+func (s *SessionService) CreateSession(ctx context.Context, userID string) (string, time.Time, error) {
+	secret, err := randomSecret()
+	if err != nil {
+		return "", time.Time{}, models.NewInternalServerError("Unable to create session", err)
+	}
+	credential := SessionPrefix + secret
+
+	now := time.Now()
+	session := &store.Session{
+		ID:        secretHash(credential),
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.store.SaveSession(ctx, session); err != nil {
+		return "", time.Time{}, models.NewInternalServerError("Unable to create session", err)
+	}
+
+	// The user signed in again, so drop the token source holding the old token
+	s.forget(userID)
+
+	return credential, session.ExpiresAt, nil
+}
+
+// SaveUser records a user who signed in, with the name shown for them
+func (s *SessionService) SaveUser(ctx context.Context, userID, displayName string) error {
+	if err := s.store.SaveUser(ctx, &store.User{ID: userID, DisplayName: displayName}); err != nil {
+		return models.NewInternalServerError("Unable to save user", err)
+	}
+	return nil
+}
+
+// ValidateSession returns the user a session credential belongs to
+func (s *SessionService) ValidateSession(ctx context.Context, credential string) (string, error) {
+	id := secretHash(credential)
+
+	session, err := s.store.GetSession(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return "", models.NewUnauthorizedError("Invalid session", nil)
+	}
+	if err != nil {
+		return "", models.NewInternalServerError("Unable to load session", err)
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		if err := s.store.DeleteSession(ctx, id); err != nil && !errors.Is(err, store.ErrNotFound) {
+			log.Printf("Failed to delete expired session of %s: %v", session.UserID, err)
+		}
+		return "", models.NewUnauthorizedError("Session expired, sign in again", nil)
+	}
+
+	return session.UserID, nil
+}
+
+// TokenSource returns the token source of a user. It refreshes the stored
+// token when it expires and saves the rotated token.
+func (s *SessionService) TokenSource(ctx context.Context, userID string) (oauth2.TokenSource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if source, ok := s.sources[userID]; ok {
+		return source, nil
+	}
+
+	if s.config == nil {
+		config, err := auth.LoadConfig(s.credentialsFile)
+		if err != nil {
+			return nil, models.NewInternalServerError("Unable to load OAuth credentials", err)
+		}
+		s.config = config
+	}
+
+	tok, err := s.tokens.Load(ctx, userID)
+	if errors.Is(err, auth.ErrTokenNotFound) {
+		return nil, models.NewUnauthorizedError("No YouTube token saved for this session, sign in again", err)
+	}
+	if err != nil {
+		return nil, models.NewInternalServerError("Unable to load token", err)
+	}
+
+	source := auth.NewTokenSource(s.config, s.tokens, userID, tok)
+	s.sources[userID] = source
+	return source, nil
+}
+
+// SessionToken returns the current token of a session's user, refreshed
+// when it has expired
+func (s *SessionService) SessionToken(ctx context.Context, userID string) (*oauth2.Token, error) {
+	source, err := s.TokenSource(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tok, err := source.Token()
+	if isTokenRefreshError(err) {
+		return nil, models.NewUnauthorizedError("Google rejected the refresh token, sign in again", err)
+	}
+	if err != nil {
+		return nil, models.NewServiceUnavailableError("Unable to refresh the YouTube token, try again later", err)
+	}
+	return tok, nil
+}
+
+// ClientFactory returns a ClientFactory that accepts session credentials as
+// well as raw access tokens. Clients built for a session take their token
+// from the user's token source, so they keep working across refreshes.
+func (s *SessionService) ClientFactory(opts ...youtube.Option) ClientFactory {
+	return func(ctx context.Context, credential string) *youtube.Client {
+		if !IsSessionCredential(credential) {
+			return youtube.NewClient(credential, opts...)
+		}
+
+		var source oauth2.TokenSource
+		userID, err := s.ValidateSession(ctx, credential)
+		if err == nil {
+			source, err = s.TokenSource(ctx, userID)
+		}
+		if err != nil {
+			// Every call made by the client fails with err
+			source = errorTokenSource{err: err}
+		}
+
+		return youtube.NewClient("", append(opts, youtube.WithTokenSource(source))...)
+	}
+}
+
+// EndUserSessions invalidates every session of a user
+func (s *SessionService) EndUserSessions(ctx context.Context, userID string) error {
+	if err := s.store.DeleteUserSessions(ctx, userID); err != nil {
+		return models.NewInternalServerError("Unable to delete sessions", err)
+	}
+	s.forget(userID)
+	return nil
+}
+
+// forget drops the cached token source of a user
+func (s *SessionService) forget(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sources, userID)
+}
+
+// secretHash returns the key a secret, such as a session credential or an
+// OAuth state, is stored under
+func secretHash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomSecret returns 32 random bytes, base64url encoded
+func randomSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// errorTokenSource is a TokenSource that always fails
+type errorTokenSource struct {
+	err error
+}
+
+// Token implements oauth2.TokenSource
+func (s errorTokenSource) Token() (*oauth2.Token, error) {
+	return nil, s.err
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/alejpaa/playlist-migration-tool/internal/models"
+	"github.com/alejpaa/playlist-migration-tool/internal/store"
+	"golang.org/x/oauth2"
+)
+
+// Spotify scopes needed to create playlists and add tracks to them
+var spotifyScopes = []string{"playlist-modify-public", "playlist-modify-private"}
+
+// SpotifyAuthService handles Spotify OAuth authentication
+type SpotifyAuthService struct {
+	config *oauth2.Config
+	states *OAuthStates
+}
+
+// NewSpotifyAuthService creates a new SpotifyAuthService. accountsURL is the
+// base URL of the Spotify accounts service (https://accounts.spotify.com).
+func NewSpotifyAuthService(clientID, clientSecret, redirectURL, accountsURL string, states *OAuthStates) *SpotifyAuthService {
+	accountsURL = strings.TrimRight(accountsURL, "/")
+	return &SpotifyAuthService{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       spotifyScopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:   accountsURL + "/authorize",
+				TokenURL:  accountsURL + "/api/token",
+				AuthStyle: oauth2.AuthStyleInHeader,
+			},
+		},
+		states: states,
+	}
+}
+
+// GetAuthURL returns the URL where the user authorizes access to Spotify.
+// Each URL carries a single-use state and a PKCE challenge. The returned
+// nonce must be set as a cookie in the browser starting the sign-in.
+func (s *SpotifyAuthService) GetAuthURL(ctx context.Context) (string, string, error) {
+	if s.config.ClientID == "" {
+		return "", "", models.NewServiceUnavailableError("Spotify is not configured", nil)
+	}
+
+	state, nonce, challenge, err := s.states.Begin(ctx, store.ProviderSpotify)
+	if err != nil {
+		return "", "", err
+	}
+	return s.config.AuthCodeURL(state, challenge), nonce, nil
+}
+
+// CompleteAuth exchanges a Spotify authorization code for an access token.
+// state must come from a URL returned by GetAuthURL, and nonce from the
+// cookie of the same browser.
+func (s *SpotifyAuthService) CompleteAuth(ctx context.Context, authCode, state, nonce string) (*models.AuthResponse, error) {
+	if s.config.ClientID == "" {
+		return nil, models.NewServiceUnavailableError("Spotify is not configured", nil)
+	}
+
+	verifier, err := s.states.Complete(ctx, store.ProviderSpotify, state, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	tok, err := s.config.Exchange(ctx, authCode, verifier)
+	if err != nil {
+		return nil, models.NewBadRequestError("Invalid authorization code", err)
+	}
+
+	return &models.AuthResponse{
+		Success:     true,
+		AccessToken: tok.AccessToken,
+		Message:     "Successfully authenticated with Spotify",
+	}, nil
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestListJobs(t *testing.T) {
+	for name, open := range testStores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			st := open(t)
+			start := time.Now().Add(-time.Hour).Truncate(time.Second)
+			finished := start.Add(time.Minute)
+
+			records := []Job{
+				{ID: "running", State: "running", CreatedAt: start.Add(2 * time.Second)},
+				{ID: "done", State: "succeeded", CreatedAt: start, FinishedAt: &finished},
+				{ID: "queued", State: "queued", CreatedAt: start.Add(3 * time.Second)},
+				{ID: "older", State: "running", CreatedAt: start.Add(time.Second)},
+			}
+			for i := range records {
+				records[i].Type = "export"
+				records[i].UserID = "user-1"
+				records[i].Data = []byte(`{"id":"` + records[i].ID + `"}`)
+				if err := st.SaveJob(ctx, &records[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			jobs, err := st.ListJobs(ctx, "queued", "running")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, job := range jobs {
+				ids = append(ids, job.ID)
+				if job.UserID != "user-1" || string(job.Data) != `{"id":"`+job.ID+`"}` {
+					t.Errorf("job %s: user %q, data %s", job.ID, job.UserID, job.Data)
+				}
+			}
+			if got, want := fmt.Sprint(ids), "[older running queued]"; got != want {
+				t.Errorf("jobs = %s, want %s", got, want)
+			}
+
+			if jobs, err := st.ListJobs(ctx); err != nil || len(jobs) != 0 {
+				t.Errorf("no states: %d jobs, %v", len(jobs), err)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps every record in memory. Records are lost on restart.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	users     map[string]User
+	tokens    map[[2]string]Token
+	sessions  map[string]Session
+	states    map[string]OAuthState
+	snapshots map[[2]string]Snapshot
+	jobs      map[string]Job
+	decisions map[string]map[int]MatchDecision
+	audit     []AuditEntry
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:     make(map[string]User),
+		tokens:    make(map[[2]string]Token),
+		sessions:  make(map[string]Session),
+		states:    make(map[string]OAuthState),
+		snapshots: make(map[[2]string]Snapshot),
+		jobs:      make(map[string]Job),
+		decisions: make(map[string]map[int]MatchDecision),
+	}
+}
+
+// SaveUser creates or updates a user
+func (s *MemoryStore) SaveUser(ctx context.Context, user *User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	saved := *user
+	saved.CreatedAt = now
+	if existing, ok := s.users[user.ID]; ok {
+		saved.CreatedAt = existing.CreatedAt
+	}
+	saved.UpdatedAt = now
+	s.users[user.ID] = saved
+	return nil
+}
+
+// SaveToken creates or replaces the token of a user for a provider. The
+// saved refresh token is kept when the new token has none.
+func (s *MemoryStore) SaveToken(ctx context.Context, token *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := [2]string{token.UserID, token.Provider}
+	saved := *token
+	saved.Scopes = append([]string(nil), token.Scopes...)
+	saved.UpdatedAt = time.Now()
+	if existing, ok := s.tokens[key]; ok && saved.RefreshToken == "" {
+		saved.RefreshToken = existing.RefreshToken
+	}
+	s.tokens[key] = saved
+	return nil
+}
+
+// GetToken returns the token of a user for a provider
+func (s *MemoryStore) GetToken(ctx context.Context, userID, provider string) (*Token, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	token, ok := s.tokens[[2]string{userID, provider}]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	token.Scopes = append([]string(nil), token.Scopes...)
+	return &token, nil
+}
+
+// DeleteToken removes the token of a user for a provider
+func (s *MemoryStore) DeleteToken(ctx context.Context, userID, provider string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := [2]string{userID, provider}
+	if _, ok := s.tokens[key]; !ok {
+		return ErrNotFound
+	}
+	delete(s.tokens, key)
+	return nil
+}
+
+// SaveSession creates or replaces a session
+func (s *MemoryStore) SaveSession(ctx context.Context, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *session
+	if saved.CreatedAt.IsZero() {
+		saved.CreatedAt = time.Now()
+	}
+	s.sessions[session.ID] = saved
+	return nil
+}
+
+// GetSession returns a session by ID
+func (s *MemoryStore) GetSession(ctx context.Context, id string) (*Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &session, nil
+}
+
+// DeleteSession removes a session
+func (s *MemoryStore) DeleteSession(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.sessions, id)
+	return nil
+}
+
+// DeleteUserSessions removes every session of a user
+func (s *MemoryStore) DeleteUserSessions(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, id)
+		}
+	}
+	return nil
+}
+
+// SaveOAuthState stores a pending authorization and drops expired ones
+func (s *MemoryStore) SaveOAuthState(ctx context.Context, state *OAuthState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, saved := range s.states {
+		if now.After(saved.ExpiresAt) {
+			delete(s.states, id)
+		}
+	}
+
+	saved := *state
+	if saved.CreatedAt.IsZero() {
+		saved.CreatedAt = now
+	}
+	s.states[state.ID] = saved
+	return nil
+}
+
+// ConsumeOAuthState returns and deletes a pending authorization
+func (s *MemoryStore) ConsumeOAuthState(ctx context.Context, id string) (*OAuthState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	delete(s.states, id)
+	return &state, nil
+}
+
+// SaveSnapshot creates or replaces a snapshot
+func (s *MemoryStore) SaveSnapshot(ctx context.Context, snapshot *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := [2]string{snapshot.Kind, snapshot.ID}
+	now := time.Now()
+	saved := *snapshot
+	saved.Data = append([]byte(nil), snapshot.Data...)
+	saved.CreatedAt = now
+	if existing, ok := s.snapshots[key]; ok {
+		saved.CreatedAt = existing.CreatedAt
+	}
+	saved.UpdatedAt = now
+	s.snapshots[key] = saved
+	return nil
+}
+
+// GetSnapshot returns a snapshot by kind and ID
+func (s *MemoryStore) GetSnapshot(ctx context.Context, kind, id string) (*Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, ok := s.snapshots[[2]string{kind, id}]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	snapshot.Data = append([]byte(nil), snapshot.Data...)
+	return &snapshot, nil
+}
+
+// SaveJob creates or updates a job record
+func (s *MemoryStore) SaveJob(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *job
+	saved.Data = append([]byte(nil), job.Data...)
+	saved.UpdatedAt = time.Now()
+	s.jobs[job.ID] = saved
+	return nil
+}
+
+// GetJob returns a job record by ID
+func (s *MemoryStore) GetJob(ctx context.Context, id string) (*Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	job.Data = append([]byte(nil), job.Data...)
+	return &job, nil
+}
+
+// ListJobs returns the jobs in any of the given states, oldest first
+func (s *MemoryStore) ListJobs(ctx context.Context, states ...string) ([]Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := []Job{}
+	for _, job := range s.jobs {
+		for _, state := range states {
+			if job.State == state {
+				job.Data = append([]byte(nil), job.Data...)
+				jobs = append(jobs, job)
+				break
+			}
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs, nil
+}
+
+// SaveMatchDecision records a review decision, replacing any earlier
+// decision on the same track
+func (s *MemoryStore) SaveMatchDecision(ctx context.Context, decision *MatchDecision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	decisions, ok := s.decisions[decision.MigrationID]
+	if !ok {
+		decisions = make(map[int]MatchDecision)
+		s.decisions[decision.MigrationID] = decisions
+	}
+	decisions[decision.Position] = *decision
+	return nil
+}
+
+// AddAuditEntry appends an audit entry and sets its ID
+func (s *MemoryStore) AddAuditEntry(ctx context.Context, entry *AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.ID = int64(len(s.audit) + 1)
+	s.audit = append(s.audit, *entry)
+	return nil
+}
+
+// Close does nothing; it exists to satisfy Store
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// schemaMigrations are applied in order, each once. Append new migrations to
+// the end; never edit one that has been released.
+var schemaMigrations = []string{
+	// 1: initial schema
+	`CREATE TABLE users (
+		id           TEXT PRIMARY KEY,
+		display_name TEXT NOT NULL DEFAULT '',
+		created_at   TIMESTAMP NOT NULL,
+		updated_at   TIMESTAMP NOT NULL
+	);
+
+	CREATE TABLE tokens (
+		user_id       TEXT NOT NULL,
+		provider      TEXT NOT NULL,
+		access_token  TEXT NOT NULL,
+		refresh_token TEXT NOT NULL DEFAULT '',
+		token_type    TEXT NOT NULL DEFAULT '',
+		expiry        TIMESTAMP,
+		scopes        TEXT NOT NULL DEFAULT '',
+		updated_at    TIMESTAMP NOT NULL,
+		PRIMARY KEY (user_id, provider)
+	);
+
+	CREATE TABLE snapshots (
+		kind       TEXT NOT NULL,
+		id         TEXT NOT NULL,
+		data       BLOB NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (kind, id)
+	);
+
+	CREATE TABLE jobs (
+		id          TEXT PRIMARY KEY,
+		type        TEXT NOT NULL,
+		user_id     TEXT NOT NULL DEFAULT '',
+		state       TEXT NOT NULL,
+		data        BLOB,
+		created_at  TIMESTAMP NOT NULL,
+		updated_at  TIMESTAMP NOT NULL,
+		finished_at TIMESTAMP
+	);
+
+	CREATE TABLE match_decisions (
+		migration_id   TEXT NOT NULL,
+		position       INTEGER NOT NULL,
+		source_id      TEXT NOT NULL,
+		destination_id TEXT NOT NULL DEFAULT '',
+		action         TEXT NOT NULL,
+		confidence     REAL NOT NULL DEFAULT 0,
+		decided_at     TIMESTAMP NOT NULL,
+		PRIMARY KEY (migration_id, position)
+	);
+
+	CREATE TABLE audit_entries (
+		id      INTEGER PRIMARY KEY AUTOINCREMENT,
+		time    TIMESTAMP NOT NULL,
+		actor   TEXT NOT NULL DEFAULT '',
+		action  TEXT NOT NULL,
+		target  TEXT NOT NULL DEFAULT '',
+		details TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX audit_entries_time ON audit_entries (time);`,
+
+	// 2: sessions
+	`CREATE TABLE sessions (
+		id         TEXT PRIMARY KEY,
+		user_id    TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		expires_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX sessions_user_id ON sessions (user_id);`,
+
+	// 3: OAuth states
+	`CREATE TABLE oauth_states (
+		id            TEXT PRIMARY KEY,
+		provider      TEXT NOT NULL,
+		code_verifier TEXT NOT NULL,
+		browser_hash  TEXT NOT NULL,
+		created_at    TIMESTAMP NOT NULL,
+		expires_at    TIMESTAMP NOT NULL
+	);`,
+}
+
+// migrate brings the database schema up to date
+func migrate(ctx context.Context, db *sql.DB) error {
+	return migrateTo(ctx, db, len(schemaMigrations))
+}
+
+// migrateTo applies the pending migrations up to and including target
+func migrateTo(ctx context.Context, db *sql.DB, target int) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("unable to create schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("unable to read schema version: %w", err)
+	}
+
+	for version := current + 1; version <= target; version++ {
+		if err := applyMigration(ctx, db, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMigration runs a single schema migration in a transaction
+func applyMigration(ctx context.Context, db *sql.DB, version int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to apply migration %d: %w", version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, schemaMigrations[version-1]); err != nil {
+		return fmt.Errorf("unable to apply migration %d: %w", version, err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now().UTC()); err != nil {
+		return fmt.Errorf("unable to record migration %d: %w", version, err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,372 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// Registers the pure-Go "sqlite" driver
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore persists records in an embedded SQLite database
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens (creating if needed) the SQLite database at dsn and
+// applies any pending schema migrations
+func OpenSQLite(dsn string) (*SQLiteStore, error) {
+	if err := createDir(dsn); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open database: %w", err)
+	}
+
+	// SQLite allows a single writer; one connection avoids "database is locked"
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, pragma := range []string{"PRAGMA journal_mode = WAL", "PRAGMA busy_timeout = 5000", "PRAGMA foreign_keys = ON"} {
+		if _, err := db.ExecContext(ctx, pragma); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("unable to configure database: %w", err)
+		}
+	}
+
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// SaveUser creates or updates a user
+func (s *SQLiteStore) SaveUser(ctx context.Context, user *User) error {
+	now := time.Now().UTC()
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO users (id, display_name, created_at, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET display_name = excluded.display_name, updated_at = excluded.updated_at`,
+		user.ID, user.DisplayName, now, now)
+	if err != nil {
+		return fmt.Errorf("unable to save user: %w", err)
+	}
+	return nil
+}
+
+// SaveToken creates or replaces the token of a user for a provider. The
+// saved refresh token is kept when the new token has none.
+func (s *SQLiteStore) SaveToken(ctx context.Context, token *Token) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO tokens (user_id, provider, access_token, refresh_token, token_type, expiry, scopes, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, provider) DO UPDATE SET
+			access_token = excluded.access_token,
+			refresh_token = COALESCE(NULLIF(excluded.refresh_token, ''), tokens.refresh_token),
+			token_type = excluded.token_type,
+			expiry = excluded.expiry,
+			scopes = excluded.scopes,
+			updated_at = excluded.updated_at`,
+		token.UserID, token.Provider, token.AccessToken, token.RefreshToken, token.TokenType,
+		nullTime(token.Expiry), strings.Join(token.Scopes, " "), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("unable to save token: %w", err)
+	}
+	return nil
+}
+
+// GetToken returns the token of a user for a provider
+func (s *SQLiteStore) GetToken(ctx context.Context, userID, provider string) (*Token, error) {
+	var token Token
+	var expiry sql.NullTime
+	var scopes string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT user_id, provider, access_token, refresh_token, token_type, expiry, scopes, updated_at
+		FROM tokens WHERE user_id = ? AND provider = ?`, userID, provider).
+		Scan(&token.UserID, &token.Provider, &token.AccessToken, &token.RefreshToken, &token.TokenType,
+			&expiry, &scopes, &token.UpdatedAt)
+	if err != nil {
+		return nil, notFound(err, "unable to load token")
+	}
+	token.Expiry = expiry.Time
+	token.Scopes = strings.Fields(scopes)
+	return &token, nil
+}
+
+// DeleteToken removes the token of a user for a provider
+func (s *SQLiteStore) DeleteToken(ctx context.Context, userID, provider string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM tokens WHERE user_id = ? AND provider = ?`, userID, provider)
+	if err != nil {
+		return fmt.Errorf("unable to delete token: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SaveSession creates or replaces a session
+func (s *SQLiteStore) SaveSession(ctx context.Context, session *Session) error {
+	createdAt := session.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, user_id, created_at, expires_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET user_id = excluded.user_id, expires_at = excluded.expires_at`,
+		session.ID, session.UserID, createdAt.UTC(), session.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("unable to save session: %w", err)
+	}
+	return nil
+}
+
+// GetSession returns a session by ID
+func (s *SQLiteStore) GetSession(ctx context.Context, id string) (*Session, error) {
+	var session Session
+	err := s.db.QueryRowContext(ctx, `SELECT id, user_id, created_at, expires_at FROM sessions WHERE id = ?`, id).
+		Scan(&session.ID, &session.UserID, &session.CreatedAt, &session.ExpiresAt)
+	if err != nil {
+		return nil, notFound(err, "unable to load session")
+	}
+	return &session, nil
+}
+
+// DeleteSession removes a session
+func (s *SQLiteStore) DeleteSession(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("unable to delete session: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteUserSessions removes every session of a user
+func (s *SQLiteStore) DeleteUserSessions(ctx context.Context, userID string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("unable to delete sessions: %w", err)
+	}
+	return nil
+}
+
+// SaveOAuthState stores a pending authorization and drops expired ones
+func (s *SQLiteStore) SaveOAuthState(ctx context.Context, state *OAuthState) error {
+	now := time.Now().UTC()
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM oauth_states WHERE expires_at < ?`, now); err != nil {
+		return fmt.Errorf("unable to prune OAuth states: %w", err)
+	}
+
+	createdAt := state.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+	_, err := s.db.ExecContext(ctx, `INSERT INTO oauth_states (id, provider, code_verifier, browser_hash, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		state.ID, state.Provider, state.CodeVerifier, state.BrowserHash, createdAt.UTC(), state.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("unable to save OAuth state: %w", err)
+	}
+	return nil
+}
+
+// ConsumeOAuthState returns and deletes a pending authorization in a single
+// statement, so two callbacks racing with the same state cannot both succeed
+func (s *SQLiteStore) ConsumeOAuthState(ctx context.Context, id string) (*OAuthState, error) {
+	var state OAuthState
+	err := s.db.QueryRowContext(ctx, `
+		DELETE FROM oauth_states WHERE id = ?
+		RETURNING id, provider, code_verifier, browser_hash, created_at, expires_at`, id).
+		Scan(&state.ID, &state.Provider, &state.CodeVerifier, &state.BrowserHash, &state.CreatedAt, &state.ExpiresAt)
+	if err != nil {
+		return nil, notFound(err, "unable to load OAuth state")
+	}
+	return &state, nil
+}
+
+// SaveSnapshot creates or replaces a snapshot
+func (s *SQLiteStore) SaveSnapshot(ctx context.Context, snapshot *Snapshot) error {
+	now := time.Now().UTC()
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO snapshots (kind, id, data, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (kind, id) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+		snapshot.Kind, snapshot.ID, []byte(snapshot.Data), now, now)
+	if err != nil {
+		return fmt.Errorf("unable to save snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetSnapshot returns a snapshot by kind and ID
+func (s *SQLiteStore) GetSnapshot(ctx context.Context, kind, id string) (*Snapshot, error) {
+	var snapshot Snapshot
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT kind, id, data, created_at, updated_at FROM snapshots WHERE kind = ? AND id = ?`, kind, id).
+		Scan(&snapshot.Kind, &snapshot.ID, &data, &snapshot.CreatedAt, &snapshot.UpdatedAt)
+	if err != nil {
+		return nil, notFound(err, "unable to load snapshot")
+	}
+	snapshot.Data = data
+	return &snapshot, nil
+}
+
+// SaveJob creates or updates a job record
+func (s *SQLiteStore) SaveJob(ctx context.Context, job *Job) error {
+	var finishedAt sql.NullTime
+	if job.FinishedAt != nil {
+		finishedAt = sql.NullTime{Time: job.FinishedAt.UTC(), Valid: true}
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO jobs (id, type, user_id, state, data, created_at, updated_at, finished_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			state = excluded.state,
+			data = excluded.data,
+			updated_at = excluded.updated_at,
+			finished_at = excluded.finished_at`,
+		job.ID, job.Type, job.UserID, job.State, []byte(job.Data), job.CreatedAt.UTC(), time.Now().UTC(), finishedAt)
+	if err != nil {
+		return fmt.Errorf("unable to save job: %w", err)
+	}
+	return nil
+}
+
+// GetJob returns a job record by ID
+func (s *SQLiteStore) GetJob(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	var data []byte
+	var finishedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `SELECT id, type, user_id, state, data, created_at, updated_at, finished_at FROM jobs WHERE id = ?`, id).
+		Scan(&job.ID, &job.Type, &job.UserID, &job.State, &data, &job.CreatedAt, &job.UpdatedAt, &finishedAt)
+	if err != nil {
+		return nil, notFound(err, "unable to load job")
+	}
+	job.Data = data
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return &job, nil
+}
+
+// ListJobs returns the jobs in any of the given states, oldest first
+func (s *SQLiteStore) ListJobs(ctx context.Context, states ...string) ([]Job, error) {
+	jobs := []Job{}
+	if len(states) == 0 {
+		return jobs, nil
+	}
+
+	args := make([]interface{}, len(states))
+	for i, state := range states {
+		args[i] = state
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(states)), ", ")
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, type, user_id, state, data, created_at, updated_at, finished_at
+		FROM jobs WHERE state IN (`+placeholders+`) ORDER BY created_at`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load jobs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var job Job
+		var data []byte
+		var finishedAt sql.NullTime
+		if err := rows.Scan(&job.ID, &job.Type, &job.UserID, &job.State, &data, &job.CreatedAt, &job.UpdatedAt, &finishedAt); err != nil {
+			return nil, fmt.Errorf("unable to load jobs: %w", err)
+		}
+		job.Data = data
+		if finishedAt.Valid {
+			job.FinishedAt = &finishedAt.Time
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// SaveMatchDecision records a review decision, replacing any earlier
+// decision on the same track
+func (s *SQLiteStore) SaveMatchDecision(ctx context.Context, decision *MatchDecision) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO match_decisions (migration_id, position, source_id, destination_id, action, confidence, decided_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (migration_id, position) DO UPDATE SET
+			source_id = excluded.source_id,
+			destination_id = excluded.destination_id,
+			action = excluded.action,
+			confidence = excluded.confidence,
+			decided_at = excluded.decided_at`,
+		decision.MigrationID, decision.Position, decision.SourceID, decision.DestinationID,
+		decision.Action, decision.Confidence, decision.DecidedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("unable to save match decision: %w", err)
+	}
+	return nil
+}
+
+// AddAuditEntry appends an audit entry and sets its ID
+func (s *SQLiteStore) AddAuditEntry(ctx context.Context, entry *AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	result, err := s.db.ExecContext(ctx, `INSERT INTO audit_entries (time, actor, action, target, details) VALUES (?, ?, ?, ?, ?)`,
+		entry.Time.UTC(), entry.Actor, entry.Action, entry.Target, entry.Details)
+	if err != nil {
+		return fmt.Errorf("unable to save audit entry: %w", err)
+	}
+	entry.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// createDir creates the directory of a file DSN such as
+// "file:data/app.db?_pragma=..." so SQLite can create the database in it
+func createDir(dsn string) error {
+	path := strings.TrimPrefix(dsn, "file:")
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" || path == ":memory:" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("unable to create database directory: %w", err)
+	}
+	return nil
+}
+
+// notFound turns sql.ErrNoRows into ErrNotFound and wraps other errors
+func notFound(err error, message string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// nullTime stores the zero time as NULL
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
@@ -0,0 +1,146 @@
+// Package store persists users, OAuth tokens and states, sessions, snapshots,
+// jobs, match decisions and audit entries. Open returns an embedded SQLite
+// store, or an in-memory one for tests and throwaway runs.
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// User is an account that connected to the service
+type User struct {
+	ID          string    `json:"id"` // YouTube channel ID
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Token is an OAuth token of a user for a provider ("youtube", "spotify")
+type Token struct {
+	UserID       string    `json:"user_id"`
+	Provider     string    `json:"provider"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+	Scopes       []string  `json:"scopes,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Session maps a server-issued session credential to a user. Only a hash of
+// the credential is kept, so a leaked database does not leak sessions.
+type Session struct {
+	ID        string    `json:"id"` // SHA-256 of the credential, hex encoded
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// OAuthState is a pending OAuth authorization, created when the auth URL is
+// handed out and consumed by the callback
+type OAuthState struct {
+	ID           string    `json:"id"` // SHA-256 of the state parameter, hex encoded
+	Provider     string    `json:"provider"`
+	CodeVerifier string    `json:"code_verifier"` // PKCE verifier
+	BrowserHash  string    `json:"browser_hash"`  // SHA-256 of the nonce cookie set in the browser that started the sign-in
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Snapshot kinds
+const (
+	SnapshotMigration = "migration"
+	SnapshotPlaylist  = "playlist"
+)
+
+// Snapshot is a JSON document identified by kind and ID, such as the state
+// of a migration or a copy of a playlist
+type Snapshot struct {
+	Kind      string          `json:"kind"`
+	ID        string          `json:"id"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Job is the record of a background job
+type Job struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	UserID     string          `json:"user_id,omitempty"` // Who submitted the job
+	State      string          `json:"state"`
+	Data       json.RawMessage `json:"data,omitempty"` // Progress, errors and result
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+// MatchDecision is a review decision on a migration track
+type MatchDecision struct {
+	MigrationID   string    `json:"migration_id"`
+	Position      int       `json:"position"`
+	SourceID      string    `json:"source_id"`
+	DestinationID string    `json:"destination_id,omitempty"`
+	Action        string    `json:"action"`
+	Confidence    float64   `json:"confidence"`
+	DecidedAt     time.Time `json:"decided_at"`
+}
+
+// AuditEntry records an action taken through the API
+type AuditEntry struct {
+	ID      int64     `json:"id"`
+	Time    time.Time `json:"time"`
+	Actor   string    `json:"actor,omitempty"` // User ID, or token key when the user is unknown
+	Action  string    `json:"action"`
+	Target  string    `json:"target,omitempty"`
+	Details string    `json:"details,omitempty"`
+}
+
+// Store is the repository used by the services. Implementations are safe for
+// concurrent use.
+type Store interface {
+	SaveUser(ctx context.Context, user *User) error
+
+	SaveToken(ctx context.Context, token *Token) error
+	GetToken(ctx context.Context, userID, provider string) (*Token, error)
+	DeleteToken(ctx context.Context, userID, provider string) error
+
+	SaveSession(ctx context.Context, session *Session) error
+	GetSession(ctx context.Context, id string) (*Session, error)
+	DeleteSession(ctx context.Context, id string) error
+	DeleteUserSessions(ctx context.Context, userID string) error
+
+	SaveOAuthState(ctx context.Context, state *OAuthState) error
+	// ConsumeOAuthState returns and deletes a state, so it can only be used once
+	ConsumeOAuthState(ctx context.Context, id string) (*OAuthState, error)
+
+	SaveSnapshot(ctx context.Context, snapshot *Snapshot) error
+	GetSnapshot(ctx context.Context, kind, id string) (*Snapshot, error)
+
+	SaveJob(ctx context.Context, job *Job) error
+	GetJob(ctx context.Context, id string) (*Job, error)
+	// ListJobs returns the jobs in any of the given states, oldest first
+	ListJobs(ctx context.Context, states ...string) ([]Job, error)
+
+	SaveMatchDecision(ctx context.Context, decision *MatchDecision) error
+
+	AddAuditEntry(ctx context.Context, entry *AuditEntry) error
+
+	Close() error
+}
+
+// Open opens the store described by dsn. "memory" (or an empty DSN) returns
+// a MemoryStore; anything else is a SQLite DSN such as "file:data/app.db".
+func Open(dsn string) (Store, error) {
+	if dsn == "" || strings.EqualFold(dsn, "memory") {
+		return NewMemoryStore(), nil
+	}
+	return OpenSQLite(dsn)
+}
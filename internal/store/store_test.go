@@ -0,0 +1,278 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// testStores opens an empty store of each backend
+var testStores = map[string]func(t *testing.T) Store{
+	"memory": func(t *testing.T) Store { return NewMemoryStore() },
+	"sqlite": func(t *testing.T) Store {
+		st, err := OpenSQLite("file:" + filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { st.Close() })
+		return st
+	},
+}
+
+// matchDecisions reads the decisions of a migration, ordered by position.
+// Store has no reader for them, so each backend is read directly.
+func matchDecisions(t *testing.T, st Store, migrationID string) []MatchDecision {
+	t.Helper()
+
+	var decisions []MatchDecision
+	switch st := st.(type) {
+	case *MemoryStore:
+		st.mu.RLock()
+		for _, decision := range st.decisions[migrationID] {
+			decisions = append(decisions, decision)
+		}
+		st.mu.RUnlock()
+		sort.Slice(decisions, func(i, j int) bool { return decisions[i].Position < decisions[j].Position })
+	case *SQLiteStore:
+		rows, err := st.db.Query(`
+			SELECT migration_id, position, source_id, destination_id, action, confidence, decided_at
+			FROM match_decisions WHERE migration_id = ? ORDER BY position`, migrationID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var d MatchDecision
+			if err := rows.Scan(&d.MigrationID, &d.Position, &d.SourceID, &d.DestinationID, &d.Action, &d.Confidence, &d.DecidedAt); err != nil {
+				t.Fatal(err)
+			}
+			decisions = append(decisions, d)
+		}
+	default:
+		t.Fatalf("unknown store %T", st)
+	}
+	return decisions
+}
+
+// auditEntries reads the audit trail in insertion order
+func auditEntries(t *testing.T, st Store) []AuditEntry {
+	t.Helper()
+
+	var entries []AuditEntry
+	switch st := st.(type) {
+	case *MemoryStore:
+		st.mu.RLock()
+		entries = append(entries, st.audit...)
+		st.mu.RUnlock()
+	case *SQLiteStore:
+		rows, err := st.db.Query(`SELECT id, time, actor, action, target, details FROM audit_entries ORDER BY id`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var e AuditEntry
+			if err := rows.Scan(&e.ID, &e.Time, &e.Actor, &e.Action, &e.Target, &e.Details); err != nil {
+				t.Fatal(err)
+			}
+			entries = append(entries, e)
+		}
+	default:
+		t.Fatalf("unknown store %T", st)
+	}
+	return entries
+}
+
+func TestStore(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, t *testing.T, st Store)
+	}{
+		{
+			name: "OAuth states",
+			run: func(ctx context.Context, t *testing.T, st Store) {
+				now := time.Now().Truncate(time.Second)
+				expired := &OAuthState{ID: "expired", Provider: ProviderYouTube, CodeVerifier: "v1", BrowserHash: "b1", ExpiresAt: now.Add(-time.Minute)}
+				pending := &OAuthState{ID: "pending", Provider: ProviderSpotify, CodeVerifier: "v2", BrowserHash: "b2", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+				// Saving a state drops the ones that have expired
+				for _, state := range []*OAuthState{expired, pending} {
+					if err := st.SaveOAuthState(ctx, state); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if _, err := st.ConsumeOAuthState(ctx, "expired"); !errors.Is(err, ErrNotFound) {
+					t.Errorf("expired state: error = %v, want ErrNotFound", err)
+				}
+
+				got, err := st.ConsumeOAuthState(ctx, "pending")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Provider != ProviderSpotify || got.CodeVerifier != "v2" || got.BrowserHash != "b2" ||
+					!got.CreatedAt.Equal(now) || !got.ExpiresAt.Equal(pending.ExpiresAt) {
+					t.Errorf("state = %+v, want %+v", got, pending)
+				}
+				if _, err := st.ConsumeOAuthState(ctx, "pending"); !errors.Is(err, ErrNotFound) {
+					t.Errorf("second consume: error = %v, want ErrNotFound", err)
+				}
+			},
+		},
+		{
+			name: "snapshots",
+			run: func(ctx context.Context, t *testing.T, st Store) {
+				if _, err := st.GetSnapshot(ctx, SnapshotPlaylist, "PL1"); !errors.Is(err, ErrNotFound) {
+					t.Fatalf("missing snapshot: error = %v, want ErrNotFound", err)
+				}
+
+				if err := st.SaveSnapshot(ctx, &Snapshot{Kind: SnapshotPlaylist, ID: "PL1", Data: []byte(`{"title":"Road trip"}`)}); err != nil {
+					t.Fatal(err)
+				}
+				first, err := st.GetSnapshot(ctx, SnapshotPlaylist, "PL1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if first.Kind != SnapshotPlaylist || first.ID != "PL1" || string(first.Data) != `{"title":"Road trip"}` || first.CreatedAt.IsZero() {
+					t.Errorf("snapshot = %+v", first)
+				}
+
+				// Saving again replaces the data but keeps the creation time
+				if err := st.SaveSnapshot(ctx, &Snapshot{Kind: SnapshotPlaylist, ID: "PL1", Data: []byte(`{"title":"Renamed"}`)}); err != nil {
+					t.Fatal(err)
+				}
+				second, err := st.GetSnapshot(ctx, SnapshotPlaylist, "PL1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(second.Data) != `{"title":"Renamed"}` || !second.CreatedAt.Equal(first.CreatedAt) || second.UpdatedAt.Before(first.UpdatedAt) {
+					t.Errorf("replaced snapshot = %+v, first saved %v", second, first.CreatedAt)
+				}
+
+				// The kind is part of the key
+				if _, err := st.GetSnapshot(ctx, SnapshotMigration, "PL1"); !errors.Is(err, ErrNotFound) {
+					t.Errorf("other kind: error = %v, want ErrNotFound", err)
+				}
+			},
+		},
+		{
+			name: "match decisions",
+			run: func(ctx context.Context, t *testing.T, st Store) {
+				decided := time.Now().Add(-time.Minute).Truncate(time.Second)
+				decisions := []MatchDecision{
+					{MigrationID: "m1", Position: 1, SourceID: "video-1", Action: "skip", DecidedAt: decided},
+					{MigrationID: "m1", Position: 0, SourceID: "video-0", DestinationID: "track-0", Action: "accept", Confidence: 0.7, DecidedAt: decided},
+					{MigrationID: "m2", Position: 0, SourceID: "video-0", DestinationID: "other", Action: "manual", DecidedAt: decided},
+					// A later decision on the same track replaces the first
+					{MigrationID: "m1", Position: 1, SourceID: "video-1", DestinationID: "track-1", Action: "manual", DecidedAt: decided.Add(time.Second)},
+				}
+				for i := range decisions {
+					if err := st.SaveMatchDecision(ctx, &decisions[i]); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				got := matchDecisions(t, st, "m1")
+				if len(got) != 2 {
+					t.Fatalf("decisions = %+v, want 2", got)
+				}
+				want := []MatchDecision{decisions[1], decisions[3]}
+				for i := range want {
+					if got[i].Position != want[i].Position || got[i].SourceID != want[i].SourceID || got[i].DestinationID != want[i].DestinationID ||
+						got[i].Action != want[i].Action || got[i].Confidence != want[i].Confidence || !got[i].DecidedAt.Equal(want[i].DecidedAt) {
+						t.Errorf("decision %d = %+v, want %+v", i, got[i], want[i])
+					}
+				}
+			},
+		},
+		{
+			name: "audit entries",
+			run: func(ctx context.Context, t *testing.T, st Store) {
+				at := time.Now().Add(-time.Hour).Truncate(time.Second)
+				entries := []*AuditEntry{
+					{Time: at, Actor: "user-1", Action: "migration.created", Target: "m1", Details: "youtube playlist PL1"},
+					{Action: "migration.completed", Target: "m1"},
+				}
+				for i, entry := range entries {
+					if err := st.AddAuditEntry(ctx, entry); err != nil {
+						t.Fatal(err)
+					}
+					if entry.ID != int64(i+1) {
+						t.Errorf("entry %d: ID = %d", i, entry.ID)
+					}
+				}
+				if entries[1].Time.IsZero() {
+					t.Error("entry without a time was not stamped")
+				}
+
+				got := auditEntries(t, st)
+				if len(got) != 2 {
+					t.Fatalf("entries = %+v, want 2", got)
+				}
+				for i, want := range entries {
+					if got[i].ID != want.ID || !got[i].Time.Equal(want.Time) || got[i].Actor != want.Actor ||
+						got[i].Action != want.Action || got[i].Target != want.Target || got[i].Details != want.Details {
+						t.Errorf("entry %d = %+v, want %+v", i, got[i], *want)
+					}
+				}
+			},
+		},
+	}
+
+	for name, open := range testStores {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				tt.run(context.Background(), t, open(t))
+			})
+		}
+	}
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+	// Version 0 is a fresh database; the last one is already up to date
+	for version := 0; version <= len(schemaMigrations); version++ {
+		t.Run(fmt.Sprintf("from version %d", version), func(t *testing.T) {
+			ctx := context.Background()
+			dsn := "file:" + filepath.Join(t.TempDir(), "test.db")
+
+			db, err := sql.Open("sqlite", dsn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := migrateTo(ctx, db, version); err != nil {
+				t.Fatal(err)
+			}
+			if version > 0 {
+				// Records written by the older schema must survive the upgrade
+				old := &SQLiteStore{db: db}
+				if err := old.SaveSnapshot(ctx, &Snapshot{Kind: SnapshotPlaylist, ID: "PL1", Data: []byte(`{}`)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			db.Close()
+
+			st, err := OpenSQLite(dsn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer st.Close()
+
+			var current, applied int
+			if err := st.db.QueryRow(`SELECT MAX(version), COUNT(*) FROM schema_migrations`).Scan(&current, &applied); err != nil {
+				t.Fatal(err)
+			}
+			if current != len(schemaMigrations) || applied != len(schemaMigrations) {
+				t.Errorf("schema version %d with %d migrations applied, want %d", current, applied, len(schemaMigrations))
+			}
+			if version > 0 {
+				if _, err := st.GetSnapshot(ctx, SnapshotPlaylist, "PL1"); err != nil {
+					t.Errorf("snapshot saved before the upgrade: %v", err)
+				}
+			}
+		})
+	}
+}
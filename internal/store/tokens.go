@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alejpaa/playlist-migration-tool/pkg/auth"
+	"golang.org/x/oauth2"
+)
+
+// OAuth providers
+const (
+	ProviderYouTube = "youtube"
+	ProviderSpotify = "spotify"
+)
+
+// TokenStore adapts a Store to auth.TokenStore for the tokens of one provider
+type TokenStore struct {
+	store    Store
+	provider string
+	owned    bool // Close closes store
+}
+
+var _ auth.TokenStore = (*TokenStore)(nil)
+
+// NewTokenStore creates a TokenStore backed by st
+func NewTokenStore(st Store, provider string) *TokenStore {
+	return &TokenStore{store: st, provider: provider}
+}
+
+// OpenTokenStore opens the YouTube token store at location, the TOKEN_FILE
+// setting: a database DSN ("file:...", "memory") or a directory holding one
+// file per user. A DSN equal to databaseDSN reuses st instead of opening the
+// database twice.
+func OpenTokenStore(location, databaseDSN string, st Store) (auth.TokenStore, error) {
+	if location == databaseDSN {
+		return NewTokenStore(st, ProviderYouTube), nil
+	}
+	if !IsDSN(location) {
+		if info, err := os.Stat(location); err == nil && !info.IsDir() {
+			return nil, fmt.Errorf("TOKEN_FILE %s is a single token file; set it to a directory or a database DSN", location)
+		}
+		return auth.NewFileTokenStore(location)
+	}
+
+	owned, err := Open(location)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenStore{store: owned, provider: ProviderYouTube, owned: true}, nil
+}
+
+// IsDSN reports whether location names a database rather than a directory
+func IsDSN(location string) bool {
+	return strings.HasPrefix(location, "file:") || strings.EqualFold(location, "memory")
+}
+
+// Load returns a user's token, with the scopes it was granted when known
+func (s *TokenStore) Load(ctx context.Context, userID string) (*oauth2.Token, error) {
+	token, err := s.store.GetToken(ctx, userID, s.provider)
+	if errors.Is(err, ErrNotFound) {
+		return nil, auth.ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tok := &oauth2.Token{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		TokenType:    token.TokenType,
+		Expiry:       token.Expiry,
+	}
+	if len(token.Scopes) > 0 {
+		// Where the token endpoint reports them, as Save expects
+		tok = tok.WithExtra(map[string]interface{}{"scope": strings.Join(token.Scopes, " ")})
+	}
+	return tok, nil
+}
+
+// Save stores a user's token along with the scopes it was granted, when known
+func (s *TokenStore) Save(ctx context.Context, userID string, token *oauth2.Token) error {
+	record := &Token{
+		UserID:       userID,
+		Provider:     s.provider,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		TokenType:    token.TokenType,
+		Expiry:       token.Expiry,
+	}
+	if scope, ok := token.Extra("scope").(string); ok {
+		record.Scopes = strings.Fields(scope)
+	}
+	return s.store.SaveToken(ctx, record)
+}
+
+// Delete removes a user's token
+func (s *TokenStore) Delete(ctx context.Context, userID string) error {
+	err := s.store.DeleteToken(ctx, userID, s.provider)
+	if errors.Is(err, ErrNotFound) {
+		return auth.ErrTokenNotFound
+	}
+	return err
+}
+
+// Close closes the underlying store when OpenTokenStore opened it
+func (s *TokenStore) Close() error {
+	if !s.owned {
+		return nil
+	}
+	return s.store.Close()
+}
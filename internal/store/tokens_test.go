@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/pkg/auth"
+	"golang.org/x/oauth2"
+)
+
+func TestTokenStoreKeepsRefreshToken(t *testing.T) {
+	stores := map[string]func(t *testing.T) auth.TokenStore{
+		"memory": func(t *testing.T) auth.TokenStore {
+			return NewTokenStore(NewMemoryStore(), ProviderYouTube)
+		},
+		"sqlite": func(t *testing.T) auth.TokenStore {
+			st, err := OpenSQLite("file:" + filepath.Join(t.TempDir(), "test.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { st.Close() })
+			return NewTokenStore(st, ProviderYouTube)
+		},
+		"file": func(t *testing.T) auth.TokenStore {
+			st, err := auth.NewFileTokenStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			return st
+		},
+	}
+
+	tests := []struct {
+		name        string
+		second      string // refresh token of the second sign-in
+		wantRefresh string
+	}{
+		{name: "consent skipped", second: "", wantRefresh: "first-refresh"},
+		{name: "consent given again", second: "second-refresh", wantRefresh: "second-refresh"},
+	}
+
+	for storeName, open := range stores {
+		for _, tt := range tests {
+			t.Run(storeName+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				tokens := open(t)
+				expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+
+				if err := tokens.Save(ctx, "UC1", &oauth2.Token{AccessToken: "a1", RefreshToken: "first-refresh", Expiry: expiry}); err != nil {
+					t.Fatal(err)
+				}
+				if err := tokens.Save(ctx, "UC1", &oauth2.Token{AccessToken: "a2", RefreshToken: tt.second, Expiry: expiry}); err != nil {
+					t.Fatal(err)
+				}
+
+				tok, err := tokens.Load(ctx, "UC1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tok.AccessToken != "a2" {
+					t.Errorf("access token = %q, want a2", tok.AccessToken)
+				}
+				if tok.RefreshToken != tt.wantRefresh {
+					t.Errorf("refresh token = %q, want %q", tok.RefreshToken, tt.wantRefresh)
+				}
+			})
+		}
+	}
+}
+
+func TestTokenStoreKeepsScopes(t *testing.T) {
+	ctx := context.Background()
+	tokens := NewTokenStore(NewMemoryStore(), ProviderYouTube)
+
+	scope := "https://www.googleapis.com/auth/youtube.readonly openid"
+	tok := (&oauth2.Token{AccessToken: "a1"}).WithExtra(map[string]interface{}{"scope": scope})
+	if err := tokens.Save(ctx, "UC1", tok); err != nil {
+		t.Fatal(err)
+	}
+	if err := tokens.Save(ctx, "UC2", &oauth2.Token{AccessToken: "a2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := tokens.Load(ctx, "UC1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Extra("scope"); got != scope {
+		t.Errorf("scope = %v, want %q", got, scope)
+	}
+
+	// Tokens saved without scopes come back without them
+	loaded, err = tokens.Load(ctx, "UC2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Extra("scope"); got != nil {
+		t.Errorf("scope = %v, want none", got)
+	}
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultRevokeURL is Google's endpoint for revoking tokens
+const DefaultRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+// RevokeToken revokes an access or refresh token at revokeURL, or
+// DefaultRevokeURL when empty. Revoking either one ends the whole grant: the
+// refresh token and every access token issued with it stop working. A token
+// Google no longer recognises is already revoked, so it is not an error.
+func RevokeToken(ctx context.Context, client *http.Client, revokeURL, token string) error {
+	if revokeURL == "" {
+		revokeURL = DefaultRevokeURL
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, revokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("unable to create revoke request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to reach revoke endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var data struct {
+		Error string `json:"error"`
+	}
+	if resp.StatusCode == http.StatusBadRequest && json.Unmarshal(body, &data) == nil && data.Error == "invalid_token" {
+		return nil
+	}
+	return fmt.Errorf("revoke endpoint returned status %d", resp.StatusCode)
+}
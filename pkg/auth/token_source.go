@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// NewTokenSource returns a TokenSource for userID that starts with tok,
+// refreshes it with config when it expires and saves every new token to
+// tokens, so a rotated refresh token is never lost. The returned source is
+// safe for concurrent use and refreshes at most once per expiry.
+func NewTokenSource(config *oauth2.Config, tokens TokenStore, userID string, tok *oauth2.Token) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(tok, &persistingTokenSource{
+		base:   config.TokenSource(context.Background(), tok),
+		tokens: tokens,
+		userID: userID,
+		last:   tok.AccessToken,
+	})
+}
+
+// persistingTokenSource saves the tokens returned by base when they change
+type persistingTokenSource struct {
+	base   oauth2.TokenSource
+	tokens TokenStore
+	userID string
+
+	mu   sync.Mutex
+	last string // Access token saved most recently
+}
+
+// Token implements oauth2.TokenSource
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := s.base.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tok.AccessToken != s.last {
+		// The new token is still usable when saving fails, so only log it
+		if err := s.tokens.Save(context.Background(), s.userID, tok); err != nil {
+			log.Printf("Failed to save refreshed token for %s: %v", s.userID, err)
+		} else {
+			s.last = tok.AccessToken
+		}
+	}
+	return tok, nil
+}
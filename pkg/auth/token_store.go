@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrTokenNotFound is returned by a TokenStore when a user has no saved token
+var ErrTokenNotFound = errors.New("token not found")
+
+// TokenStore persists OAuth tokens keyed by user ID, which is the user's
+// YouTube channel ID. Implementations must be safe for concurrent use.
+// Google only returns a refresh token on first consent, so Save must keep
+// the stored refresh token when the new token has none.
+type TokenStore interface {
+	Load(ctx context.Context, userID string) (*oauth2.Token, error)
+	Save(ctx context.Context, userID string, token *oauth2.Token) error
+	Delete(ctx context.Context, userID string) error
+}
+
+// userIDPattern matches the user IDs accepted by FileTokenStore. Channel IDs
+// only use these characters, and rejecting the rest keeps every file inside
+// the directory.
+var userIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// FileTokenStore keeps each user's token in its own JSON file in a directory
+type FileTokenStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// NewFileTokenStore creates a FileTokenStore, creating dir if it does not exist
+func NewFileTokenStore(dir string) (*FileTokenStore, error) {
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		return nil, fmt.Errorf("token store %s is a file, not a directory", dir)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("unable to create token directory: %w", err)
+	}
+	return &FileTokenStore{dir: dir}, nil
+}
+
+// Load reads a user's token
+func (s *FileTokenStore) Load(ctx context.Context, userID string) (*oauth2.Token, error) {
+	path, err := s.path(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tok, err := tokenFromFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read token: %w", err)
+	}
+	return tok, nil
+}
+
+// Save writes a user's token, keeping the saved refresh token when token has
+// none. The file is replaced atomically so concurrent readers never see a
+// half-written token.
+func (s *FileTokenStore) Save(ctx context.Context, userID string, token *oauth2.Token) error {
+	path, err := s.path(userID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token.RefreshToken == "" {
+		if saved, err := tokenFromFile(path); err == nil && saved.RefreshToken != "" {
+			merged := *token
+			merged.RefreshToken = saved.RefreshToken
+			token = &merged
+		}
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("unable to encode token: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, userID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("unable to save token: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to save token: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to save token: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("unable to save token: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a user's token
+func (s *FileTokenStore) Delete(ctx context.Context, userID string) error {
+	path, err := s.path(userID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrTokenNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("unable to delete token: %w", err)
+	}
+	return nil
+}
+
+// path returns the file of a user's token
+func (s *FileTokenStore) path(userID string) (string, error) {
+	if !userIDPattern.MatchString(userID) {
+		return "", fmt.Errorf("invalid user ID %q", userID)
+	}
+	return filepath.Join(s.dir, userID+".json"), nil
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func newTestFileTokenStore(t *testing.T) (*FileTokenStore, string) {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "tokens")
+	tokens, err := NewFileTokenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens, dir
+}
+
+func TestFileTokenStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	tokens, dir := newTestFileTokenStore(t)
+
+	if _, err := tokens.Load(ctx, "UC1"); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("Load before Save: %v, want ErrTokenNotFound", err)
+	}
+
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	for _, user := range []string{"UC1", "UC2"} {
+		tok := &oauth2.Token{AccessToken: "access-" + user, RefreshToken: "refresh-" + user, Expiry: expiry}
+		if err := tokens.Save(ctx, user, tok); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each user has their own file, readable only by the owner
+	for _, user := range []string{"UC1", "UC2"} {
+		tok, err := tokens.Load(ctx, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.AccessToken != "access-"+user || tok.RefreshToken != "refresh-"+user || !tok.Expiry.Equal(expiry) {
+			t.Errorf("token of %s = %+v", user, tok)
+		}
+
+		info, err := os.Stat(filepath.Join(dir, user+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm&0o077 != 0 {
+			t.Errorf("token file of %s has mode %v, want no group or other access", user, perm)
+		}
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("directory has %d entries, want 2", len(entries))
+	}
+
+	if err := tokens.Delete(ctx, "UC1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.Load(ctx, "UC1"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Load after Delete: %v, want ErrTokenNotFound", err)
+	}
+	if err := tokens.Delete(ctx, "UC1"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("second Delete: %v, want ErrTokenNotFound", err)
+	}
+	if _, err := tokens.Load(ctx, "UC2"); err != nil {
+		t.Errorf("other user's token: %v", err)
+	}
+}
+
+func TestFileTokenStoreRejectsUnsafeUserIDs(t *testing.T) {
+	ctx := context.Background()
+	tokens, dir := newTestFileTokenStore(t)
+	tok := &oauth2.Token{AccessToken: "access"}
+
+	for _, userID := range []string{"", "../UC1", "UC1/../../x", "/etc/passwd", "UC1.json", "UC 1"} {
+		if err := tokens.Save(ctx, userID, tok); err == nil {
+			t.Errorf("Save(%q) succeeded, want an error", userID)
+		}
+		if _, err := tokens.Load(ctx, userID); err == nil || errors.Is(err, ErrTokenNotFound) {
+			t.Errorf("Load(%q) = %v, want an invalid user ID error", userID, err)
+		}
+		if err := tokens.Delete(ctx, userID); err == nil || errors.Is(err, ErrTokenNotFound) {
+			t.Errorf("Delete(%q) = %v, want an invalid user ID error", userID, err)
+		}
+	}
+
+	if entries, _ := os.ReadDir(filepath.Dir(dir)); len(entries) != 1 {
+		t.Errorf("files written outside the token directory: %v", entries)
+	}
+}
+
+func TestNewFileTokenStoreOnAFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token.json")
+	if err := os.WriteFile(file, []byte(`{}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewFileTokenStore(file); err == nil {
+		t.Error("expected an error for a path that is a file")
+	}
+}
+
+func TestFileTokenStoreConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	tokens, _ := newTestFileTokenStore(t)
+	if err := tokens.Save(ctx, "UC1", &oauth2.Token{AccessToken: "access-0", RefreshToken: "refresh"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			// Refreshed tokens come without a refresh token
+			errs <- tokens.Save(ctx, "UC1", &oauth2.Token{AccessToken: fmt.Sprintf("access-%d", i)})
+		}(i)
+		go func() {
+			defer wg.Done()
+			// Readers never see a half-written file
+			tok, err := tokens.Load(ctx, "UC1")
+			if err == nil && tok.RefreshToken != "refresh" {
+				err = fmt.Errorf("refresh token = %q, want refresh", tok.RefreshToken)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTokenInfoURL is Google's endpoint for inspecting access tokens
+const DefaultTokenInfoURL = "https://oauth2.googleapis.com/tokeninfo"
+
+// DefaultTokenCacheTTL is how long a tokeninfo answer is reused
+const DefaultTokenCacheTTL = 5 * time.Minute
+
+// DefaultRejectedTokenCacheTTL is how long a rejection is reused. It is kept
+// short because anyone can send made-up tokens.
+const DefaultRejectedTokenCacheTTL = 15 * time.Second
+
+// DefaultTokenCacheSize is how many answers are cached at most
+const DefaultTokenCacheSize = 10000
+
+// YouTubeReadScopes are the scopes that allow reading the user's playlists.
+// A token must carry at least one of them.
+var YouTubeReadScopes = []string{
+	"https://www.googleapis.com/auth/youtube.readonly",
+	"https://www.googleapis.com/auth/youtube",
+	"https://www.googleapis.com/auth/youtube.force-ssl",
+}
+
+// Errors returned by TokenValidator.Validate
+var (
+	// ErrInvalidToken means Google does not recognise the token, or it expired
+	ErrInvalidToken = errors.New("invalid or expired access token")
+	// ErrWrongAudience means the token was issued to another OAuth client
+	ErrWrongAudience = errors.New("access token was issued to another application")
+	// ErrMissingScope means the token does not grant a required scope
+	ErrMissingScope = errors.New("access token is missing a required scope")
+)
+
+// TokenInfo is what Google reports about an access token
+type TokenInfo struct {
+	Audience        string
+	AuthorizedParty string
+	Subject         string
+	Email           string
+	Scopes          []string
+	Expiry          time.Time
+}
+
+// HasScope reports whether the token grants scope
+func (i *TokenInfo) HasScope(scope string) bool {
+	for _, s := range i.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenInfoResponse is the JSON body returned by the tokeninfo endpoint
+type tokenInfoResponse struct {
+	Aud       string `json:"aud"`
+	Azp       string `json:"azp"`
+	Sub       string `json:"sub"`
+	Email     string `json:"email"`
+	Scope     string `json:"scope"`
+	Exp       string `json:"exp"`
+	ExpiresIn string `json:"expires_in"`
+}
+
+// TokenValidatorConfig configures a TokenValidator
+type TokenValidatorConfig struct {
+	// URL of the tokeninfo endpoint; DefaultTokenInfoURL when empty
+	URL string
+	// ClientID the token must be issued to; the audience is not checked when empty
+	ClientID string
+	// RequiredScopes must all be granted, on top of one of YouTubeReadScopes
+	RequiredScopes []string
+	// CacheTTL bounds how long an answer is reused; DefaultTokenCacheTTL when zero
+	CacheTTL time.Duration
+	// RejectedCacheTTL bounds how long a rejection is reused, up to CacheTTL;
+	// DefaultRejectedTokenCacheTTL when zero
+	RejectedCacheTTL time.Duration
+	// CacheSize caps the number of cached answers; DefaultTokenCacheSize when zero
+	CacheSize int
+	// HTTPClient used to call the endpoint; http.DefaultClient when nil
+	HTTPClient *http.Client
+}
+
+// TokenValidator checks access tokens against Google's tokeninfo endpoint.
+// Answers are cached by token hash, so each token costs one lookup per TTL.
+type TokenValidator struct {
+	config TokenValidatorConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	cache     map[string]cachedTokenInfo
+	nextPrune time.Time // Earliest time a full cache is scanned for expired entries
+}
+
+// cachedTokenInfo is a cached answer: the token info or the validation error
+type cachedTokenInfo struct {
+	info    *TokenInfo
+	err     error
+	expires time.Time
+}
+
+// NewTokenValidator creates a TokenValidator
+func NewTokenValidator(config TokenValidatorConfig) *TokenValidator {
+	if config.URL == "" {
+		config.URL = DefaultTokenInfoURL
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = DefaultTokenCacheTTL
+	}
+	if config.RejectedCacheTTL <= 0 {
+		config.RejectedCacheTTL = DefaultRejectedTokenCacheTTL
+	}
+	if config.RejectedCacheTTL > config.CacheTTL {
+		config.RejectedCacheTTL = config.CacheTTL
+	}
+	if config.CacheSize <= 0 {
+		config.CacheSize = DefaultTokenCacheSize
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	return &TokenValidator{
+		config: config,
+		now:    time.Now,
+		cache:  make(map[string]cachedTokenInfo),
+	}
+}
+
+// Validate returns the info of a valid access token. It fails with
+// ErrInvalidToken, ErrWrongAudience or ErrMissingScope when the token is
+// rejected, or another error when Google could not be reached.
+func (v *TokenValidator) Validate(ctx context.Context, accessToken string) (*TokenInfo, error) {
+	key := tokenHash(accessToken)
+	now := v.now()
+
+	v.mu.Lock()
+	cached, ok := v.cache[key]
+	v.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.info, cached.err
+	}
+
+	info, err := v.lookup(ctx, accessToken)
+	if err == nil {
+		err = v.check(info)
+	} else if !errors.Is(err, ErrInvalidToken) {
+		// Do not cache transient failures
+		return nil, err
+	}
+
+	ttl := v.config.CacheTTL
+	if err != nil {
+		ttl = v.config.RejectedCacheTTL
+	}
+	expires := now.Add(ttl)
+	if info != nil && !info.Expiry.IsZero() && info.Expiry.Before(expires) {
+		expires = info.Expiry
+	}
+
+	v.mu.Lock()
+	v.makeRoom(now)
+	v.cache[key] = cachedTokenInfo{info: info, err: err, expires: expires}
+	v.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Forget drops the cached answer for a token, so a revoked token is not
+// accepted until its cache entry expires
+func (v *TokenValidator) Forget(accessToken string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.cache, tokenHash(accessToken))
+}
+
+// lookup asks the tokeninfo endpoint about a token
+func (v *TokenValidator) lookup(ctx context.Context, accessToken string) (*TokenInfo, error) {
+	// The token goes in the body, so it does not end up in URL logs
+	form := url.Values{"access_token": {accessToken}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.config.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create tokeninfo request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach tokeninfo: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read tokeninfo response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized:
+		return nil, ErrInvalidToken
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("tokeninfo returned status %d", resp.StatusCode)
+	}
+
+	var data tokenInfoResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("unable to parse tokeninfo response: %w", err)
+	}
+
+	info := &TokenInfo{
+		Audience:        data.Aud,
+		AuthorizedParty: data.Azp,
+		Subject:         data.Sub,
+		Email:           data.Email,
+		Scopes:          strings.Fields(data.Scope),
+	}
+	if exp, err := strconv.ParseInt(data.Exp, 10, 64); err == nil {
+		info.Expiry = time.Unix(exp, 0)
+	} else if expiresIn, err := strconv.Atoi(data.ExpiresIn); err == nil {
+		info.Expiry = v.now().Add(time.Duration(expiresIn) * time.Second)
+	}
+	return info, nil
+}
+
+// check verifies the audience and scopes of a token
+func (v *TokenValidator) check(info *TokenInfo) error {
+	if !info.Expiry.IsZero() && !v.now().Before(info.Expiry) {
+		return ErrInvalidToken
+	}
+	if v.config.ClientID != "" && info.Audience != v.config.ClientID && info.AuthorizedParty != v.config.ClientID {
+		return ErrWrongAudience
+	}
+
+	readable := false
+	for _, scope := range YouTubeReadScopes {
+		if info.HasScope(scope) {
+			readable = true
+			break
+		}
+	}
+	if !readable {
+		return ErrMissingScope
+	}
+	for _, scope := range v.config.RequiredScopes {
+		if !info.HasScope(scope) {
+			return ErrMissingScope
+		}
+	}
+	return nil
+}
+
+// makeRoom frees a slot when the cache is full. Expired entries are dropped
+// at most once per rejected TTL, so a flood of new tokens does not scan the
+// whole cache on every lookup; otherwise an arbitrary entry is evicted. The
+// caller must hold the lock.
+func (v *TokenValidator) makeRoom(now time.Time) {
+	if len(v.cache) < v.config.CacheSize {
+		return
+	}
+
+	if !now.Before(v.nextPrune) {
+		for key, cached := range v.cache {
+			if !now.Before(cached.expires) {
+				delete(v.cache, key)
+			}
+		}
+		v.nextPrune = now.Add(v.config.RejectedCacheTTL)
+	}
+
+	// Map iteration starts at a random entry
+	for key := range v.cache {
+		if len(v.cache) < v.config.CacheSize {
+			break
+		}
+		delete(v.cache, key)
+	}
+}
+
+// tokenHash returns the cache key of a token, so raw tokens are not kept in memory
+func tokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return hex.EncodeToString(sum[:])
+}
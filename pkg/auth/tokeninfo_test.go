@@ -0,0 +1,319 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testClientID  = "client-id.apps.googleusercontent.com"
+	readonlyScope = "https://www.googleapis.com/auth/youtube.readonly"
+)
+
+// tokenInfoServer is a fake tokeninfo endpoint. Unknown tokens get a 400,
+// like Google answers for invalid or expired tokens.
+type tokenInfoServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	tokens   map[string]tokenInfoResponse
+	failures int // Next requests answered with a 500
+	requests int
+}
+
+func newTokenInfoServer(t *testing.T) *tokenInfoServer {
+	s := &tokenInfoServer{tokens: make(map[string]tokenInfoResponse)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests++
+
+		if s.failures > 0 {
+			s.failures--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.RawQuery != "" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		info, ok := s.tokens[r.PostFormValue("access_token")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_token","error_description":"Invalid Value"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(info)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *tokenInfoServer) add(token string, info tokenInfoResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[token] = info
+}
+
+func (s *tokenInfoServer) remove(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, token)
+}
+
+func (s *tokenInfoServer) fail(times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = times
+}
+
+func (s *tokenInfoServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// newTestValidator creates a validator against server whose clock is *now
+func newTestValidator(server *tokenInfoServer, now *time.Time, required ...string) *TokenValidator {
+	v := NewTokenValidator(TokenValidatorConfig{
+		URL:            server.URL,
+		ClientID:       testClientID,
+		RequiredScopes: required,
+		CacheTTL:       time.Minute,
+	})
+	v.now = func() time.Time { return *now }
+	return v
+}
+
+// validInfo is the tokeninfo answer for a read-only token that expires at exp
+func validInfo(exp time.Time) tokenInfoResponse {
+	return tokenInfoResponse{
+		Aud:   testClientID,
+		Azp:   testClientID,
+		Sub:   "108",
+		Email: "user@example.com",
+		Scope: "openid " + readonlyScope,
+		Exp:   strconv.FormatInt(exp.Unix(), 10),
+	}
+}
+
+func TestTokenValidatorValidate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	in := func(d time.Duration) time.Time { return now.Add(d) }
+
+	tests := []struct {
+		name     string
+		info     *tokenInfoResponse // nil for a token Google does not know
+		required []string
+		wantErr  error
+	}{
+		{name: "valid", info: ptr(validInfo(in(time.Hour)))},
+		{name: "unknown token", wantErr: ErrInvalidToken},
+		{
+			name:    "expired",
+			info:    ptr(validInfo(in(-time.Second))),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "expires_in instead of exp",
+			info: &tokenInfoResponse{Aud: testClientID, Scope: readonlyScope, ExpiresIn: "3599"},
+		},
+		{
+			name:    "wrong audience",
+			info:    &tokenInfoResponse{Aud: "other.apps.googleusercontent.com", Azp: "other.apps.googleusercontent.com", Scope: readonlyScope, Exp: validInfo(in(time.Hour)).Exp},
+			wantErr: ErrWrongAudience,
+		},
+		{
+			name: "authorized party is enough",
+			info: &tokenInfoResponse{Aud: "other.apps.googleusercontent.com", Azp: testClientID, Scope: readonlyScope, Exp: validInfo(in(time.Hour)).Exp},
+		},
+		{
+			name:    "no YouTube scope",
+			info:    &tokenInfoResponse{Aud: testClientID, Scope: "openid email", Exp: validInfo(in(time.Hour)).Exp},
+			wantErr: ErrMissingScope,
+		},
+		{
+			name: "full YouTube scope",
+			info: &tokenInfoResponse{Aud: testClientID, Scope: "https://www.googleapis.com/auth/youtube", Exp: validInfo(in(time.Hour)).Exp},
+		},
+		{
+			name:     "missing required scope",
+			info:     ptr(validInfo(in(time.Hour))),
+			required: []string{"https://www.googleapis.com/auth/youtube"},
+			wantErr:  ErrMissingScope,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTokenInfoServer(t)
+			if tt.info != nil {
+				server.add("token", *tt.info)
+			}
+			v := newTestValidator(server, &now, tt.required...)
+
+			info, err := v.Validate(context.Background(), "token")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if info != nil {
+					t.Error("rejected token returned info")
+				}
+				return
+			}
+			if info.Audience != tt.info.Aud || !info.Expiry.After(now) {
+				t.Errorf("info = %+v", info)
+			}
+		})
+	}
+}
+
+func TestTokenValidatorCachesForTTL(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	server := newTokenInfoServer(t)
+	server.add("valid", validInfo(now.Add(time.Hour)))
+	server.add("foreign", tokenInfoResponse{Aud: "other", Scope: readonlyScope})
+	v := newTestValidator(server, &now)
+
+	for i := 0; i < 3; i++ {
+		if _, err := v.Validate(context.Background(), "valid"); err != nil {
+			t.Fatal(err)
+		}
+		// Rejections are cached as well
+		if _, err := v.Validate(context.Background(), "foreign"); !errors.Is(err, ErrWrongAudience) {
+			t.Fatalf("foreign token: %v", err)
+		}
+		if _, err := v.Validate(context.Background(), "unknown"); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("unknown token: %v", err)
+		}
+	}
+	if got := server.count(); got != 3 {
+		t.Fatalf("requests = %d, want one per token", got)
+	}
+
+	// Once the TTL passes, Google is asked again
+	now = now.Add(time.Minute)
+	if _, err := v.Validate(context.Background(), "valid"); err != nil {
+		t.Fatal(err)
+	}
+	if got := server.count(); got != 4 {
+		t.Errorf("requests after TTL = %d, want 4", got)
+	}
+	// The rejections expired long before
+	if _, err := v.Validate(context.Background(), "unknown"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("unknown token: %v", err)
+	}
+	if got := server.count(); got != 5 {
+		t.Errorf("requests after rejected TTL = %d, want 5", got)
+	}
+
+	// Forget drops the answer right away, for example after a revocation
+	v.Forget("valid")
+	server.remove("valid")
+	if _, err := v.Validate(context.Background(), "valid"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("forgotten token: %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestTokenValidatorRejectionsExpireFirst(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	server := newTokenInfoServer(t)
+	server.add("valid", validInfo(now.Add(time.Hour)))
+	v := newTestValidator(server, &now)
+
+	for _, token := range []string{"valid", "unknown"} {
+		v.Validate(context.Background(), token)
+	}
+
+	// A token the user was just granted is accepted soon after a rejection
+	now = now.Add(DefaultRejectedTokenCacheTTL)
+	server.add("unknown", validInfo(now.Add(time.Hour)))
+	if _, err := v.Validate(context.Background(), "unknown"); err != nil {
+		t.Errorf("token after the rejected TTL: %v", err)
+	}
+	if _, err := v.Validate(context.Background(), "valid"); err != nil {
+		t.Fatal(err)
+	}
+	if got := server.count(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestTokenValidatorCacheSize(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	server := newTokenInfoServer(t)
+	v := NewTokenValidator(TokenValidatorConfig{URL: server.URL, CacheSize: 3})
+	v.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		if _, err := v.Validate(context.Background(), "garbage"+strconv.Itoa(i)); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("garbage token: %v", err)
+		}
+		if len(v.cache) > 3 {
+			t.Fatalf("cache holds %d answers, want at most 3", len(v.cache))
+		}
+	}
+
+	// Once they expire, the rejections make room for new answers
+	now = now.Add(DefaultRejectedTokenCacheTTL)
+	server.add("valid", validInfo(now.Add(time.Hour)))
+	if _, err := v.Validate(context.Background(), "valid"); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.cache) != 1 {
+		t.Errorf("cache holds %d answers, want only the valid one", len(v.cache))
+	}
+}
+
+func TestTokenValidatorCacheEndsAtTokenExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	server := newTokenInfoServer(t)
+	server.add("token", validInfo(now.Add(10*time.Second)))
+	v := newTestValidator(server, &now)
+
+	if _, err := v.Validate(context.Background(), "token"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Well within the TTL, but the token itself has expired
+	now = now.Add(10 * time.Second)
+	if _, err := v.Validate(context.Background(), "token"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expired token: %v, want ErrInvalidToken", err)
+	}
+	if got := server.count(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestTokenValidatorDoesNotCacheTransientErrors(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	server := newTokenInfoServer(t)
+	server.add("token", validInfo(now.Add(time.Hour)))
+	server.fail(1)
+	v := newTestValidator(server, &now)
+
+	_, err := v.Validate(context.Background(), "token")
+	if err == nil || errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrWrongAudience) || errors.Is(err, ErrMissingScope) {
+		t.Fatalf("error = %v, want a transient error", err)
+	}
+
+	// The next call goes back to Google instead of repeating the failure
+	if _, err := v.Validate(context.Background(), "token"); err != nil {
+		t.Fatalf("after the outage: %v", err)
+	}
+	if got := server.count(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
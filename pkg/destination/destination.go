@@ -0,0 +1,70 @@
+// Package destination defines the interface implemented by the services a
+// playlist can be migrated to
+package destination
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInvalidTrackID is returned by ParseTrackID when a reference does not
+// name a track
+var ErrInvalidTrackID = errors.New("invalid track ID")
+
+// Track is a track in a destination catalogue
+type Track struct {
+	ID       string        `json:"id"`
+	URI      string        `json:"uri"`
+	Title    string        `json:"title"`
+	Artists  []string      `json:"artists"`
+	Album    string        `json:"album,omitempty"`
+	Duration time.Duration `json:"duration"`
+	ISRC     string        `json:"isrc,omitempty"`
+}
+
+// Playlist is a playlist created on a destination
+type Playlist struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// PlaylistParams holds the fields used to create a playlist
+type PlaylistParams struct {
+	Name        string
+	Description string
+	Public      bool
+}
+
+// Destination is a service playlists can be migrated to
+type Destination interface {
+	// Name returns the identifier used to select the destination (e.g. "spotify")
+	Name() string
+
+	// SearchTracks searches the catalogue and returns up to limit candidates
+	SearchTracks(ctx context.Context, query string, limit int) ([]Track, error)
+
+	// CreatePlaylist creates an empty playlist owned by the authenticated user
+	CreatePlaylist(ctx context.Context, params PlaylistParams) (*Playlist, error)
+
+	// AddTracks appends tracks, in order, to the end of a playlist
+	AddTracks(ctx context.Context, playlistID string, trackIDs []string) error
+
+	// PlaylistTrackIDs returns the IDs of the tracks in a playlist, in order
+	PlaylistTrackIDs(ctx context.Context, playlistID string) ([]string, error)
+
+	// ParseTrackID returns the ID of a track given by a user as an ID, URI or
+	// share URL. It wraps ErrInvalidTrackID when ref does not name a track.
+	ParseTrackID(ref string) (string, error)
+}
+
+// Factory builds a Destination authenticated with the given access token
+type Factory func(accessToken string) Destination
+
+// StatusError is implemented by provider errors that carry the HTTP status
+// code returned by the destination API
+type StatusError interface {
+	error
+	HTTPStatus() int
+}
@@ -0,0 +1,447 @@
+// Package spotify is a minimal client for the Spotify Web API that implements
+// destination.Destination
+package spotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/pkg/destination"
+)
+
+// DefaultBaseURL is the base URL of the Spotify Web API
+const DefaultBaseURL = "https://api.spotify.com/v1"
+
+// MaxTracksPerRequest is the maximum number of tracks accepted when adding
+// items to a playlist
+const MaxTracksPerRequest = 100
+
+// Defaults for requests rejected with 429 Too Many Requests: how many times
+// they are sent again, and the longest Retry-After the client waits for
+const (
+	DefaultRateLimitRetries = 3
+	DefaultMaxRetryAfter    = 30 * time.Second
+)
+
+// Client implements destination.Destination
+var _ destination.Destination = (*Client)(nil)
+
+// Client is a Spotify Web API client
+type Client struct {
+	accessToken string
+	baseURL     string
+	httpClient  *http.Client
+	userID      string
+
+	rateLimitRetries int
+	maxRetryAfter    time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithBaseURL changes the API base URL (e.g. to point at a local stub)
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if baseURL != "" {
+			c.baseURL = strings.TrimRight(baseURL, "/")
+		}
+	}
+}
+
+// WithHTTPClient uses the given http.Client for every request
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithRateLimitRetries changes how many times a request rejected with 429 is
+// sent again, and the longest Retry-After the client waits for. A longer
+// Retry-After, or a 429 after the last retry, is returned as an *APIError.
+func WithRateLimitRetries(retries int, maxRetryAfter time.Duration) Option {
+	return func(c *Client) {
+		if retries >= 0 {
+			c.rateLimitRetries = retries
+		}
+		if maxRetryAfter >= 0 {
+			c.maxRetryAfter = maxRetryAfter
+		}
+	}
+}
+
+// NewClient creates a new Spotify client
+func NewClient(accessToken string, opts ...Option) *Client {
+	c := &Client{
+		accessToken:      accessToken,
+		baseURL:          DefaultBaseURL,
+		httpClient:       &http.Client{Timeout: 30 * time.Second},
+		rateLimitRetries: DefaultRateLimitRetries,
+		maxRetryAfter:    DefaultMaxRetryAfter,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// APIError is an error returned by the Spotify Web API
+type APIError struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration // How long Spotify asked to wait, on 429 responses
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("spotify API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// HTTPStatus implements destination.StatusError
+func (e *APIError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+// User is a Spotify user profile
+type User struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+}
+
+type artist struct {
+	Name string `json:"name"`
+}
+
+type track struct {
+	ID         string   `json:"id"`
+	URI        string   `json:"uri"`
+	Name       string   `json:"name"`
+	DurationMS int      `json:"duration_ms"`
+	Artists    []artist `json:"artists"`
+	Album      struct {
+		Name string `json:"name"`
+	} `json:"album"`
+	ExternalIDs struct {
+		ISRC string `json:"isrc"`
+	} `json:"external_ids"`
+}
+
+type playlist struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	ExternalURLs struct {
+		Spotify string `json:"spotify"`
+	} `json:"external_urls"`
+}
+
+// Name implements destination.Destination
+func (c *Client) Name() string {
+	return "spotify"
+}
+
+// CurrentUser returns the profile of the authenticated user
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodGet, "/me", nil, nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SearchTracks implements destination.Destination
+func (c *Client) SearchTracks(ctx context.Context, query string, limit int) ([]destination.Track, error) {
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("type", "track")
+	params.Set("limit", strconv.Itoa(limit))
+
+	var response struct {
+		Tracks struct {
+			Items []track `json:"items"`
+		} `json:"tracks"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/search", params, nil, &response); err != nil {
+		return nil, err
+	}
+
+	tracks := make([]destination.Track, len(response.Tracks.Items))
+	for i, item := range response.Tracks.Items {
+		artists := make([]string, len(item.Artists))
+		for j, a := range item.Artists {
+			artists[j] = a.Name
+		}
+		tracks[i] = destination.Track{
+			ID:       item.ID,
+			URI:      item.URI,
+			Title:    item.Name,
+			Artists:  artists,
+			Album:    item.Album.Name,
+			Duration: time.Duration(item.DurationMS) * time.Millisecond,
+			ISRC:     item.ExternalIDs.ISRC,
+		}
+	}
+
+	return tracks, nil
+}
+
+// CreatePlaylist implements destination.Destination
+func (c *Client) CreatePlaylist(ctx context.Context, params destination.PlaylistParams) (*destination.Playlist, error) {
+	if c.userID == "" {
+		user, err := c.CurrentUser(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.userID = user.ID
+	}
+
+	body := map[string]interface{}{
+		"name":        params.Name,
+		"description": params.Description,
+		"public":      params.Public,
+	}
+
+	var created playlist
+	if err := c.do(ctx, http.MethodPost, "/users/"+url.PathEscape(c.userID)+"/playlists", nil, body, &created); err != nil {
+		return nil, err
+	}
+
+	return &destination.Playlist{
+		ID:   created.ID,
+		Name: created.Name,
+		URL:  created.ExternalURLs.Spotify,
+	}, nil
+}
+
+// AddTracks implements destination.Destination, sending at most 100 tracks per request
+func (c *Client) AddTracks(ctx context.Context, playlistID string, trackIDs []string) error {
+	for start := 0; start < len(trackIDs); start += MaxTracksPerRequest {
+		end := start + MaxTracksPerRequest
+		if end > len(trackIDs) {
+			end = len(trackIDs)
+		}
+
+		uris := make([]string, 0, end-start)
+		for _, id := range trackIDs[start:end] {
+			uris = append(uris, "spotify:track:"+id)
+		}
+
+		body := map[string]interface{}{"uris": uris}
+		if err := c.do(ctx, http.MethodPost, "/playlists/"+url.PathEscape(playlistID)+"/tracks", nil, body, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PlaylistTrackIDs implements destination.Destination, following pagination
+func (c *Client) PlaylistTrackIDs(ctx context.Context, playlistID string) ([]string, error) {
+	var ids []string
+	for offset := 0; ; {
+		params := url.Values{}
+		params.Set("fields", "items(track(id)),total")
+		params.Set("limit", strconv.Itoa(MaxTracksPerRequest))
+		params.Set("offset", strconv.Itoa(offset))
+
+		var page struct {
+			Items []struct {
+				Track *struct {
+					ID string `json:"id"`
+				} `json:"track"`
+			} `json:"items"`
+			Total int `json:"total"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/playlists/"+url.PathEscape(playlistID)+"/tracks", params, nil, &page); err != nil {
+			return nil, err
+		}
+
+		for _, item := range page.Items {
+			// Tracks removed from the catalogue come back as null
+			if item.Track != nil && item.Track.ID != "" {
+				ids = append(ids, item.Track.ID)
+			}
+		}
+
+		offset += len(page.Items)
+		if len(page.Items) == 0 || offset >= page.Total {
+			return ids, nil
+		}
+	}
+}
+
+// ParseTrackID implements destination.Destination. It accepts a track ID, a
+// spotify:track: URI or an open.spotify.com/track/ URL.
+func (c *Client) ParseTrackID(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	id := ref
+
+	switch {
+	case strings.HasPrefix(ref, "spotify:"):
+		parts := strings.Split(ref, ":")
+		if len(parts) != 3 || parts[1] != "track" {
+			return "", fmt.Errorf("%w: %q is not a track URI", destination.ErrInvalidTrackID, ref)
+		}
+		id = parts[2]
+	case strings.Contains(ref, "://"):
+		u, err := url.Parse(ref)
+		if err != nil || u.Host != "open.spotify.com" {
+			return "", fmt.Errorf("%w: %q is not a Spotify URL", destination.ErrInvalidTrackID, ref)
+		}
+		// Share links may carry a locale, e.g. /intl-es/track/ID
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(segments) > 0 && strings.HasPrefix(segments[0], "intl-") {
+			segments = segments[1:]
+		}
+		if len(segments) != 2 || segments[0] != "track" {
+			return "", fmt.Errorf("%w: %q is not a track URL", destination.ErrInvalidTrackID, ref)
+		}
+		id = segments[1]
+	}
+
+	if !validID(id) {
+		return "", fmt.Errorf("%w: %q", destination.ErrInvalidTrackID, ref)
+	}
+	return id, nil
+}
+
+// validID reports whether id has the form of a Spotify ID: 22 base-62 characters
+func validID(id string) bool {
+	if len(id) != 22 {
+		return false
+	}
+	for _, r := range id {
+		if !('0' <= r && r <= '9' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// do sends a request to the API, encoding in as the JSON body when not nil and
+// decoding the JSON response into out when not nil. Requests rejected with
+// 429 are sent again once the Retry-After delay has passed.
+func (c *Client) do(ctx context.Context, method, path string, params url.Values, in, out interface{}) error {
+	fullURL := c.baseURL + path
+	if len(params) > 0 {
+		fullURL += "?" + params.Encode()
+	}
+
+	var payload []byte
+	if in != nil {
+		var err error
+		if payload, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("unable to encode request: %w", err)
+		}
+	}
+
+	for retries := 0; ; retries++ {
+		body, err := c.send(ctx, method, fullURL, payload)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests &&
+			retries < c.rateLimitRetries && apiErr.RetryAfter <= c.maxRetryAfter {
+			if err := sleep(ctx, apiErr.RetryAfter); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if out == nil || len(body) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("unable to decode response: %w", err)
+		}
+		return nil
+	}
+}
+
+// send makes a single request with payload as the JSON body, when not nil,
+// and returns the body of a successful response
+func (c *Client) send(ctx context.Context, method, fullURL string, payload []byte) ([]byte, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to spotify failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newAPIError(resp, body)
+	}
+	return body, nil
+}
+
+// sleep waits for d, or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// newAPIError builds an APIError from Spotify's {"error": {"status", "message"}} envelope
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    http.StatusText(resp.StatusCode),
+	}
+
+	var envelope struct {
+		Error struct {
+			Status  int    `json:"status"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error.Message != "" {
+		apiErr.Message = envelope.Error.Message
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return apiErr
+}
@@ -0,0 +1,285 @@
+package spotify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/pkg/destination"
+)
+
+// recorder is a fake Spotify Web API that records the requests it receives
+type recorder struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *recorder) record(req *http.Request) int {
+	var body []byte
+	if req.Body != nil {
+		var payload json.RawMessage
+		json.NewDecoder(req.Body).Decode(&payload)
+		body = payload
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	return len(r.requests)
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+// newTestClient starts a server with handler and returns a client for it
+func newTestClient(t *testing.T, rec *recorder, handler func(w http.ResponseWriter, r *http.Request, n int)) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := rec.record(r)
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, r, n)
+	}))
+	t.Cleanup(server.Close)
+
+	return NewClient("token", WithBaseURL(server.URL), WithRateLimitRetries(2, time.Second))
+}
+
+func TestSearchTracks(t *testing.T) {
+	rec := &recorder{}
+	client := newTestClient(t, rec, func(w http.ResponseWriter, r *http.Request, n int) {
+		w.Write([]byte(`{"tracks": {"items": [{
+			"id": "4u7EnebtmKWzUH433cf5Qv",
+			"uri": "spotify:track:4u7EnebtmKWzUH433cf5Qv",
+			"name": "Bohemian Rhapsody",
+			"duration_ms": 354320,
+			"artists": [{"name": "Queen"}],
+			"album": {"name": "A Night At The Opera"},
+			"external_ids": {"isrc": "GBUM71029604"}
+		}]}}`))
+	})
+
+	tracks, err := client.SearchTracks(context.Background(), "Queen Bohemian Rhapsody", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := rec.requests[0]
+	if req.URL.Path != "/search" || req.Method != http.MethodGet {
+		t.Errorf("request = %s %s, want GET /search", req.Method, req.URL.Path)
+	}
+	query := req.URL.Query()
+	if query.Get("q") != "Queen Bohemian Rhapsody" || query.Get("type") != "track" || query.Get("limit") != "5" {
+		t.Errorf("query = %v", query)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization = %q", got)
+	}
+
+	if len(tracks) != 1 {
+		t.Fatalf("tracks = %+v, want one", tracks)
+	}
+	track := tracks[0]
+	if track.ID != "4u7EnebtmKWzUH433cf5Qv" || track.Title != "Bohemian Rhapsody" || track.Album != "A Night At The Opera" ||
+		track.ISRC != "GBUM71029604" || fmt.Sprint(track.Artists) != "[Queen]" || track.Duration != 354320*time.Millisecond {
+		t.Errorf("track = %+v", track)
+	}
+}
+
+func TestAddTracksBatches(t *testing.T) {
+	rec := &recorder{}
+	client := newTestClient(t, rec, func(w http.ResponseWriter, r *http.Request, n int) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"snapshot_id": "snapshot"}`))
+	})
+
+	ids := make([]string, 250)
+	for i := range ids {
+		ids[i] = "track" + strconv.Itoa(i)
+	}
+	if err := client.AddTracks(context.Background(), "PL1", ids); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.count() != 3 {
+		t.Fatalf("requests = %d, want 3", rec.count())
+	}
+	next := 0
+	for i, want := range []int{100, 100, 50} {
+		req := rec.requests[i]
+		if req.Method != http.MethodPost || req.URL.Path != "/playlists/PL1/tracks" {
+			t.Errorf("request %d = %s %s", i, req.Method, req.URL.Path)
+		}
+
+		var body struct {
+			URIs []string `json:"uris"`
+		}
+		if err := json.Unmarshal(rec.bodies[i], &body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.URIs) != want {
+			t.Fatalf("request %d has %d tracks, want %d", i, len(body.URIs), want)
+		}
+		// Order is kept across batches
+		for _, uri := range body.URIs {
+			if uri != "spotify:track:track"+strconv.Itoa(next) {
+				t.Fatalf("request %d: uri %q, want track%d", i, uri, next)
+			}
+			next++
+		}
+	}
+}
+
+func TestPlaylistTrackIDsPaging(t *testing.T) {
+	rec := &recorder{}
+	client := newTestClient(t, rec, func(w http.ResponseWriter, r *http.Request, n int) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		const total = 230
+		var items []string
+		for i := offset; i < total && i < offset+limit; i++ {
+			if i == 150 {
+				// Removed from the catalogue
+				items = append(items, `{"track": null}`)
+				continue
+			}
+			items = append(items, fmt.Sprintf(`{"track": {"id": "track%d"}}`, i))
+		}
+		fmt.Fprintf(w, `{"items": [%s], "total": %d}`, strings.Join(items, ","), total)
+	})
+
+	ids, err := client.PlaylistTrackIDs(context.Background(), "PL1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.count() != 3 {
+		t.Errorf("requests = %d, want 3", rec.count())
+	}
+	for i, want := range []string{"0", "100", "200"} {
+		if got := rec.requests[i].URL.Query().Get("offset"); got != want {
+			t.Errorf("request %d offset = %s, want %s", i, got, want)
+		}
+	}
+	if len(ids) != 229 || ids[0] != "track0" || ids[149] != "track149" || ids[150] != "track151" || ids[228] != "track229" {
+		t.Errorf("got %d ids: first %q, around the gap %q %q, last %q", len(ids), ids[0], ids[149], ids[150], ids[len(ids)-1])
+	}
+}
+
+func TestParseTrackID(t *testing.T) {
+	const id = "4u7EnebtmKWzUH433cf5Qv"
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{ref: id, want: id},
+		{ref: "  " + id + "\n", want: id},
+		{ref: "spotify:track:" + id, want: id},
+		{ref: "https://open.spotify.com/track/" + id, want: id},
+		{ref: "https://open.spotify.com/track/" + id + "?si=abc123", want: id},
+		{ref: "https://open.spotify.com/intl-es/track/" + id, want: id},
+		{ref: ""},
+		{ref: "4u7Eneb"},
+		{ref: "4u7EnebtmKWzUH433cf5Q-"},
+		{ref: "spotify:album:" + id},
+		{ref: "spotify:track:spotify:track:" + id},
+		{ref: "https://open.spotify.com/album/" + id},
+		{ref: "https://example.com/track/" + id},
+	}
+
+	client := NewClient("")
+	for _, tt := range tests {
+		got, err := client.ParseTrackID(tt.ref)
+		if tt.want == "" {
+			if !errors.Is(err, destination.ErrInvalidTrackID) {
+				t.Errorf("ParseTrackID(%q) = %q, %v, want ErrInvalidTrackID", tt.ref, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseTrackID(%q) = %q, %v, want %q", tt.ref, got, err, tt.want)
+		}
+	}
+}
+
+func TestRateLimitRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		limited      int    // Requests answered with 429 before one succeeds
+		retryAfter   string // Retry-After of the 429 responses
+		wantRequests int
+		wantErr      bool
+	}{
+		{name: "retried after the delay", limited: 2, retryAfter: "0", wantRequests: 3},
+		{name: "retries exhausted", limited: 5, retryAfter: "0", wantRequests: 3, wantErr: true},
+		{name: "delay too long", limited: 1, retryAfter: "3600", wantRequests: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			client := newTestClient(t, rec, func(w http.ResponseWriter, r *http.Request, n int) {
+				if n <= tt.limited {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					w.WriteHeader(http.StatusTooManyRequests)
+					w.Write([]byte(`{"error": {"status": 429, "message": "API rate limit exceeded"}}`))
+					return
+				}
+				w.Write([]byte(`{"id": "user", "display_name": "User"}`))
+			})
+
+			user, err := client.CurrentUser(context.Background())
+			if got := rec.count(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if !tt.wantErr {
+				if err != nil || user.ID != "user" {
+					t.Fatalf("user = %+v, %v", user, err)
+				}
+				return
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Message != "API rate limit exceeded" {
+				t.Fatalf("err = %v, want a 429 APIError", err)
+			}
+			if want, _ := strconv.Atoi(tt.retryAfter); apiErr.RetryAfter != time.Duration(want)*time.Second {
+				t.Errorf("RetryAfter = %s, want %ss", apiErr.RetryAfter, tt.retryAfter)
+			}
+		})
+	}
+}
+
+func TestRateLimitRetryStopsWithContext(t *testing.T) {
+	rec := &recorder{}
+	client := newTestClient(t, rec, func(w http.ResponseWriter, r *http.Request, n int) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.CurrentUser(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("waited %s for Retry-After after the context ended", elapsed)
+	}
+}
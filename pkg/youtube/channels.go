@@ -0,0 +1,53 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+// ErrNoChannel se devuelve cuando la cuenta autenticada no tiene un canal de YouTube
+var ErrNoChannel = errors.New("la cuenta no tiene un canal de YouTube")
+
+// ChannelsResponse representa la respuesta de la API de canales
+type ChannelsResponse struct {
+	Kind     string    `json:"kind"`
+	Etag     string    `json:"etag"`
+	PageInfo PageInfo  `json:"pageInfo"`
+	Items    []Channel `json:"items"`
+}
+
+// Channel representa un canal de YouTube
+type Channel struct {
+	Kind    string         `json:"kind"`
+	Etag    string         `json:"etag"`
+	ID      string         `json:"id"`
+	Snippet ChannelSnippet `json:"snippet"`
+}
+
+// ChannelSnippet contiene la información básica del canal
+type ChannelSnippet struct {
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	CustomURL   string               `json:"customUrl,omitempty"`
+	PublishedAt string               `json:"publishedAt"`
+	Thumbnails  map[string]Thumbnail `json:"thumbnails,omitempty"`
+}
+
+// GetMyChannel obtiene el canal del usuario autenticado. Su ID identifica a
+// la cuenta de forma estable, a diferencia del access token.
+func (c *Client) GetMyChannel(ctx context.Context) (*Channel, error) {
+	params := url.Values{}
+	params.Add("part", "snippet")
+	params.Add("mine", "true")
+
+	var channelsResp ChannelsResponse
+	if err := c.get(ctx, "channels", params, &channelsResp); err != nil {
+		return nil, err
+	}
+	if len(channelsResp.Items) == 0 {
+		return nil, ErrNoChannel
+	}
+
+	return &channelsResp.Items[0], nil
+}
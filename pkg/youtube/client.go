@@ -1,25 +1,150 @@
 package youtube
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
 )
 
+// DefaultBaseURL es la URL base de la YouTube Data API v3
+const DefaultBaseURL = "https://www.googleapis.com/youtube/v3"
+
 // Client representa un cliente para la API de YouTube
 type Client struct {
 	accessToken string
+	tokenSource oauth2.TokenSource
+	baseURL     string
+	userAgent   string
 	httpClient  *http.Client
+	retryPolicy RetryPolicy
+	quota       *QuotaTracker
 }
 
 // NewClient crea una nueva instancia del cliente de YouTube
-func NewClient(accessToken string) *Client {
-	return &Client{
+func NewClient(accessToken string, opts ...Option) *Client {
+	c := &Client{
 		accessToken: accessToken,
+		baseURL:     DefaultBaseURL,
 		httpClient:  &http.Client{},
+		retryPolicy: DefaultRetryPolicy(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// Envolver el transporte con la contabilidad de cuota (cada intento
+	// consume cuota) y por encima la política de reintentos
+	transport := c.httpClient.Transport
+	if c.quota != nil {
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		transport = &quotaTransport{base: transport, tracker: c.quota}
+	}
+	if c.retryPolicy.MaxAttempts > 1 {
+		transport = newRetryTransport(transport, c.retryPolicy)
+	}
+	if transport != c.httpClient.Transport {
+		httpClient := *c.httpClient
+		httpClient.Transport = transport
+		c.httpClient = &httpClient
+	}
+
+	return c
+}
+
+// get realiza una petición GET al endpoint indicado y decodifica la respuesta JSON en out
+func (c *Client) get(ctx context.Context, endpoint string, params url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodGet, endpoint, params, nil, out)
+}
+
+// do realiza una petición al endpoint indicado enviando in como cuerpo JSON
+// (si no es nil) y decodifica la respuesta JSON en out (si no es nil)
+func (c *Client) do(ctx context.Context, method, endpoint string, params url.Values, in, out interface{}) error {
+	fullURL := fmt.Sprintf("%s/%s?%s", c.baseURL, endpoint, params.Encode())
+
+	var reqBody io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("error serializando petición: %w", err)
+		}
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("error creando petición: %w", err)
+	}
+
+	// Agregar el header de autorización
+	accessToken, err := c.token()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	// Realizar la petición
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error realizando petición: %w", err)
 	}
+	defer resp.Body.Close()
+
+	// Leer la respuesta
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error leyendo respuesta: %w", err)
+	}
+
+	// Verificar el código de estado
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return newAPIError(resp.StatusCode, body)
+	}
+
+	// Parsear la respuesta JSON
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("error parseando JSON: %w", err)
+	}
+
+	return nil
+}
+
+// token devuelve el access token para la próxima petición. Con un
+// TokenSource se pide en cada petición, para que un token vencido se renueve
+// sin interrumpir operaciones largas.
+func (c *Client) token() (string, error) {
+	if c.tokenSource == nil {
+		return c.accessToken, nil
+	}
+	tok, err := c.tokenSource.Token()
+	if err != nil {
+		return "", fmt.Errorf("error obteniendo access token: %w", err)
+	}
+	return tok.AccessToken, nil
+}
+
+// trimBaseURL normaliza la URL base quitando la barra final
+func trimBaseURL(baseURL string) string {
+	return strings.TrimRight(baseURL, "/")
 }
 
 // PlaylistsResponse representa la respuesta de la API de playlists
@@ -84,7 +209,7 @@ type Thumbnail struct {
 }
 
 // ListPlaylists obtiene las playlists del usuario autenticado
-func (c *Client) ListPlaylists(options *ListPlaylistsOptions) (*PlaylistsResponse, error) {
+func (c *Client) ListPlaylists(ctx context.Context, options *ListPlaylistsOptions) (*PlaylistsResponse, error) {
 	if options == nil {
 		options = &ListPlaylistsOptions{
 			Part:       "snippet,status,contentDetails",
@@ -105,8 +230,7 @@ func (c *Client) ListPlaylists(options *ListPlaylistsOptions) (*PlaylistsRespons
 		options.MaxResults = 50
 	}
 
-	// Construir la URL con parámetros
-	baseURL := "https://www.googleapis.com/youtube/v3/playlists"
+	// Construir los parámetros
 	params := url.Values{}
 
 	params.Add("part", options.Part)
@@ -123,40 +247,9 @@ func (c *Client) ListPlaylists(options *ListPlaylistsOptions) (*PlaylistsRespons
 		params.Add("pageToken", options.PageToken)
 	}
 
-	fullURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
-
-	// Crear la petición HTTP
-	req, err := http.NewRequest("GET", fullURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creando petición: %w", err)
-	}
-
-	// Agregar el header de autorización
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.accessToken))
-	req.Header.Set("Accept", "application/json")
-
-	// Realizar la petición
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error realizando petición: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Leer la respuesta
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error leyendo respuesta: %w", err)
-	}
-
-	// Verificar el código de estado
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API retornó código %d: %s", resp.StatusCode, string(body))
-	}
-
-	// Parsear la respuesta JSON
 	var playlistsResp PlaylistsResponse
-	if err := json.Unmarshal(body, &playlistsResp); err != nil {
-		return nil, fmt.Errorf("error parseando JSON: %w", err)
+	if err := c.get(ctx, "playlists", params, &playlistsResp); err != nil {
+		return nil, err
 	}
 
 	return &playlistsResp, nil
@@ -172,8 +265,8 @@ type ListPlaylistsOptions struct {
 }
 
 // ListMyPlaylists es una función de conveniencia para listar las playlists del usuario autenticado
-func (c *Client) ListMyPlaylists() (*PlaylistsResponse, error) {
-	return c.ListPlaylists(&ListPlaylistsOptions{
+func (c *Client) ListMyPlaylists(ctx context.Context) (*PlaylistsResponse, error) {
+	return c.ListPlaylists(ctx, &ListPlaylistsOptions{
 		Part:       "snippet,status,contentDetails",
 		Mine:       true,
 		MaxResults: 50,
@@ -181,44 +274,24 @@ func (c *Client) ListMyPlaylists() (*PlaylistsResponse, error) {
 }
 
 // GetPlaylistByID obtiene una playlist específica por su ID
-func (c *Client) GetPlaylistByID(playlistID string) (*Playlist, error) {
-	baseURL := "https://www.googleapis.com/youtube/v3/playlists"
+func (c *Client) GetPlaylistByID(ctx context.Context, playlistID string) (*Playlist, error) {
 	params := url.Values{}
 	params.Add("part", "snippet,status,contentDetails")
 	params.Add("id", playlistID)
 
-	fullURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
-
-	req, err := http.NewRequest("GET", fullURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creando petición: %w", err)
-	}
-
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.accessToken))
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error realizando petición: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error leyendo respuesta: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API retornó código %d: %s", resp.StatusCode, string(body))
-	}
-
 	var playlistsResp PlaylistsResponse
-	if err := json.Unmarshal(body, &playlistsResp); err != nil {
-		return nil, fmt.Errorf("error parseando JSON: %w", err)
+	if err := c.get(ctx, "playlists", params, &playlistsResp); err != nil {
+		return nil, err
 	}
 
 	if len(playlistsResp.Items) == 0 {
-		return nil, fmt.Errorf("playlist no encontrada")
+		return nil, &APIError{
+			StatusCode: http.StatusNotFound,
+			Code:       http.StatusNotFound,
+			Message:    "playlist no encontrada",
+			Reason:     ReasonPlaylistNotFound,
+			Domain:     "youtube.playlist",
+		}
 	}
 
 	return &playlistsResp.Items[0], nil
@@ -261,47 +334,104 @@ type PlaylistItemsResponse struct {
 	Items         []PlaylistItem `json:"items"`
 }
 
-// ListPlaylistItems obtiene los videos de una playlist
-func (c *Client) ListPlaylistItems(playlistID string, maxResults int) (*PlaylistItemsResponse, error) {
-	if maxResults <= 0 {
+// ListPlaylistItemsOptions contiene las opciones para listar los items de una playlist
+type ListPlaylistItemsOptions struct {
+	Part       string // Partes a incluir: snippet, contentDetails, status, etc.
+	PlaylistID string // ID de la playlist
+	MaxResults int    // Número máximo de resultados por página (1-50, default: 50)
+	PageToken  string // Token para paginación
+}
+
+// ListPlaylistItems obtiene una página de videos de una playlist
+func (c *Client) ListPlaylistItems(ctx context.Context, options *ListPlaylistItemsOptions) (*PlaylistItemsResponse, error) {
+	if options == nil || options.PlaylistID == "" {
+		return nil, fmt.Errorf("playlistId es requerido")
+	}
+
+	part := options.Part
+	if part == "" {
+		part = "snippet"
+	}
+
+	// Asegurar que MaxResults esté en rango válido (1-50)
+	maxResults := options.MaxResults
+	if maxResults <= 0 || maxResults > 50 {
 		maxResults = 50
 	}
 
-	baseURL := "https://www.googleapis.com/youtube/v3/playlistItems"
 	params := url.Values{}
-	params.Add("part", "snippet")
-	params.Add("playlistId", playlistID)
+	params.Add("part", part)
+	params.Add("playlistId", options.PlaylistID)
 	params.Add("maxResults", fmt.Sprintf("%d", maxResults))
+	if options.PageToken != "" {
+		params.Add("pageToken", options.PageToken)
+	}
 
-	fullURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
+	var itemsResp PlaylistItemsResponse
+	if err := c.get(ctx, "playlistItems", params, &itemsResp); err != nil {
+		return nil, err
+	}
 
-	req, err := http.NewRequest("GET", fullURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creando petición: %w", err)
+	return &itemsResp, nil
+}
+
+// PlaylistItemsIterator recorre página por página los items de una playlist
+// siguiendo nextPageToken hasta agotar los resultados
+type PlaylistItemsIterator struct {
+	client  *Client
+	options ListPlaylistItemsOptions
+	done    bool
+}
+
+// IteratePlaylistItems crea un iterador sobre todas las páginas de una playlist
+func (c *Client) IteratePlaylistItems(options *ListPlaylistItemsOptions) *PlaylistItemsIterator {
+	it := &PlaylistItemsIterator{client: c}
+	if options != nil {
+		it.options = *options
 	}
+	return it
+}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.accessToken))
-	req.Header.Set("Accept", "application/json")
+// HasNext indica si quedan páginas por recorrer
+func (it *PlaylistItemsIterator) HasNext() bool {
+	return !it.done
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error realizando petición: %w", err)
+// NextPage obtiene la siguiente página de items
+func (it *PlaylistItemsIterator) NextPage(ctx context.Context) (*PlaylistItemsResponse, error) {
+	if it.done {
+		return nil, fmt.Errorf("no hay más páginas")
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	page, err := it.client.ListPlaylistItems(ctx, &it.options)
 	if err != nil {
-		return nil, fmt.Errorf("error leyendo respuesta: %w", err)
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API retornó código %d: %s", resp.StatusCode, string(body))
+	it.options.PageToken = page.NextPageToken
+	if page.NextPageToken == "" {
+		it.done = true
 	}
 
-	var itemsResp PlaylistItemsResponse
-	if err := json.Unmarshal(body, &itemsResp); err != nil {
-		return nil, fmt.Errorf("error parseando JSON: %w", err)
+	return page, nil
+}
+
+// ListAllPlaylistItems obtiene todos los videos de una playlist recorriendo todas las páginas
+func (c *Client) ListAllPlaylistItems(ctx context.Context, playlistID string) ([]PlaylistItem, error) {
+	it := c.IteratePlaylistItems(&ListPlaylistItemsOptions{
+		Part:       "snippet",
+		PlaylistID: playlistID,
+		MaxResults: 50,
+	})
+
+	var items []PlaylistItem
+	for it.HasNext() {
+		page, err := it.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
 	}
 
-	return &itemsResp, nil
+	return items, nil
 }
@@ -0,0 +1,146 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// newPlaylistItemsServer serves total items of playlist PL1 in pages of the
+// requested size. Page tokens are the offset of the page. It returns the
+// server and the page tokens it was called with.
+func newPlaylistItemsServer(t *testing.T, total int) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/youtube/v3/playlistItems" || query.Get("playlistId") != "PL1" {
+			http.NotFound(w, r)
+			return
+		}
+		tokens = append(tokens, query.Get("pageToken"))
+
+		start, _ := strconv.Atoi(query.Get("pageToken"))
+		size, _ := strconv.Atoi(query.Get("maxResults"))
+		end := start + size
+		if end > total {
+			end = total
+		}
+
+		page := PlaylistItemsResponse{PageInfo: PageInfo{TotalResults: total, ResultsPerPage: size}}
+		for i := start; i < end; i++ {
+			page.Items = append(page.Items, PlaylistItem{
+				ID: fmt.Sprintf("item-%d", i),
+				Snippet: PlaylistItemSnippet{
+					PlaylistID: "PL1",
+					Position:   i,
+					ResourceID: ResourceID{Kind: "youtube#video", VideoID: fmt.Sprintf("video-%d", i)},
+				},
+			})
+		}
+		if end < total {
+			page.NextPageToken = strconv.Itoa(end)
+		}
+		if start > 0 {
+			page.PrevPageToken = strconv.Itoa(start - size)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(server.Close)
+	return server, &tokens
+}
+
+// newTestClient returns a client whose requests go to server
+func newTestClient(t *testing.T, server *httptest.Server, opts ...Option) *Client {
+	t.Helper()
+	return NewClient("test-token", append([]Option{WithBaseURL(server.URL + "/youtube/v3/")}, opts...)...)
+}
+
+func TestListAllPlaylistItemsPaging(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      int
+		wantTokens []string
+	}{
+		{name: "empty playlist", total: 0, wantTokens: []string{""}},
+		{name: "single page", total: 50, wantTokens: []string{""}},
+		{name: "three pages", total: 120, wantTokens: []string{"", "50", "100"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, tokens := newPlaylistItemsServer(t, tt.total)
+			client := newTestClient(t, server)
+
+			items, err := client.ListAllPlaylistItems(context.Background(), "PL1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(items) != tt.total {
+				t.Fatalf("got %d items, want %d", len(items), tt.total)
+			}
+			for i, item := range items {
+				if item.Snippet.Position != i {
+					t.Fatalf("item %d has position %d", i, item.Snippet.Position)
+				}
+			}
+			if fmt.Sprint(*tokens) != fmt.Sprint(tt.wantTokens) {
+				t.Errorf("page tokens = %q, want %q", *tokens, tt.wantTokens)
+			}
+		})
+	}
+}
+
+func TestPlaylistItemsIterator(t *testing.T) {
+	server, _ := newPlaylistItemsServer(t, 25)
+	client := newTestClient(t, server)
+
+	it := client.IteratePlaylistItems(&ListPlaylistItemsOptions{PlaylistID: "PL1", MaxResults: 10})
+	var sizes []int
+	for it.HasNext() {
+		page, err := it.NextPage(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(page.Items))
+	}
+
+	if fmt.Sprint(sizes) != "[10 10 5]" {
+		t.Errorf("page sizes = %v, want [10 10 5]", sizes)
+	}
+	if _, err := it.NextPage(context.Background()); err == nil {
+		t.Error("NextPage after the last page returned no error")
+	}
+}
+
+func TestListPlaylistItemsSinglePage(t *testing.T) {
+	server, tokens := newPlaylistItemsServer(t, 120)
+	client := newTestClient(t, server)
+
+	page, err := client.ListPlaylistItems(context.Background(), &ListPlaylistItemsOptions{PlaylistID: "PL1", MaxResults: 50, PageToken: "50"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 50 {
+		t.Fatalf("got %d items, want 50", len(page.Items))
+	}
+	if got := page.Items[0].Snippet.Position; got != 50 {
+		t.Errorf("page starts at position %d, want 50", got)
+	}
+	if page.NextPageToken != "100" || page.PrevPageToken != "0" {
+		t.Errorf("page tokens = %q/%q, want 100/0", page.NextPageToken, page.PrevPageToken)
+	}
+	if page.PageInfo.TotalResults != 120 {
+		t.Errorf("totalResults = %d, want 120", page.PageInfo.TotalResults)
+	}
+	if len(*tokens) != 1 {
+		t.Errorf("made %d requests, want 1", len(*tokens))
+	}
+}
@@ -0,0 +1,141 @@
+package youtube
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Reasons de error documentados por la YouTube Data API
+const (
+	ReasonAuthError             = "authError"
+	ReasonForbidden             = "forbidden"
+	ReasonInsufficientPerms     = "insufficientPermissions"
+	ReasonQuotaExceeded         = "quotaExceeded"
+	ReasonDailyLimitExceeded    = "dailyLimitExceeded"
+	ReasonRateLimitExceeded     = "rateLimitExceeded"
+	ReasonUserRateLimitExceeded = "userRateLimitExceeded"
+	ReasonPlaylistNotFound      = "playlistNotFound"
+	ReasonPlaylistItemNotFound  = "playlistItemNotFound"
+	ReasonVideoNotFound         = "videoNotFound"
+	ReasonChannelNotFound       = "channelNotFound"
+	ReasonBackendError          = "backendError"
+)
+
+// APIError representa un error devuelto por la YouTube Data API con el
+// formato estándar de Google: {"error": {"code", "message", "errors": [...]}}
+type APIError struct {
+	StatusCode int         // Código HTTP de la respuesta
+	Code       int         // Código informado en el cuerpo del error
+	Message    string      // Mensaje legible del error
+	Reason     string      // Reason del primer error (por ejemplo "quotaExceeded")
+	Domain     string      // Dominio del primer error (por ejemplo "youtube.quota")
+	Errors     []ErrorItem // Todos los errores informados
+	Body       string      // Cuerpo crudo de la respuesta
+}
+
+// ErrorItem es cada uno de los errores del arreglo "errors" del cuerpo
+type ErrorItem struct {
+	Message string `json:"message"`
+	Domain  string `json:"domain"`
+	Reason  string `json:"reason"`
+}
+
+func (e *APIError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("API retornó código %d (%s): %s", e.StatusCode, e.Reason, e.Message)
+	}
+	return fmt.Sprintf("API retornó código %d: %s", e.StatusCode, e.Message)
+}
+
+// HasReason indica si alguno de los errores informados tiene el reason dado
+func (e *APIError) HasReason(reason string) bool {
+	if e.Reason == reason {
+		return true
+	}
+	for _, item := range e.Errors {
+		if item.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// IsQuotaExceeded indica si se agotó la cuota diaria del proyecto
+func (e *APIError) IsQuotaExceeded() bool {
+	return e.HasReason(ReasonQuotaExceeded) || e.HasReason(ReasonDailyLimitExceeded)
+}
+
+// IsRateLimited indica si la petición fue rechazada por exceso de velocidad
+func (e *APIError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests ||
+		e.HasReason(ReasonRateLimitExceeded) ||
+		e.HasReason(ReasonUserRateLimitExceeded)
+}
+
+// IsAuthError indica si el token es inválido o expiró
+func (e *APIError) IsAuthError() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.HasReason(ReasonAuthError)
+}
+
+// IsNotFound indica si el recurso pedido no existe o no es accesible
+func (e *APIError) IsNotFound() bool {
+	if e.StatusCode == http.StatusNotFound {
+		return true
+	}
+	for _, item := range e.Errors {
+		if strings.HasSuffix(item.Reason, "NotFound") {
+			return true
+		}
+	}
+	return strings.HasSuffix(e.Reason, "NotFound")
+}
+
+// AsAPIError extrae un *APIError de la cadena de errores, si existe
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// errorEnvelope es el formato JSON de los errores de las APIs de Google
+type errorEnvelope struct {
+	Error struct {
+		Code    int         `json:"code"`
+		Message string      `json:"message"`
+		Errors  []ErrorItem `json:"errors"`
+	} `json:"error"`
+}
+
+// newAPIError construye un APIError a partir de una respuesta no exitosa
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Code:       statusCode,
+		Message:    http.StatusText(statusCode),
+		Body:       string(body),
+	}
+
+	var envelope errorEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return apiErr
+	}
+
+	if envelope.Error.Code != 0 {
+		apiErr.Code = envelope.Error.Code
+	}
+	if envelope.Error.Message != "" {
+		apiErr.Message = envelope.Error.Message
+	}
+	apiErr.Errors = envelope.Error.Errors
+	if len(envelope.Error.Errors) > 0 {
+		apiErr.Reason = envelope.Error.Errors[0].Reason
+		apiErr.Domain = envelope.Error.Errors[0].Domain
+	}
+
+	return apiErr
+}
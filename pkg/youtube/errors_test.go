@@ -0,0 +1,168 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantCode    int
+		wantMessage string
+		wantReason  string
+		wantDomain  string
+		wantErrors  int
+	}{
+		{
+			name:   "quota exceeded",
+			status: http.StatusForbidden,
+			body: `{"error": {"code": 403, "message": "The request cannot be completed because you have exceeded your quota.",
+				"errors": [{"message": "quota", "domain": "youtube.quota", "reason": "quotaExceeded"}]}}`,
+			wantCode:    403,
+			wantMessage: "The request cannot be completed because you have exceeded your quota.",
+			wantReason:  ReasonQuotaExceeded,
+			wantDomain:  "youtube.quota",
+			wantErrors:  1,
+		},
+		{
+			name:   "first of several errors",
+			status: http.StatusBadRequest,
+			body: `{"error": {"code": 400, "message": "Bad request", "errors": [
+				{"domain": "youtube.parameter", "reason": "invalidValue"},
+				{"domain": "global", "reason": "required"}]}}`,
+			wantCode:    400,
+			wantMessage: "Bad request",
+			wantReason:  "invalidValue",
+			wantDomain:  "youtube.parameter",
+			wantErrors:  2,
+		},
+		{
+			name:        "envelope without errors",
+			status:      http.StatusUnauthorized,
+			body:        `{"error": {"code": 401, "message": "Request had invalid authentication credentials."}}`,
+			wantCode:    401,
+			wantMessage: "Request had invalid authentication credentials.",
+		},
+		{
+			name:        "body is not JSON",
+			status:      http.StatusBadGateway,
+			body:        `<html>Bad Gateway</html>`,
+			wantCode:    502,
+			wantMessage: "Bad Gateway",
+		},
+		{
+			name:        "empty body",
+			status:      http.StatusServiceUnavailable,
+			wantCode:    503,
+			wantMessage: "Service Unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newAPIError(tt.status, []byte(tt.body))
+
+			if err.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", err.StatusCode, tt.status)
+			}
+			if err.Code != tt.wantCode {
+				t.Errorf("Code = %d, want %d", err.Code, tt.wantCode)
+			}
+			if err.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", err.Message, tt.wantMessage)
+			}
+			if err.Reason != tt.wantReason || err.Domain != tt.wantDomain {
+				t.Errorf("Reason/Domain = %q/%q, want %q/%q", err.Reason, err.Domain, tt.wantReason, tt.wantDomain)
+			}
+			if len(err.Errors) != tt.wantErrors {
+				t.Errorf("got %d errors, want %d", len(err.Errors), tt.wantErrors)
+			}
+			if err.Body != tt.body {
+				t.Errorf("Body = %q, want the raw response", err.Body)
+			}
+		})
+	}
+}
+
+func TestAPIErrorClassification(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         *APIError
+		quota       bool
+		rateLimited bool
+		auth        bool
+		notFound    bool
+	}{
+		{name: "quota exceeded", err: &APIError{StatusCode: 403, Reason: ReasonQuotaExceeded}, quota: true},
+		{name: "daily limit exceeded", err: &APIError{StatusCode: 403, Reason: ReasonDailyLimitExceeded}, quota: true},
+		{name: "rate limit reason", err: &APIError{StatusCode: 403, Reason: ReasonRateLimitExceeded}, rateLimited: true},
+		{name: "user rate limit in a later error", err: &APIError{StatusCode: 403, Reason: ReasonForbidden,
+			Errors: []ErrorItem{{Reason: ReasonForbidden}, {Reason: ReasonUserRateLimitExceeded}}}, rateLimited: true},
+		{name: "status 429", err: &APIError{StatusCode: 429}, rateLimited: true},
+		{name: "status 401", err: &APIError{StatusCode: 401}, auth: true},
+		{name: "auth error reason", err: &APIError{StatusCode: 403, Reason: ReasonAuthError}, auth: true},
+		{name: "status 404", err: &APIError{StatusCode: 404}, notFound: true},
+		{name: "not found reason", err: &APIError{StatusCode: 403, Reason: ReasonPlaylistItemNotFound}, notFound: true},
+		{name: "forbidden", err: &APIError{StatusCode: 403, Reason: ReasonForbidden}},
+		{name: "backend error", err: &APIError{StatusCode: 500, Reason: ReasonBackendError}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.IsQuotaExceeded(); got != tt.quota {
+				t.Errorf("IsQuotaExceeded() = %v, want %v", got, tt.quota)
+			}
+			if got := tt.err.IsRateLimited(); got != tt.rateLimited {
+				t.Errorf("IsRateLimited() = %v, want %v", got, tt.rateLimited)
+			}
+			if got := tt.err.IsAuthError(); got != tt.auth {
+				t.Errorf("IsAuthError() = %v, want %v", got, tt.auth)
+			}
+			if got := tt.err.IsNotFound(); got != tt.notFound {
+				t.Errorf("IsNotFound() = %v, want %v", got, tt.notFound)
+			}
+		})
+	}
+}
+
+func TestAsAPIError(t *testing.T) {
+	apiErr := &APIError{StatusCode: 404, Reason: ReasonVideoNotFound}
+
+	if got, ok := AsAPIError(fmt.Errorf("fetching video: %w", apiErr)); !ok || got != apiErr {
+		t.Errorf("AsAPIError(wrapped) = %v, %v", got, ok)
+	}
+	if got, ok := AsAPIError(fmt.Errorf("connection refused")); ok || got != nil {
+		t.Errorf("AsAPIError(other) = %v, %v", got, ok)
+	}
+}
+
+func TestClientReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("id") == "empty" {
+			w.Write([]byte(`{"items": []}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"code": 403, "message": "quota", "errors": [{"domain": "youtube.quota", "reason": "quotaExceeded"}]}}`))
+	}))
+	defer server.Close()
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	_, err := client.GetPlaylistByID(context.Background(), "PL1")
+	if apiErr, ok := AsAPIError(err); !ok || !apiErr.IsQuotaExceeded() {
+		t.Errorf("error = %v, want a quotaExceeded APIError", err)
+	}
+
+	// An empty result is reported like a 404 from the API
+	_, err = client.GetPlaylistByID(context.Background(), "empty")
+	if apiErr, ok := AsAPIError(err); !ok || !apiErr.IsNotFound() || apiErr.Reason != ReasonPlaylistNotFound {
+		t.Errorf("error = %v, want a playlistNotFound APIError", err)
+	}
+}
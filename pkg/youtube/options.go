@@ -0,0 +1,85 @@
+package youtube
+
+import (
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Option configura un Client
+type Option func(*Client)
+
+// WithBaseURL cambia la URL base de la API (por ejemplo, un servidor falso o un proxy)
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if baseURL != "" {
+			c.baseURL = trimBaseURL(baseURL)
+		}
+	}
+}
+
+// WithTokenSource obtiene el access token de cada petición del TokenSource
+// indicado en lugar del token fijo pasado a NewClient. Con un TokenSource que
+// renueva tokens, el cliente sigue funcionando después de que vence el access token.
+func WithTokenSource(tokenSource oauth2.TokenSource) Option {
+	return func(c *Client) {
+		c.tokenSource = tokenSource
+	}
+}
+
+// WithHTTPClient usa el http.Client indicado para todas las peticiones
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithTransport usa el RoundTripper indicado en el http.Client del cliente
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		httpClient := *c.httpClient
+		httpClient.Transport = transport
+		c.httpClient = &httpClient
+	}
+}
+
+// WithUserAgent define el header User-Agent enviado en cada petición
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTimeout define el tiempo máximo de cada petición HTTP, incluidos sus reintentos
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		httpClient := *c.httpClient
+		httpClient.Timeout = timeout
+		c.httpClient = &httpClient
+	}
+}
+
+// WithRetryPolicy reemplaza la política de reintentos por defecto
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithoutRetries desactiva los reintentos automáticos
+func WithoutRetries() Option {
+	return func(c *Client) {
+		c.retryPolicy.MaxAttempts = 1
+	}
+}
+
+// WithQuotaTracker contabiliza la cuota de cada petición en el tracker indicado,
+// que normalmente se comparte entre todos los clientes
+func WithQuotaTracker(tracker *QuotaTracker) Option {
+	return func(c *Client) {
+		c.quota = tracker
+	}
+}
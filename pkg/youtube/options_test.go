@@ -0,0 +1,103 @@
+package youtube
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// countingTransport counts the requests that go through it
+type countingTransport struct {
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	client := NewClient("test-token")
+
+	if client.baseURL != DefaultBaseURL {
+		t.Errorf("baseURL = %q, want %q", client.baseURL, DefaultBaseURL)
+	}
+	if client.httpClient == nil {
+		t.Fatal("no HTTP client")
+	}
+	if client.userAgent != "" {
+		t.Errorf("userAgent = %q, want none", client.userAgent)
+	}
+}
+
+func TestNewClientOptions(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/playlists" {
+			http.NotFound(w, r)
+			return
+		}
+		headers = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items": [{"id": "PL1", "snippet": {"title": "Playlist"}}]}`))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/api/"),
+		WithTransport(transport),
+		WithUserAgent("playlist-migration-tool/test"),
+		WithTimeout(5*time.Second),
+	)
+
+	playlist, err := client.GetPlaylistByID(context.Background(), "PL1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if playlist.ID != "PL1" {
+		t.Errorf("playlist = %q, want PL1", playlist.ID)
+	}
+
+	if got := headers.Get("Authorization"); got != "Bearer test-token" {
+		t.Errorf("Authorization = %q, want Bearer test-token", got)
+	}
+	if got := headers.Get("User-Agent"); got != "playlist-migration-tool/test" {
+		t.Errorf("User-Agent = %q, want playlist-migration-tool/test", got)
+	}
+	if transport.requests != 1 {
+		t.Errorf("transport saw %d requests, want 1", transport.requests)
+	}
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("timeout = %v, want 5s", client.httpClient.Timeout)
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	custom := &http.Client{Timeout: time.Second}
+
+	// Retries wrap the transport in a copy of the HTTP client
+	if client := NewClient("test-token", WithHTTPClient(custom), WithoutRetries()); client.httpClient != custom {
+		t.Error("WithHTTPClient did not set the HTTP client")
+	}
+	if client := NewClient("test-token", WithHTTPClient(nil)); client.httpClient == nil {
+		t.Error("WithHTTPClient(nil) removed the HTTP client")
+	}
+	if client := NewClient("test-token", WithBaseURL("")); client.baseURL != DefaultBaseURL {
+		t.Errorf("WithBaseURL(\"\") set baseURL to %q", client.baseURL)
+	}
+}
+
+func TestWithTimeoutDoesNotChangeSharedClient(t *testing.T) {
+	shared := &http.Client{}
+
+	client := NewClient("test-token", WithHTTPClient(shared), WithTimeout(time.Second))
+	if client.httpClient.Timeout != time.Second {
+		t.Errorf("timeout = %v, want 1s", client.httpClient.Timeout)
+	}
+	if shared.Timeout != 0 {
+		t.Errorf("shared client timeout changed to %v", shared.Timeout)
+	}
+}
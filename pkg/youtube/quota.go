@@ -0,0 +1,241 @@
+package youtube
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDailyQuota es la cuota diaria asignada por Google a un proyecto nuevo
+const DefaultDailyQuota = 10000
+
+// DefaultQuotaCosts contiene el costo en unidades de cada método de la API
+var DefaultQuotaCosts = map[string]int{
+	"playlists.list":       1,
+	"playlists.insert":     50,
+	"playlists.update":     50,
+	"playlists.delete":     50,
+	"playlistItems.list":   1,
+	"playlistItems.insert": 50,
+	"playlistItems.update": 50,
+	"playlistItems.delete": 50,
+	"videos.list":          1,
+	"channels.list":        1,
+	"search.list":          100,
+}
+
+// QuotaExceededError se devuelve cuando una llamada superaría el presupuesto diario configurado
+type QuotaExceededError struct {
+	Method  string
+	Cost    int
+	Used    int
+	Budget  int
+	ResetAt time.Time
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("presupuesto de cuota agotado: %s cuesta %d unidades y ya se usaron %d de %d (se reinicia %s)",
+		e.Method, e.Cost, e.Used, e.Budget, e.ResetAt.Format(time.RFC3339))
+}
+
+// QuotaUsage es una foto del consumo de cuota del día actual
+type QuotaUsage struct {
+	Budget    int                   `json:"budget"`
+	Used      int                   `json:"used"`
+	Remaining int                   `json:"remaining"`
+	ResetAt   time.Time             `json:"reset_at"`
+	ByMethod  map[string]int        `json:"by_method"`
+	ByToken   map[string]TokenUsage `json:"by_token,omitempty"`
+}
+
+// TokenUsage es el consumo de cuota de un access token
+type TokenUsage struct {
+	Used     int            `json:"used"`
+	ByMethod map[string]int `json:"by_method"`
+}
+
+// QuotaTracker lleva la cuenta de unidades consumidas por método y por access
+// token, y rechaza llamadas que superarían el presupuesto diario. El día se
+// reinicia a medianoche en hora del Pacífico, igual que en Google.
+type QuotaTracker struct {
+	mu       sync.Mutex
+	budget   int
+	costs    map[string]int
+	now      func() time.Time
+	location *time.Location
+	resetAt  time.Time
+	used     int
+	byMethod map[string]int
+	byToken  map[string]*TokenUsage
+}
+
+// NewQuotaTracker crea un tracker con el presupuesto diario indicado. Un
+// presupuesto de cero solo contabiliza, sin rechazar llamadas.
+func NewQuotaTracker(dailyBudget int) *QuotaTracker {
+	t := &QuotaTracker{
+		budget:   dailyBudget,
+		costs:    DefaultQuotaCosts,
+		now:      time.Now,
+		location: pacificLocation(),
+	}
+	t.reset()
+	return t
+}
+
+// Reserve descuenta el costo de method para accessToken, o devuelve un
+// *QuotaExceededError si no queda presupuesto suficiente
+func (t *QuotaTracker) Reserve(accessToken, method string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollover()
+
+	cost := t.cost(method)
+	if t.budget > 0 && t.used+cost > t.budget {
+		return &QuotaExceededError{
+			Method:  method,
+			Cost:    cost,
+			Used:    t.used,
+			Budget:  t.budget,
+			ResetAt: t.resetAt,
+		}
+	}
+
+	t.used += cost
+	t.byMethod[method] += cost
+
+	key := TokenKey(accessToken)
+	usage, ok := t.byToken[key]
+	if !ok {
+		usage = &TokenUsage{ByMethod: make(map[string]int)}
+		t.byToken[key] = usage
+	}
+	usage.Used += cost
+	usage.ByMethod[method] += cost
+
+	return nil
+}
+
+// Usage devuelve el consumo acumulado del día
+func (t *QuotaTracker) Usage() QuotaUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollover()
+
+	usage := QuotaUsage{
+		Budget:   t.budget,
+		Used:     t.used,
+		ResetAt:  t.resetAt,
+		ByMethod: copyCounts(t.byMethod),
+		ByToken:  make(map[string]TokenUsage, len(t.byToken)),
+	}
+	if t.budget > 0 {
+		usage.Remaining = t.budget - t.used
+	}
+	for key, tokenUsage := range t.byToken {
+		usage.ByToken[key] = TokenUsage{Used: tokenUsage.Used, ByMethod: copyCounts(tokenUsage.ByMethod)}
+	}
+
+	return usage
+}
+
+// TokenUsage devuelve el consumo del día de un access token
+func (t *QuotaTracker) TokenUsage(accessToken string) TokenUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollover()
+
+	usage, ok := t.byToken[TokenKey(accessToken)]
+	if !ok {
+		return TokenUsage{ByMethod: map[string]int{}}
+	}
+	return TokenUsage{Used: usage.Used, ByMethod: copyCounts(usage.ByMethod)}
+}
+
+// cost devuelve el costo de un método; los desconocidos cuestan 1 unidad
+func (t *QuotaTracker) cost(method string) int {
+	if cost, ok := t.costs[method]; ok {
+		return cost
+	}
+	return 1
+}
+
+// rollover reinicia los contadores si ya pasó la medianoche del Pacífico. Debe llamarse con mu tomado.
+func (t *QuotaTracker) rollover() {
+	if !t.now().Before(t.resetAt) {
+		t.reset()
+	}
+}
+
+// reset pone los contadores en cero y calcula el próximo reinicio
+func (t *QuotaTracker) reset() {
+	now := t.now().In(t.location)
+	t.resetAt = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, t.location)
+	t.used = 0
+	t.byMethod = make(map[string]int)
+	t.byToken = make(map[string]*TokenUsage)
+}
+
+// TokenKey devuelve un identificador estable de un access token que no lo expone
+func TokenKey(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// pacificLocation devuelve la zona horaria del Pacífico, o un offset fijo si
+// la base de zonas horarias no está disponible en el sistema
+func pacificLocation() *time.Location {
+	if loc, err := time.LoadLocation("America/Los_Angeles"); err == nil {
+		return loc
+	}
+	return time.FixedZone("PST", -8*60*60)
+}
+
+// copyCounts copia un mapa de contadores
+func copyCounts(counts map[string]int) map[string]int {
+	copied := make(map[string]int, len(counts))
+	for k, v := range counts {
+		copied[k] = v
+	}
+	return copied
+}
+
+// quotaTransport es un http.RoundTripper que descuenta cuota antes de cada petición
+type quotaTransport struct {
+	base    http.RoundTripper
+	tracker *QuotaTracker
+}
+
+// RoundTrip implementa http.RoundTripper
+func (t *quotaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	accessToken := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if err := t.tracker.Reserve(accessToken, quotaMethod(req)); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// quotaMethod deduce el método de la API (por ejemplo "playlistItems.list") a partir de la petición
+func quotaMethod(req *http.Request) string {
+	resource := path.Base(req.URL.Path)
+	switch req.Method {
+	case http.MethodPost:
+		return resource + ".insert"
+	case http.MethodPut:
+		return resource + ".update"
+	case http.MethodDelete:
+		return resource + ".delete"
+	default:
+		return resource + ".list"
+	}
+}
@@ -0,0 +1,198 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock es un reloj que solo avanza cuando el test lo indica
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// newTestTracker crea un tracker que lee la hora de clock
+func newTestTracker(budget int, clock *fakeClock) *QuotaTracker {
+	tracker := NewQuotaTracker(budget)
+	tracker.now = clock.Now
+	tracker.reset()
+	return tracker
+}
+
+// pacificTime devuelve la hora indicada en la zona del Pacífico
+func pacificTime(t *testing.T, year int, month time.Month, day, hour, min int) time.Time {
+	t.Helper()
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+	return time.Date(year, month, day, hour, min, 0, 0, loc)
+}
+
+func TestQuotaTrackerBudget(t *testing.T) {
+	clock := &fakeClock{now: pacificTime(t, 2024, time.March, 5, 10, 0)}
+	tracker := newTestTracker(120, clock)
+
+	// 50 + 50 + 20 × 1 = 120 unidades: justo el presupuesto
+	for _, method := range []string{"playlistItems.insert", "playlistItems.insert"} {
+		if err := tracker.Reserve("token-a", method); err != nil {
+			t.Fatalf("Reserve(%s): %v", method, err)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		if err := tracker.Reserve("token-b", "playlists.list"); err != nil {
+			t.Fatalf("Reserve(playlists.list) #%d: %v", i+1, err)
+		}
+	}
+
+	err := tracker.Reserve("token-b", "videos.list")
+	var quotaErr *QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("Reserve over budget = %v, want *QuotaExceededError", err)
+	}
+	if quotaErr.Used != 120 || quotaErr.Budget != 120 || quotaErr.Cost != 1 {
+		t.Errorf("error = %+v", quotaErr)
+	}
+	if want := pacificTime(t, 2024, time.March, 6, 0, 0); !quotaErr.ResetAt.Equal(want) {
+		t.Errorf("ResetAt = %s, want %s", quotaErr.ResetAt, want)
+	}
+
+	// Las llamadas rechazadas no consumen cuota
+	usage := tracker.Usage()
+	if usage.Used != 120 || usage.Remaining != 0 {
+		t.Errorf("usage = %d used, %d remaining; want 120, 0", usage.Used, usage.Remaining)
+	}
+	if got := tracker.TokenUsage("token-a").Used; got != 100 {
+		t.Errorf("token-a used %d, want 100", got)
+	}
+	if got := tracker.TokenUsage("token-b").ByMethod["playlists.list"]; got != 20 {
+		t.Errorf("token-b playlists.list = %d, want 20", got)
+	}
+}
+
+func TestQuotaTrackerWithoutBudgetOnlyCounts(t *testing.T) {
+	clock := &fakeClock{now: pacificTime(t, 2024, time.March, 5, 10, 0)}
+	tracker := newTestTracker(0, clock)
+
+	for i := 0; i < 300; i++ {
+		if err := tracker.Reserve("token", "playlists.insert"); err != nil {
+			t.Fatalf("Reserve #%d: %v", i+1, err)
+		}
+	}
+	if got := tracker.Usage().Used; got != 15000 {
+		t.Errorf("used = %d, want 15000", got)
+	}
+}
+
+func TestQuotaTrackerResetsAtPacificMidnight(t *testing.T) {
+	tests := []struct {
+		name  string
+		start time.Time
+	}{
+		{name: "standard time", start: pacificTime(t, 2024, time.January, 15, 23, 30)},
+		{name: "daylight saving time", start: pacificTime(t, 2024, time.July, 15, 23, 30)},
+		// El 10 de marzo de 2024 solo tiene 23 horas en el Pacífico
+		{name: "spring forward day", start: pacificTime(t, 2024, time.March, 10, 23, 30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: tt.start}
+			tracker := newTestTracker(50, clock)
+
+			if err := tracker.Reserve("token", "playlists.insert"); err != nil {
+				t.Fatal(err)
+			}
+			if err := tracker.Reserve("token", "playlists.list"); err == nil {
+				t.Fatal("budget was not enforced")
+			}
+
+			// 23:59: todavía es el mismo día en el Pacífico, aunque en UTC ya cambió
+			clock.Advance(29 * time.Minute)
+			if got := tracker.Usage().Used; got != 50 {
+				t.Fatalf("used at 23:59 = %d, want 50", got)
+			}
+
+			// 00:00 del día siguiente: se reinicia
+			clock.Advance(time.Minute)
+			usage := tracker.Usage()
+			if usage.Used != 0 || usage.Remaining != 50 {
+				t.Fatalf("usage after midnight = %d used, %d remaining; want 0, 50", usage.Used, usage.Remaining)
+			}
+			if got := tracker.TokenUsage("token").Used; got != 0 {
+				t.Errorf("token usage after midnight = %d, want 0", got)
+			}
+			next := tt.start.AddDate(0, 0, 2)
+			want := time.Date(next.Year(), next.Month(), next.Day(), 0, 0, 0, 0, tt.start.Location())
+			if !usage.ResetAt.Equal(want) {
+				t.Errorf("next reset = %s, want %s", usage.ResetAt, want)
+			}
+			if err := tracker.Reserve("token", "playlists.insert"); err != nil {
+				t.Errorf("Reserve after midnight: %v", err)
+			}
+		})
+	}
+}
+
+func TestQuotaTrackerStopsRequests(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{now: pacificTime(t, 2024, time.March, 5, 10, 0)}
+	tracker := newTestTracker(2, clock)
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, Logf: t.Logf}
+	client := NewClient("token", WithBaseURL(server.URL), WithQuotaTracker(tracker), WithRetryPolicy(policy))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.ListMyPlaylists(context.Background()); err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+	}
+
+	// La tercera llamada se rechaza sin llegar a la API y sin reintentos
+	_, err := client.ListMyPlaylists(context.Background())
+	var quotaErr *QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("error = %v, want *QuotaExceededError", err)
+	}
+	if quotaErr.Method != "playlists.list" {
+		t.Errorf("method = %q, want playlists.list", quotaErr.Method)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestQuotaMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{method: http.MethodGet, path: "/youtube/v3/playlists", want: "playlists.list"},
+		{method: http.MethodGet, path: "/youtube/v3/playlistItems", want: "playlistItems.list"},
+		{method: http.MethodPost, path: "/youtube/v3/playlistItems", want: "playlistItems.insert"},
+		{method: http.MethodPut, path: "/youtube/v3/playlists", want: "playlists.update"},
+		{method: http.MethodDelete, path: "/youtube/v3/playlistItems", want: "playlistItems.delete"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "https://www.googleapis.com"+tt.path+"?part=snippet", nil)
+		if got := quotaMethod(req); got != tt.want {
+			t.Errorf("quotaMethod(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
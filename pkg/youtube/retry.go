@@ -0,0 +1,215 @@
+package youtube
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configura los reintentos ante fallos transitorios de la API
+// (errores 5xx, 429, rateLimitExceeded y errores de conexión)
+type RetryPolicy struct {
+	MaxAttempts        int           // Intentos totales incluido el primero; 1 desactiva los reintentos
+	BaseDelay          time.Duration // Espera base del backoff exponencial
+	MaxDelay           time.Duration // Espera máxima entre intentos
+	RetryNonIdempotent bool          // Si es true también reintenta POST, PUT, DELETE, etc.
+	OnAttempt          func(Attempt) // Hook de métricas invocado después de cada intento
+	Logf               func(format string, args ...interface{})
+}
+
+// Attempt describe el resultado de un intento de petición
+type Attempt struct {
+	Method     string        // Método HTTP
+	URL        string        // URL sin parámetros
+	Number     int           // Número de intento, empezando en 1
+	StatusCode int           // Código HTTP recibido (0 si hubo error de conexión)
+	Err        error         // Error de conexión, si lo hubo
+	Retry      bool          // Si se va a reintentar
+	Delay      time.Duration // Espera antes del siguiente intento
+}
+
+// DefaultRetryPolicy devuelve la política usada por NewClient por defecto
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// retryTransport es un http.RoundTripper que reintenta según una RetryPolicy
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+// newRetryTransport envuelve base con la política de reintentos indicada
+func newRetryTransport(base http.RoundTripper, policy RetryPolicy) *retryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = DefaultRetryPolicy().BaseDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = DefaultRetryPolicy().MaxDelay
+	}
+	if policy.Logf == nil {
+		policy.Logf = log.Printf
+	}
+	return &retryTransport{base: base, policy: policy}
+}
+
+// RoundTrip implementa http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	maxAttempts := t.policy.MaxAttempts
+	if !t.policy.RetryNonIdempotent && !isIdempotent(req.Method) {
+		maxAttempts = 1
+	}
+	if req.Body != nil && req.GetBody == nil {
+		// Sin GetBody no es posible volver a enviar el cuerpo
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(r)
+
+		retry := attempt < maxAttempts && ctx.Err() == nil && t.shouldRetry(resp, err)
+		var delay time.Duration
+		if retry {
+			delay = t.backoff(attempt, resp)
+		}
+
+		info := Attempt{
+			Method: req.Method,
+			URL:    req.URL.Scheme + "://" + req.URL.Host + req.URL.Path,
+			Number: attempt,
+			Err:    err,
+			Retry:  retry,
+			Delay:  delay,
+		}
+		if resp != nil {
+			info.StatusCode = resp.StatusCode
+		}
+		if t.policy.OnAttempt != nil {
+			t.policy.OnAttempt(info)
+		}
+
+		if !retry {
+			return resp, err
+		}
+
+		if err != nil {
+			t.policy.Logf("youtube: intento %d/%d de %s %s falló: %v; reintentando en %s", attempt, maxAttempts, info.Method, info.URL, err, delay)
+		} else {
+			t.policy.Logf("youtube: intento %d/%d de %s %s retornó %d; reintentando en %s", attempt, maxAttempts, info.Method, info.URL, resp.StatusCode, delay)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry decide si el resultado de un intento es un fallo transitorio
+func (t *retryTransport) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		// Errores de conexión (reset, EOF, timeouts de red), pero no el
+		// presupuesto de cuota agotado: reintentar no lo soluciona
+		var quotaErr *QuotaExceededError
+		return !errors.As(err, &quotaErr)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	case http.StatusForbidden:
+		// rateLimitExceeded llega como 403: hay que mirar el cuerpo
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if readErr != nil {
+			return false
+		}
+		apiErr := newAPIError(resp.StatusCode, body)
+		return apiErr.IsRateLimited() && !apiErr.IsQuotaExceeded()
+	default:
+		return false
+	}
+}
+
+// backoff calcula la espera antes del siguiente intento: Retry-After si el
+// servidor lo envía, o backoff exponencial con jitter
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if d > t.policy.MaxDelay {
+				return t.policy.MaxDelay
+			}
+			return d
+		}
+	}
+
+	d := t.policy.BaseDelay << uint(attempt-1)
+	if d <= 0 || d > t.policy.MaxDelay {
+		d = t.policy.MaxDelay
+	}
+
+	// Equal jitter: la mitad fija y la otra mitad aleatoria
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseRetryAfter interpreta el header Retry-After en segundos o como fecha HTTP
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		d := time.Until(at)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// isIdempotent indica si el método HTTP puede reintentarse sin efectos secundarios
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
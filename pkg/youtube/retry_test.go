@@ -0,0 +1,222 @@
+package youtube
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testRetryPolicy devuelve una política con esperas cortas que registra cada intento
+func testRetryPolicy(t *testing.T, attempts *[]Attempt) RetryPolicy {
+	var mu sync.Mutex
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+		OnAttempt: func(a Attempt) {
+			mu.Lock()
+			defer mu.Unlock()
+			*attempts = append(*attempts, a)
+		},
+		Logf: t.Logf,
+	}
+}
+
+// flakyServer responde con las respuestas indicadas en orden y luego con 200
+func flakyServer(t *testing.T, responses ...func(w http.ResponseWriter)) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		if n <= len(responses) {
+			responses[n-1](w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"items":[]}`)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func status(code int) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.WriteHeader(code)
+	}
+}
+
+func googleError(code int, reason string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		io.WriteString(w, `{"error":{"code":403,"message":"error","errors":[{"reason":"`+reason+`","domain":"youtube"}]}}`)
+	}
+}
+
+func retryAfter(value string, code int) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", value)
+		w.WriteHeader(code)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "", wantOK: false},
+		{value: "0", want: 0, wantOK: true},
+		{value: "7", want: 7 * time.Second, wantOK: true},
+		{value: "-3", wantOK: false},
+		{value: "soon", wantOK: false},
+		{value: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), want: 0, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, %v; want %s, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	// Una fecha HTTP futura se convierte en el tiempo que falta hasta ella
+	got, ok := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if !ok || got <= 58*time.Second || got > time.Minute {
+		t.Errorf("parseRetryAfter(fecha +1m) = %s, %v", got, ok)
+	}
+}
+
+func TestRetryHonoursRetryAfter(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  func(w http.ResponseWriter)
+		wantDelay time.Duration
+	}{
+		{name: "retry after zero", response: retryAfter("0", http.StatusServiceUnavailable), wantDelay: 0},
+		{name: "capped at max delay", response: retryAfter("3600", http.StatusTooManyRequests), wantDelay: 5 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := flakyServer(t, tt.response)
+			var attempts []Attempt
+			client := NewClient("token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy(t, &attempts)))
+
+			if _, err := client.ListMyPlaylists(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := atomic.LoadInt32(requests); got != 2 {
+				t.Fatalf("requests = %d, want 2", got)
+			}
+			if !attempts[0].Retry || attempts[0].Delay != tt.wantDelay {
+				t.Errorf("first attempt: retry %v, delay %s; want retry, delay %s", attempts[0].Retry, attempts[0].Delay, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestRetryAttemptCap(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     func(w http.ResponseWriter)
+		wantRequests int32
+	}{
+		{name: "server error", response: status(http.StatusInternalServerError), wantRequests: 3},
+		{name: "bad gateway", response: status(http.StatusBadGateway), wantRequests: 3},
+		{name: "too many requests", response: status(http.StatusTooManyRequests), wantRequests: 3},
+		{name: "rate limit exceeded", response: googleError(http.StatusForbidden, "rateLimitExceeded"), wantRequests: 3},
+		{name: "quota exceeded is not retried", response: googleError(http.StatusForbidden, "quotaExceeded"), wantRequests: 1},
+		{name: "not found is not retried", response: status(http.StatusNotFound), wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Falla siempre: más respuestas que intentos permitidos
+			server, requests := flakyServer(t, tt.response, tt.response, tt.response, tt.response)
+			var attempts []Attempt
+			client := NewClient("token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy(t, &attempts)))
+
+			if _, err := client.ListMyPlaylists(context.Background()); err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := atomic.LoadInt32(requests); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if last := attempts[len(attempts)-1]; last.Retry {
+				t.Errorf("last attempt %d was marked for retry", last.Number)
+			}
+		})
+	}
+}
+
+func TestRetryNonIdempotentWrites(t *testing.T) {
+	tests := []struct {
+		name               string
+		retryNonIdempotent bool
+		wantRequests       int32
+	}{
+		{name: "writes are not retried by default", wantRequests: 1},
+		{name: "writes are retried when enabled", retryNonIdempotent: true, wantRequests: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var bodies []string
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				mu.Lock()
+				bodies = append(bodies, string(body))
+				mu.Unlock()
+				atomic.AddInt32(&requests, 1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			var attempts []Attempt
+			policy := testRetryPolicy(t, &attempts)
+			policy.RetryNonIdempotent = tt.retryNonIdempotent
+			client := NewClient("token", WithBaseURL(server.URL), WithRetryPolicy(policy))
+
+			if _, err := client.InsertPlaylistItem(context.Background(), "PL1", "video1", nil); err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := atomic.LoadInt32(&requests); got != tt.wantRequests {
+				t.Fatalf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			// Cada reintento vuelve a enviar el cuerpo completo
+			for i, body := range bodies {
+				if body == "" || body != bodies[0] {
+					t.Errorf("request %d body = %q, want %q", i+1, body, bodies[0])
+				}
+			}
+		})
+	}
+}
+
+func TestRetryStopsWhenContextIsCancelled(t *testing.T) {
+	server, requests := flakyServer(t, retryAfter("3600", http.StatusServiceUnavailable))
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Hour, Logf: t.Logf}
+	client := NewClient("token", WithBaseURL(server.URL), WithRetryPolicy(policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.ListMyPlaylists(ctx); err == nil {
+		t.Fatal("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %s for Retry-After after the context ended", elapsed)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
@@ -0,0 +1,191 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxVideoIDsPerRequest es el máximo de IDs que acepta videos.list en una llamada
+const MaxVideoIDsPerRequest = 50
+
+// DefaultVideoParts son las partes pedidas por GetVideos
+const DefaultVideoParts = "snippet,contentDetails,statistics,status,topicDetails"
+
+// MusicCategoryID es el ID de la categoría "Music" de YouTube
+const MusicCategoryID = "10"
+
+// VideosResponse representa la respuesta de la API de videos
+type VideosResponse struct {
+	Kind          string   `json:"kind"`
+	Etag          string   `json:"etag"`
+	NextPageToken string   `json:"nextPageToken,omitempty"`
+	PrevPageToken string   `json:"prevPageToken,omitempty"`
+	PageInfo      PageInfo `json:"pageInfo"`
+	Items         []Video  `json:"items"`
+}
+
+// Video representa un video de YouTube
+type Video struct {
+	Kind           string              `json:"kind"`
+	Etag           string              `json:"etag,omitempty"`
+	ID             string              `json:"id"`
+	Snippet        VideoSnippet        `json:"snippet"`
+	ContentDetails VideoContentDetails `json:"contentDetails"`
+	Statistics     VideoStatistics     `json:"statistics"`
+	Status         VideoStatus         `json:"status"`
+	TopicDetails   *VideoTopicDetails  `json:"topicDetails,omitempty"`
+}
+
+// VideoSnippet contiene la información básica del video
+type VideoSnippet struct {
+	PublishedAt  string               `json:"publishedAt"`
+	ChannelID    string               `json:"channelId"`
+	ChannelTitle string               `json:"channelTitle"`
+	Title        string               `json:"title"`
+	Description  string               `json:"description"`
+	Thumbnails   map[string]Thumbnail `json:"thumbnails,omitempty"`
+	CategoryID   string               `json:"categoryId"`
+}
+
+// VideoContentDetails contiene la duración ISO-8601 y las restricciones regionales
+type VideoContentDetails struct {
+	Duration          string             `json:"duration"`
+	RegionRestriction *RegionRestriction `json:"regionRestriction,omitempty"`
+}
+
+// RegionRestriction lista los países donde el video está permitido o bloqueado
+type RegionRestriction struct {
+	Allowed []string `json:"allowed,omitempty"`
+	Blocked []string `json:"blocked,omitempty"`
+}
+
+// VideoStatistics contiene los contadores del video (la API los devuelve como strings)
+type VideoStatistics struct {
+	ViewCount    string `json:"viewCount,omitempty"`
+	LikeCount    string `json:"likeCount,omitempty"`
+	CommentCount string `json:"commentCount,omitempty"`
+}
+
+// VideoStatus contiene el estado de publicación del video
+type VideoStatus struct {
+	UploadStatus  string `json:"uploadStatus"`
+	PrivacyStatus string `json:"privacyStatus"`
+	Embeddable    bool   `json:"embeddable"`
+}
+
+// VideoTopicDetails contiene las categorías temáticas (URLs de Wikipedia) del video
+type VideoTopicDetails struct {
+	TopicCategories []string `json:"topicCategories,omitempty"`
+}
+
+// Duration devuelve la duración del video parseada
+func (v *Video) Duration() (time.Duration, error) {
+	return ParseDuration(v.ContentDetails.Duration)
+}
+
+// ViewCount devuelve la cantidad de reproducciones como número
+func (v *Video) ViewCount() int64 {
+	count, _ := strconv.ParseInt(v.Statistics.ViewCount, 10, 64)
+	return count
+}
+
+// IsMusic indica si el video pertenece a la categoría Music o a un tema musical
+func (v *Video) IsMusic() bool {
+	if v.Snippet.CategoryID == MusicCategoryID {
+		return true
+	}
+	if v.TopicDetails == nil {
+		return false
+	}
+	for _, topic := range v.TopicDetails.TopicCategories {
+		name := topic[strings.LastIndex(topic, "/")+1:]
+		if name == "Music" || strings.HasSuffix(name, "_music") {
+			return true
+		}
+	}
+	return false
+}
+
+// ListVideos obtiene hasta 50 videos por ID en una sola llamada
+func (c *Client) ListVideos(ctx context.Context, ids []string, part string) (*VideosResponse, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("se requiere al menos un ID de video")
+	}
+	if len(ids) > MaxVideoIDsPerRequest {
+		return nil, fmt.Errorf("videos.list acepta como máximo %d IDs, se recibieron %d", MaxVideoIDsPerRequest, len(ids))
+	}
+	if part == "" {
+		part = DefaultVideoParts
+	}
+
+	params := url.Values{}
+	params.Add("part", part)
+	params.Add("id", strings.Join(ids, ","))
+	params.Add("maxResults", fmt.Sprintf("%d", MaxVideoIDsPerRequest))
+
+	var videosResp VideosResponse
+	if err := c.get(ctx, "videos", params, &videosResp); err != nil {
+		return nil, err
+	}
+
+	return &videosResp, nil
+}
+
+// GetVideos obtiene los detalles de todos los videos indicados, agrupando los
+// IDs en lotes de 50. Los videos privados o eliminados no aparecen en el resultado.
+func (c *Client) GetVideos(ctx context.Context, ids []string) ([]Video, error) {
+	var videos []Video
+	for start := 0; start < len(ids); start += MaxVideoIDsPerRequest {
+		end := start + MaxVideoIDsPerRequest
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		page, err := c.ListVideos(ctx, ids[start:end], DefaultVideoParts)
+		if err != nil {
+			return nil, err
+		}
+		videos = append(videos, page.Items...)
+	}
+
+	return videos, nil
+}
+
+// isoDurationPattern reconoce duraciones ISO-8601 como PT4M13S o P1DT2H
+var isoDurationPattern = regexp.MustCompile(`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// ParseDuration convierte una duración ISO-8601 (formato usado por la API) a time.Duration
+func ParseDuration(iso string) (time.Duration, error) {
+	matches := isoDurationPattern.FindStringSubmatch(iso)
+	if matches == nil || iso == "P" || strings.HasSuffix(iso, "T") {
+		return 0, fmt.Errorf("duración ISO-8601 inválida: %q", iso)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute}
+	var total time.Duration
+	for i, unit := range units {
+		if matches[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(matches[i+1])
+		if err != nil {
+			return 0, fmt.Errorf("duración ISO-8601 inválida: %q", iso)
+		}
+		total += time.Duration(n) * unit
+	}
+
+	if matches[5] != "" {
+		seconds, err := strconv.ParseFloat(matches[5], 64)
+		if err != nil {
+			return 0, fmt.Errorf("duración ISO-8601 inválida: %q", iso)
+		}
+		total += time.Duration(seconds * float64(time.Second))
+	}
+
+	return total, nil
+}
@@ -0,0 +1,114 @@
+package youtube_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube/youtubetest"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		iso     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{iso: "PT4M13S", want: 4*time.Minute + 13*time.Second},
+		{iso: "PT1H2M3S", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{iso: "PT45S", want: 45 * time.Second},
+		{iso: "PT3M", want: 3 * time.Minute},
+		{iso: "P1DT2H", want: 26 * time.Hour},
+		{iso: "P1W", want: 7 * 24 * time.Hour},
+		{iso: "PT1.5S", want: 1500 * time.Millisecond},
+		{iso: "PT2M0.25S", want: 2*time.Minute + 250*time.Millisecond},
+		// Live streams that have not started report a zero duration
+		{iso: "P0D", want: 0},
+		{iso: "PT0S", want: 0},
+
+		// Invalid
+		{iso: "", wantErr: true},
+		{iso: "P", wantErr: true},
+		{iso: "PT", wantErr: true},
+		{iso: "P1DT", wantErr: true},
+		{iso: "4M13S", wantErr: true},
+		{iso: "PT4M13", wantErr: true},
+		{iso: "PT-4M", wantErr: true},
+		{iso: "PT4S4M", wantErr: true},
+		{iso: "pt4m13s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.iso, func(t *testing.T) {
+			got, err := youtube.ParseDuration(tt.iso)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseDuration(%q) = %s, want an error", tt.iso, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseDuration(%q) = %s, %v, want %s", tt.iso, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetVideosBatches(t *testing.T) {
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+
+	// 120 IDs: 50 + 50 + 20, with every tenth video private or deleted
+	var ids []string
+	for i := 0; i < 120; i++ {
+		id := fmt.Sprintf("video%03d", i)
+		ids = append(ids, id)
+		if i%10 != 9 {
+			yt.AddVideos(youtubetest.NewVideo(id, "Video "+id, "Channel", "PT3M"))
+		}
+	}
+
+	client := youtube.NewClient("token", youtube.WithBaseURL(yt.URL), youtube.WithoutRetries())
+	videos, err := client.GetVideos(context.Background(), ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := yt.Requests("videos"); got != 3 {
+		t.Errorf("videos requests = %d, want 3", got)
+	}
+	if len(videos) != 108 {
+		t.Fatalf("videos = %d, want 108", len(videos))
+	}
+	// Order follows the requested IDs
+	if videos[0].ID != "video000" || videos[9].ID != "video010" || videos[107].ID != "video118" {
+		t.Errorf("videos in wrong order: %s, %s, ..., %s", videos[0].ID, videos[9].ID, videos[107].ID)
+	}
+	if d, err := videos[0].Duration(); err != nil || d != 3*time.Minute {
+		t.Errorf("duration = %s, %v", d, err)
+	}
+}
+
+func TestGetVideosWithoutIDs(t *testing.T) {
+	yt := youtubetest.NewServer()
+	defer yt.Close()
+
+	client := youtube.NewClient("token", youtube.WithBaseURL(yt.URL), youtube.WithoutRetries())
+	videos, err := client.GetVideos(context.Background(), nil)
+	if err != nil || len(videos) != 0 {
+		t.Errorf("GetVideos(nil) = %v, %v", videos, err)
+	}
+	if got := yt.Requests("videos"); got != 0 {
+		t.Errorf("videos requests = %d, want 0", got)
+	}
+}
+
+func TestListVideosRejectsTooManyIDs(t *testing.T) {
+	client := youtube.NewClient("token", youtube.WithBaseURL("http://127.0.0.1:0"))
+	ids := make([]string, youtube.MaxVideoIDsPerRequest+1)
+	if _, err := client.ListVideos(context.Background(), ids, ""); err == nil {
+		t.Error("expected an error for 51 IDs")
+	}
+}
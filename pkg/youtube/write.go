@@ -0,0 +1,168 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Valores válidos de privacyStatus
+const (
+	PrivacyPublic   = "public"
+	PrivacyUnlisted = "unlisted"
+	PrivacyPrivate  = "private"
+)
+
+// PlaylistParams contiene los campos editables de una playlist
+type PlaylistParams struct {
+	Title           string // Título (obligatorio)
+	Description     string // Descripción
+	PrivacyStatus   string // public, unlisted o private (default: private)
+	DefaultLanguage string // Idioma por defecto, opcional
+}
+
+// playlistResource es el cuerpo enviado a playlists.insert y playlists.update
+type playlistResource struct {
+	ID      string               `json:"id,omitempty"`
+	Snippet playlistWriteSnippet `json:"snippet"`
+	Status  *PlaylistStatus      `json:"status,omitempty"`
+}
+
+type playlistWriteSnippet struct {
+	Title           string `json:"title"`
+	Description     string `json:"description"`
+	DefaultLanguage string `json:"defaultLanguage,omitempty"`
+}
+
+// playlistItemResource es el cuerpo enviado a playlistItems.insert y playlistItems.update
+type playlistItemResource struct {
+	ID      string                   `json:"id,omitempty"`
+	Snippet playlistItemWriteSnippet `json:"snippet"`
+}
+
+type playlistItemWriteSnippet struct {
+	PlaylistID string     `json:"playlistId"`
+	ResourceID ResourceID `json:"resourceId"`
+	Position   *int       `json:"position,omitempty"`
+}
+
+// newPlaylistResource construye el cuerpo de escritura de una playlist
+func newPlaylistResource(playlistID string, params PlaylistParams) playlistResource {
+	privacy := params.PrivacyStatus
+	if privacy == "" {
+		privacy = PrivacyPrivate
+	}
+	return playlistResource{
+		ID: playlistID,
+		Snippet: playlistWriteSnippet{
+			Title:           params.Title,
+			Description:     params.Description,
+			DefaultLanguage: params.DefaultLanguage,
+		},
+		Status: &PlaylistStatus{PrivacyStatus: privacy},
+	}
+}
+
+// CreatePlaylist crea una playlist en el canal del usuario autenticado
+func (c *Client) CreatePlaylist(ctx context.Context, params PlaylistParams) (*Playlist, error) {
+	if params.Title == "" {
+		return nil, fmt.Errorf("el título de la playlist es requerido")
+	}
+
+	query := url.Values{}
+	query.Add("part", "snippet,status")
+
+	var playlist Playlist
+	if err := c.do(ctx, "POST", "playlists", query, newPlaylistResource("", params), &playlist); err != nil {
+		return nil, err
+	}
+
+	return &playlist, nil
+}
+
+// UpdatePlaylist reemplaza título, descripción y privacidad de una playlist.
+// La API exige enviar el título aunque no cambie.
+func (c *Client) UpdatePlaylist(ctx context.Context, playlistID string, params PlaylistParams) (*Playlist, error) {
+	if playlistID == "" || params.Title == "" {
+		return nil, fmt.Errorf("el ID y el título de la playlist son requeridos")
+	}
+
+	query := url.Values{}
+	query.Add("part", "snippet,status")
+
+	var playlist Playlist
+	if err := c.do(ctx, "PUT", "playlists", query, newPlaylistResource(playlistID, params), &playlist); err != nil {
+		return nil, err
+	}
+
+	return &playlist, nil
+}
+
+// DeletePlaylist elimina una playlist
+func (c *Client) DeletePlaylist(ctx context.Context, playlistID string) error {
+	query := url.Values{}
+	query.Add("id", playlistID)
+
+	return c.do(ctx, "DELETE", "playlists", query, nil, nil)
+}
+
+// InsertPlaylistItem agrega un video a una playlist. Si position es nil el
+// video se agrega al final.
+func (c *Client) InsertPlaylistItem(ctx context.Context, playlistID, videoID string, position *int) (*PlaylistItem, error) {
+	if playlistID == "" || videoID == "" {
+		return nil, fmt.Errorf("el ID de la playlist y del video son requeridos")
+	}
+
+	query := url.Values{}
+	query.Add("part", "snippet")
+
+	body := playlistItemResource{
+		Snippet: playlistItemWriteSnippet{
+			PlaylistID: playlistID,
+			ResourceID: ResourceID{Kind: "youtube#video", VideoID: videoID},
+			Position:   position,
+		},
+	}
+
+	var item PlaylistItem
+	if err := c.do(ctx, "POST", "playlistItems", query, body, &item); err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// UpdatePlaylistItem mueve un item a otra posición dentro de su playlist.
+// La API exige reenviar el playlistId y el videoId del item.
+func (c *Client) UpdatePlaylistItem(ctx context.Context, itemID, playlistID, videoID string, position int) (*PlaylistItem, error) {
+	if itemID == "" || playlistID == "" || videoID == "" {
+		return nil, fmt.Errorf("el ID del item, de la playlist y del video son requeridos")
+	}
+
+	query := url.Values{}
+	query.Add("part", "snippet")
+
+	body := playlistItemResource{
+		ID: itemID,
+		Snippet: playlistItemWriteSnippet{
+			PlaylistID: playlistID,
+			ResourceID: ResourceID{Kind: "youtube#video", VideoID: videoID},
+			Position:   &position,
+		},
+	}
+
+	var item PlaylistItem
+	if err := c.do(ctx, "PUT", "playlistItems", query, body, &item); err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// DeletePlaylistItem quita un item de su playlist
+func (c *Client) DeletePlaylistItem(ctx context.Context, itemID string) error {
+	query := url.Values{}
+	query.Add("id", itemID)
+
+	return c.do(ctx, "DELETE", "playlistItems", query, nil, nil)
+}
@@ -0,0 +1,198 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// recordedRequest is a request received by newRecordingServer
+type recordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   map[string]interface{}
+}
+
+// newRecordingServer answers every request with an empty JSON object, or 204
+// for DELETE, and records the requests it receives
+func newRecordingServer(t *testing.T) (*httptest.Server, *[]recordedRequest) {
+	t.Helper()
+
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorded := recordedRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery}
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			if err := json.Unmarshal(data, &recorded.Body); err != nil {
+				t.Errorf("%s %s: body is not JSON: %s", r.Method, r.URL.Path, data)
+			}
+			if got := r.Header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("%s %s: Content-Type = %q", r.Method, r.URL.Path, got)
+			}
+		}
+		requests = append(requests, recorded)
+
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestWriteRequestBodies(t *testing.T) {
+	zero, third := 0, 2
+
+	tests := []struct {
+		name      string
+		call      func(ctx context.Context, client *Client) error
+		method    string
+		path      string
+		wantQuery string
+		wantBody  string
+	}{
+		{
+			name: "create playlist defaults to private",
+			call: func(ctx context.Context, client *Client) error {
+				_, err := client.CreatePlaylist(ctx, PlaylistParams{Title: "Road trip", Description: "Songs"})
+				return err
+			},
+			method:    http.MethodPost,
+			path:      "/playlists",
+			wantQuery: "part=snippet%2Cstatus",
+			wantBody:  `{"snippet":{"description":"Songs","title":"Road trip"},"status":{"privacyStatus":"private"}}`,
+		},
+		{
+			name: "update playlist sends the ID and every field",
+			call: func(ctx context.Context, client *Client) error {
+				_, err := client.UpdatePlaylist(ctx, "PL1", PlaylistParams{Title: "Road trip", PrivacyStatus: PrivacyUnlisted, DefaultLanguage: "es"})
+				return err
+			},
+			method:    http.MethodPut,
+			path:      "/playlists",
+			wantQuery: "part=snippet%2Cstatus",
+			wantBody:  `{"id":"PL1","snippet":{"defaultLanguage":"es","description":"","title":"Road trip"},"status":{"privacyStatus":"unlisted"}}`,
+		},
+		{
+			name: "delete playlist",
+			call: func(ctx context.Context, client *Client) error {
+				return client.DeletePlaylist(ctx, "PL1")
+			},
+			method:    http.MethodDelete,
+			path:      "/playlists",
+			wantQuery: "id=PL1",
+		},
+		{
+			name: "insert item at the end omits the position",
+			call: func(ctx context.Context, client *Client) error {
+				_, err := client.InsertPlaylistItem(ctx, "PL1", "video-1", nil)
+				return err
+			},
+			method:    http.MethodPost,
+			path:      "/playlistItems",
+			wantQuery: "part=snippet",
+			wantBody:  `{"snippet":{"playlistId":"PL1","resourceId":{"kind":"youtube#video","videoId":"video-1"}}}`,
+		},
+		{
+			name: "insert item at the start sends position 0",
+			call: func(ctx context.Context, client *Client) error {
+				_, err := client.InsertPlaylistItem(ctx, "PL1", "video-1", &zero)
+				return err
+			},
+			method:    http.MethodPost,
+			path:      "/playlistItems",
+			wantQuery: "part=snippet",
+			wantBody:  `{"snippet":{"playlistId":"PL1","position":0,"resourceId":{"kind":"youtube#video","videoId":"video-1"}}}`,
+		},
+		{
+			name: "insert item at a position",
+			call: func(ctx context.Context, client *Client) error {
+				_, err := client.InsertPlaylistItem(ctx, "PL1", "video-1", &third)
+				return err
+			},
+			method:    http.MethodPost,
+			path:      "/playlistItems",
+			wantQuery: "part=snippet",
+			wantBody:  `{"snippet":{"playlistId":"PL1","position":2,"resourceId":{"kind":"youtube#video","videoId":"video-1"}}}`,
+		},
+		{
+			name: "move item resends playlist and video",
+			call: func(ctx context.Context, client *Client) error {
+				_, err := client.UpdatePlaylistItem(ctx, "item-1", "PL1", "video-1", 0)
+				return err
+			},
+			method:    http.MethodPut,
+			path:      "/playlistItems",
+			wantQuery: "part=snippet",
+			wantBody:  `{"id":"item-1","snippet":{"playlistId":"PL1","position":0,"resourceId":{"kind":"youtube#video","videoId":"video-1"}}}`,
+		},
+		{
+			name: "delete item",
+			call: func(ctx context.Context, client *Client) error {
+				return client.DeletePlaylistItem(ctx, "item-1")
+			},
+			method:    http.MethodDelete,
+			path:      "/playlistItems",
+			wantQuery: "id=item-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newRecordingServer(t)
+			client := NewClient("test-token", WithBaseURL(server.URL), WithoutRetries())
+
+			if err := tt.call(context.Background(), client); err != nil {
+				t.Fatal(err)
+			}
+			if len(*requests) != 1 {
+				t.Fatalf("made %d requests, want 1", len(*requests))
+			}
+
+			got := (*requests)[0]
+			if got.Method != tt.method || got.Path != tt.path || got.Query != tt.wantQuery {
+				t.Errorf("request = %s %s?%s, want %s %s?%s", got.Method, got.Path, got.Query, tt.method, tt.path, tt.wantQuery)
+			}
+			body := ""
+			if got.Body != nil {
+				data, _ := json.Marshal(got.Body)
+				body = string(data)
+			}
+			if body != tt.wantBody {
+				t.Errorf("body = %s\nwant %s", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestWriteRequiresIDs(t *testing.T) {
+	server, requests := newRecordingServer(t)
+	client := NewClient("test-token", WithBaseURL(server.URL), WithoutRetries())
+	ctx := context.Background()
+
+	if _, err := client.CreatePlaylist(ctx, PlaylistParams{}); err == nil {
+		t.Error("CreatePlaylist without a title returned no error")
+	}
+	if _, err := client.UpdatePlaylist(ctx, "", PlaylistParams{Title: "Title"}); err == nil {
+		t.Error("UpdatePlaylist without an ID returned no error")
+	}
+	if _, err := client.UpdatePlaylist(ctx, "PL1", PlaylistParams{}); err == nil {
+		t.Error("UpdatePlaylist without a title returned no error")
+	}
+	if _, err := client.InsertPlaylistItem(ctx, "PL1", "", nil); err == nil {
+		t.Error("InsertPlaylistItem without a video returned no error")
+	}
+	if _, err := client.UpdatePlaylistItem(ctx, "", "PL1", "video-1", 0); err == nil {
+		t.Error("UpdatePlaylistItem without an item ID returned no error")
+	}
+
+	if len(*requests) != 0 {
+		t.Errorf("made %d requests, want none", len(*requests))
+	}
+}
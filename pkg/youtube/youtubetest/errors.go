@@ -0,0 +1,122 @@
+package youtubetest
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Error describe un error de la API tal como lo devuelve Google
+type Error struct {
+	Code    int
+	Reason  string
+	Domain  string
+	Message string
+}
+
+// QuotaExceeded es el error 403 que devuelve Google al agotar la cuota diaria
+func QuotaExceeded() Error {
+	return Error{
+		Code:    http.StatusForbidden,
+		Reason:  "quotaExceeded",
+		Domain:  "youtube.quota",
+		Message: "The request cannot be completed because you have exceeded your quota.",
+	}
+}
+
+// RateLimitExceeded es el error 403 que devuelve Google al enviar demasiadas peticiones
+func RateLimitExceeded() Error {
+	return Error{
+		Code:    http.StatusForbidden,
+		Reason:  "rateLimitExceeded",
+		Domain:  "usageLimits",
+		Message: "The request cannot be completed because you have exceeded your rate limit.",
+	}
+}
+
+// NotFound es un error 404 con el reason indicado (por ejemplo "playlistNotFound")
+func NotFound(reason string) Error {
+	return Error{
+		Code:    http.StatusNotFound,
+		Reason:  reason,
+		Domain:  "youtube." + resourceDomain(reason),
+		Message: "The requested resource cannot be found.",
+	}
+}
+
+// Forbidden es un error 403 por falta de permisos sobre el recurso
+func Forbidden() Error {
+	return Error{
+		Code:    http.StatusForbidden,
+		Reason:  "forbidden",
+		Domain:  "youtube.common",
+		Message: "The request is not properly authorized.",
+	}
+}
+
+// Unauthorized es el error 401 que devuelve Google con un token inválido o expirado
+func Unauthorized() Error {
+	return Error{
+		Code:    http.StatusUnauthorized,
+		Reason:  "authError",
+		Domain:  "global",
+		Message: "Request had invalid authentication credentials.",
+	}
+}
+
+// InternalError es un error 500 genérico del backend
+func InternalError() Error {
+	return Error{
+		Code:    http.StatusInternalServerError,
+		Reason:  "backendError",
+		Domain:  "global",
+		Message: "Backend Error",
+	}
+}
+
+// resourceDomain deduce el dominio del error a partir del reason
+func resourceDomain(reason string) string {
+	switch reason {
+	case "playlistNotFound", "playlistItemNotFound":
+		return "playlist"
+	case "videoNotFound":
+		return "video"
+	case "channelNotFound":
+		return "channel"
+	default:
+		return "common"
+	}
+}
+
+// errorEnvelope es el formato JSON de los errores de las APIs de Google
+type errorEnvelope struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Errors  []errorItem `json:"errors"`
+}
+
+type errorItem struct {
+	Message string `json:"message"`
+	Domain  string `json:"domain"`
+	Reason  string `json:"reason"`
+}
+
+// writeError escribe el error con el formato de Google
+func writeError(w http.ResponseWriter, e Error) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(e.Code)
+	json.NewEncoder(w).Encode(errorEnvelope{
+		Error: errorBody{
+			Code:    e.Code,
+			Message: e.Message,
+			Errors: []errorItem{{
+				Message: e.Message,
+				Domain:  e.Domain,
+				Reason:  e.Reason,
+			}},
+		},
+	})
+}
@@ -0,0 +1,99 @@
+package youtubetest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+)
+
+// Channel es la representación JSON de un recurso channel de la API
+type Channel struct {
+	Kind           string                `json:"kind"`
+	ID             string                `json:"id"`
+	Snippet        ChannelSnippet        `json:"snippet"`
+	ContentDetails ChannelContentDetails `json:"contentDetails"`
+}
+
+// ChannelSnippet contiene la información básica de un canal
+type ChannelSnippet struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	CustomURL   string `json:"customUrl,omitempty"`
+	PublishedAt string `json:"publishedAt"`
+}
+
+// ChannelContentDetails contiene las playlists relacionadas del canal
+type ChannelContentDetails struct {
+	RelatedPlaylists struct {
+		Likes   string `json:"likes,omitempty"`
+		Uploads string `json:"uploads,omitempty"`
+	} `json:"relatedPlaylists"`
+}
+
+// fixtureTime es la fecha usada por los fixtures generados
+var fixtureTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+
+// NewPlaylist crea un fixture de playlist pública con valores por defecto
+func NewPlaylist(id, title, channelID string) youtube.Playlist {
+	return youtube.Playlist{
+		ID: id,
+		Snippet: youtube.PlaylistSnippet{
+			PublishedAt:  fixtureTime,
+			ChannelID:    channelID,
+			Title:        title,
+			ChannelTitle: "Channel " + channelID,
+		},
+		Status: youtube.PlaylistStatus{PrivacyStatus: "public"},
+	}
+}
+
+// NewPlaylistItem crea un fixture de item de playlist para el video indicado
+func NewPlaylistItem(videoID, title, channelTitle string) youtube.PlaylistItem {
+	return youtube.PlaylistItem{
+		Snippet: youtube.PlaylistItemSnippet{
+			PublishedAt:  fixtureTime,
+			Title:        title,
+			ChannelTitle: channelTitle,
+			ResourceID:   youtube.ResourceID{Kind: "youtube#video", VideoID: videoID},
+		},
+	}
+}
+
+// NewVideo crea un fixture de video musical público con la duración ISO-8601 indicada
+func NewVideo(id, title, channelTitle, duration string) youtube.Video {
+	return youtube.Video{
+		ID: id,
+		Snippet: youtube.VideoSnippet{
+			PublishedAt:  fixtureTime,
+			ChannelTitle: channelTitle,
+			Title:        title,
+			CategoryID:   youtube.MusicCategoryID,
+		},
+		ContentDetails: youtube.VideoContentDetails{Duration: duration},
+		Statistics:     youtube.VideoStatistics{ViewCount: "0"},
+		Status: youtube.VideoStatus{
+			UploadStatus:  "processed",
+			PrivacyStatus: "public",
+			Embeddable:    true,
+		},
+	}
+}
+
+// SeedPlaylist agrega una playlist con n videos generados (con sus recursos
+// video correspondientes) y devuelve la playlist creada
+func (s *Server) SeedPlaylist(id, title string, n int) youtube.Playlist {
+	playlist := NewPlaylist(id, title, "UC-test")
+	s.AddPlaylist(playlist)
+
+	for i := 0; i < n; i++ {
+		videoID := fmt.Sprintf("%s-video-%d", id, i)
+		videoTitle := fmt.Sprintf("Artist %d - Song %d", i, i)
+		s.AddPlaylistItems(id, NewPlaylistItem(videoID, videoTitle, "Channel UC-test"))
+		s.AddVideos(NewVideo(videoID, videoTitle, "Channel UC-test", "PT3M30S"))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.playlists[id]
+}
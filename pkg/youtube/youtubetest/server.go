@@ -0,0 +1,486 @@
+// Package youtubetest provee un servidor falso en memoria de la YouTube Data API
+// para probar el cliente de pkg/youtube y los handlers sin acceder a Google.
+package youtubetest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+)
+
+// Costos en unidades de cuota de cada método, según la documentación de Google
+const (
+	ListCost  = 1
+	WriteCost = 50
+)
+
+// Server es un servidor HTTP que imita los endpoints playlists, playlistItems,
+// videos y channels de la YouTube Data API v3
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	playlists     map[string]youtube.Playlist
+	playlistOrder []string
+	items         map[string][]youtube.PlaylistItem
+	videos        map[string]youtube.Video
+	channels      map[string]Channel
+	mineChannelID string
+	nextID        int
+	quotaUsed     int
+	quotaLimit    int
+	requests      map[string]int
+	injected      map[string][]Error
+}
+
+// NewServer crea e inicia un servidor falso vacío. Debe cerrarse con Close.
+func NewServer() *Server {
+	s := &Server{
+		playlists: make(map[string]youtube.Playlist),
+		items:     make(map[string][]youtube.PlaylistItem),
+		videos:    make(map[string]youtube.Video),
+		channels:  make(map[string]Channel),
+		requests:  make(map[string]int),
+		injected:  make(map[string][]Error),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/playlists", s.handle("playlists", methods{
+		http.MethodGet:    s.listPlaylists,
+		http.MethodPost:   s.insertPlaylist,
+		http.MethodPut:    s.updatePlaylist,
+		http.MethodDelete: s.deletePlaylist,
+	}))
+	mux.HandleFunc("/playlistItems", s.handle("playlistItems", methods{
+		http.MethodGet:    s.listPlaylistItems,
+		http.MethodPost:   s.insertPlaylistItem,
+		http.MethodPut:    s.updatePlaylistItem,
+		http.MethodDelete: s.deletePlaylistItem,
+	}))
+	mux.HandleFunc("/videos", s.handle("videos", methods{
+		http.MethodGet: s.listVideos,
+	}))
+	mux.HandleFunc("/channels", s.handle("channels", methods{
+		http.MethodGet: s.listChannels,
+	}))
+
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Playlist devuelve una playlist almacenada, con sus items
+func (s *Server) Playlist(playlistID string) (youtube.Playlist, []youtube.PlaylistItem, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlist, ok := s.playlists[playlistID]
+	return playlist, append([]youtube.PlaylistItem(nil), s.items[playlistID]...), ok
+}
+
+// Client devuelve un cliente de YouTube apuntando a este servidor
+func (s *Server) Client(accessToken string, opts ...youtube.Option) *youtube.Client {
+	opts = append([]youtube.Option{youtube.WithBaseURL(s.URL)}, opts...)
+	return youtube.NewClient(accessToken, opts...)
+}
+
+// AddPlaylist agrega (o reemplaza) una playlist
+func (s *Server) AddPlaylist(playlist youtube.Playlist) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if playlist.Kind == "" {
+		playlist.Kind = "youtube#playlist"
+	}
+	if _, exists := s.playlists[playlist.ID]; !exists {
+		s.playlistOrder = append(s.playlistOrder, playlist.ID)
+	}
+	playlist.ContentDetails.ItemCount = len(s.items[playlist.ID])
+	s.playlists[playlist.ID] = playlist
+}
+
+// AddPlaylistItems agrega videos al final de una playlist. La posición y el
+// playlistId de cada item se completan automáticamente.
+func (s *Server) AddPlaylistItems(playlistID string, items ...youtube.PlaylistItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range items {
+		position := len(s.items[playlistID])
+		if item.Kind == "" {
+			item.Kind = "youtube#playlistItem"
+		}
+		if item.ID == "" {
+			item.ID = fmt.Sprintf("%s-item-%d", playlistID, position)
+		}
+		item.Snippet.PlaylistID = playlistID
+		item.Snippet.Position = position
+		if item.Snippet.ResourceID.Kind == "" {
+			item.Snippet.ResourceID.Kind = "youtube#video"
+		}
+		s.items[playlistID] = append(s.items[playlistID], item)
+	}
+
+	if playlist, ok := s.playlists[playlistID]; ok {
+		playlist.ContentDetails.ItemCount = len(s.items[playlistID])
+		s.playlists[playlistID] = playlist
+	}
+}
+
+// AddVideos agrega videos consultables mediante videos.list
+func (s *Server) AddVideos(videos ...youtube.Video) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, video := range videos {
+		if video.Kind == "" {
+			video.Kind = "youtube#video"
+		}
+		s.videos[video.ID] = video
+	}
+}
+
+// AddChannel agrega un canal. Si mine es true, es el canal devuelto por channels.list?mine=true.
+func (s *Server) AddChannel(channel Channel, mine bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if channel.Kind == "" {
+		channel.Kind = "youtube#channel"
+	}
+	s.channels[channel.ID] = channel
+	if mine {
+		s.mineChannelID = channel.ID
+	}
+}
+
+// SetQuotaLimit define el límite de unidades de cuota. Cero significa sin límite.
+func (s *Server) SetQuotaLimit(units int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quotaLimit = units
+}
+
+// QuotaUsed devuelve las unidades de cuota consumidas hasta ahora
+func (s *Server) QuotaUsed() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.quotaUsed
+}
+
+// Requests devuelve cuántas peticiones recibió un endpoint (por ejemplo "playlistItems")
+func (s *Server) Requests(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[endpoint]
+}
+
+// InjectError hace que las próximas times peticiones al endpoint fallen con el
+// error indicado. Un endpoint vacío aplica a cualquier endpoint.
+func (s *Server) InjectError(endpoint string, e Error, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < times; i++ {
+		s.injected[endpoint] = append(s.injected[endpoint], e)
+	}
+}
+
+// methods asocia cada método HTTP soportado por un endpoint con su handler
+type methods map[string]http.HandlerFunc
+
+// handle envuelve un endpoint con autenticación, errores inyectados y cuota
+func (s *Server) handle(endpoint string, routes methods) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[endpoint]++
+		injected, ok := s.nextInjectedError(endpoint)
+		s.mu.Unlock()
+
+		if ok {
+			writeError(w, injected)
+			return
+		}
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") ||
+			strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ") == "" {
+			writeError(w, Unauthorized())
+			return
+		}
+
+		route, ok := routes[r.Method]
+		if !ok {
+			writeError(w, Error{Code: http.StatusMethodNotAllowed, Reason: "methodNotAllowed", Message: "Method not allowed"})
+			return
+		}
+
+		cost := ListCost
+		if r.Method != http.MethodGet {
+			cost = WriteCost
+		}
+		if err, exceeded := s.chargeQuota(cost); exceeded {
+			writeError(w, err)
+			return
+		}
+
+		route(w, r)
+	}
+}
+
+// nextInjectedError extrae el siguiente error inyectado para el endpoint. Debe llamarse con mu tomado.
+func (s *Server) nextInjectedError(endpoint string) (Error, bool) {
+	for _, key := range []string{endpoint, ""} {
+		if queue := s.injected[key]; len(queue) > 0 {
+			s.injected[key] = queue[1:]
+			return queue[0], true
+		}
+	}
+	return Error{}, false
+}
+
+// chargeQuota descuenta unidades de cuota o devuelve quotaExceeded si no alcanzan
+func (s *Server) chargeQuota(cost int) (Error, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.quotaLimit > 0 && s.quotaUsed+cost > s.quotaLimit {
+		return QuotaExceeded(), true
+	}
+	s.quotaUsed += cost
+	return Error{}, false
+}
+
+// listPlaylists implementa GET /playlists
+func (s *Server) listPlaylists(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	playlists := []youtube.Playlist{}
+	switch {
+	case query.Get("id") != "":
+		for _, id := range strings.Split(query.Get("id"), ",") {
+			if playlist, ok := s.playlists[id]; ok {
+				playlists = append(playlists, playlist)
+			}
+		}
+	case query.Get("mine") == "true" || query.Get("channelId") != "":
+		channelID := query.Get("channelId")
+		for _, id := range s.playlistOrder {
+			playlist := s.playlists[id]
+			if channelID == "" || playlist.Snippet.ChannelID == channelID {
+				playlists = append(playlists, playlist)
+			}
+		}
+	default:
+		s.mu.Unlock()
+		writeError(w, Error{Code: http.StatusBadRequest, Reason: "missingRequiredParameter", Message: "No filter selected."})
+		return
+	}
+	s.mu.Unlock()
+
+	page, pageInfo, next, prev, apiErr := paginate(len(playlists), query)
+	if apiErr != nil {
+		writeError(w, *apiErr)
+		return
+	}
+
+	writeJSON(w, youtube.PlaylistsResponse{
+		Kind:          "youtube#playlistListResponse",
+		NextPageToken: next,
+		PrevPageToken: prev,
+		PageInfo:      pageInfo,
+		Items:         playlists[page.start:page.end],
+	})
+}
+
+// listPlaylistItems implementa GET /playlistItems
+func (s *Server) listPlaylistItems(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	playlistID := query.Get("playlistId")
+	if playlistID == "" {
+		writeError(w, Error{Code: http.StatusBadRequest, Reason: "missingRequiredParameter", Message: "No filter selected."})
+		return
+	}
+
+	s.mu.Lock()
+	_, exists := s.playlists[playlistID]
+	items := append([]youtube.PlaylistItem(nil), s.items[playlistID]...)
+	s.mu.Unlock()
+
+	if !exists {
+		writeError(w, NotFound("playlistNotFound"))
+		return
+	}
+
+	page, pageInfo, next, prev, apiErr := paginate(len(items), query)
+	if apiErr != nil {
+		writeError(w, *apiErr)
+		return
+	}
+
+	writeJSON(w, youtube.PlaylistItemsResponse{
+		Kind:          "youtube#playlistItemListResponse",
+		NextPageToken: next,
+		PrevPageToken: prev,
+		PageInfo:      pageInfo,
+		Items:         items[page.start:page.end],
+	})
+}
+
+// listVideos implementa GET /videos (solo filtro por id, máximo 50)
+func (s *Server) listVideos(w http.ResponseWriter, r *http.Request) {
+	ids := splitIDs(r.URL.Query().Get("id"))
+	if len(ids) == 0 {
+		writeError(w, Error{Code: http.StatusBadRequest, Reason: "missingRequiredParameter", Message: "No filter selected."})
+		return
+	}
+	if len(ids) > 50 {
+		writeError(w, Error{Code: http.StatusBadRequest, Reason: "invalidFilters", Message: "Too many video IDs."})
+		return
+	}
+
+	s.mu.Lock()
+	videos := []youtube.Video{}
+	for _, id := range ids {
+		if video, ok := s.videos[id]; ok {
+			videos = append(videos, video)
+		}
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, youtube.VideosResponse{
+		Kind:     "youtube#videoListResponse",
+		PageInfo: youtube.PageInfo{TotalResults: len(videos), ResultsPerPage: len(videos)},
+		Items:    videos,
+	})
+}
+
+// listChannels implementa GET /channels (filtros mine e id)
+func (s *Server) listChannels(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	channels := []Channel{}
+	switch {
+	case query.Get("mine") == "true":
+		if channel, ok := s.channels[s.mineChannelID]; ok {
+			channels = append(channels, channel)
+		}
+	case query.Get("id") != "":
+		for _, id := range splitIDs(query.Get("id")) {
+			if channel, ok := s.channels[id]; ok {
+				channels = append(channels, channel)
+			}
+		}
+	default:
+		s.mu.Unlock()
+		writeError(w, Error{Code: http.StatusBadRequest, Reason: "missingRequiredParameter", Message: "No filter selected."})
+		return
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, listResponse{
+		Kind:     "youtube#channelListResponse",
+		PageInfo: youtube.PageInfo{TotalResults: len(channels), ResultsPerPage: len(channels)},
+		Items:    channels,
+	})
+}
+
+// pageBounds delimita la porción de resultados de una página
+type pageBounds struct {
+	start, end int
+}
+
+// paginate calcula la página pedida a partir de maxResults y pageToken.
+// maxResults debe estar entre 1 y 50: con 0 la página quedaría vacía y el
+// nextPageToken apuntaría siempre al mismo offset.
+func paginate(total int, query url.Values) (pageBounds, youtube.PageInfo, string, string, *Error) {
+	maxResults := 5
+	if mr := query.Get("maxResults"); mr != "" {
+		parsed, err := strconv.Atoi(mr)
+		if err != nil || parsed < 1 || parsed > 50 {
+			return pageBounds{}, youtube.PageInfo{}, "", "", &Error{Code: http.StatusBadRequest, Reason: "invalidParameter", Message: "Invalid maxResults."}
+		}
+		maxResults = parsed
+	}
+
+	start := 0
+	if token := query.Get("pageToken"); token != "" {
+		offset, ok := decodePageToken(token)
+		if !ok || offset > total {
+			return pageBounds{}, youtube.PageInfo{}, "", "", &Error{Code: http.StatusBadRequest, Reason: "invalidPageToken", Message: "The request specifies an invalid page token."}
+		}
+		start = offset
+	}
+
+	end := start + maxResults
+	if end > total {
+		end = total
+	}
+
+	var next, prev string
+	if end < total {
+		next = encodePageToken(end)
+	}
+	if start > 0 {
+		prevOffset := start - maxResults
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		prev = encodePageToken(prevOffset)
+	}
+
+	return pageBounds{start: start, end: end}, youtube.PageInfo{TotalResults: total, ResultsPerPage: maxResults}, next, prev, nil
+}
+
+// encodePageToken genera un token opaco a partir de un offset
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+// decodePageToken recupera el offset de un token generado por encodePageToken
+func decodePageToken(token string) (int, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(raw), "offset:") {
+		return 0, false
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), "offset:"))
+	if err != nil || offset < 0 {
+		return 0, false
+	}
+	return offset, true
+}
+
+// splitIDs separa una lista de IDs separada por comas ignorando vacíos
+func splitIDs(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// listResponse es la forma genérica de una respuesta de listado
+type listResponse struct {
+	Kind          string           `json:"kind"`
+	NextPageToken string           `json:"nextPageToken,omitempty"`
+	PrevPageToken string           `json:"prevPageToken,omitempty"`
+	PageInfo      youtube.PageInfo `json:"pageInfo"`
+	Items         interface{}      `json:"items"`
+}
+
+// writeJSON escribe una respuesta 200 con el cuerpo en JSON
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,62 @@
+package youtubetest
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestPaginateMaxResults(t *testing.T) {
+	tests := []struct {
+		maxResults string
+		wantErr    bool
+	}{
+		{maxResults: "", wantErr: false},
+		{maxResults: "1", wantErr: false},
+		{maxResults: "50", wantErr: false},
+		{maxResults: "0", wantErr: true}, // Una página vacía repetiría el mismo nextPageToken
+		{maxResults: "-1", wantErr: true},
+		{maxResults: "51", wantErr: true},
+		{maxResults: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		query := url.Values{}
+		if tt.maxResults != "" {
+			query.Set("maxResults", tt.maxResults)
+		}
+
+		_, _, _, _, apiErr := paginate(10, query)
+		if (apiErr != nil) != tt.wantErr {
+			t.Errorf("maxResults=%q: error = %v, want error %v", tt.maxResults, apiErr, tt.wantErr)
+		}
+		if apiErr != nil && apiErr.Code != http.StatusBadRequest {
+			t.Errorf("maxResults=%q: code = %d, want 400", tt.maxResults, apiErr.Code)
+		}
+	}
+}
+
+func TestPaginateFollowsTokensToTheEnd(t *testing.T) {
+	for _, total := range []int{0, 1, 5, 12} {
+		query := url.Values{"maxResults": {"5"}}
+		seen := 0
+		for pages := 0; ; pages++ {
+			if pages > total {
+				t.Fatalf("total=%d: pagination did not end", total)
+			}
+
+			page, _, next, _, apiErr := paginate(total, query)
+			if apiErr != nil {
+				t.Fatalf("total=%d: %v", total, apiErr)
+			}
+			seen += page.end - page.start
+			if next == "" {
+				break
+			}
+			query.Set("pageToken", next)
+		}
+		if seen != total {
+			t.Errorf("total=%d: saw %d items", total, seen)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package youtubetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/alejpaa/playlist-migration-tool/pkg/youtube"
+)
+
+// insertPlaylist implementa POST /playlists
+func (s *Server) insertPlaylist(w http.ResponseWriter, r *http.Request) {
+	var body youtube.Playlist
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Snippet.Title == "" {
+		writeError(w, Error{Code: http.StatusBadRequest, Reason: "playlistTitleRequired", Message: "The request must specify a playlist title."})
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	body.ID = fmt.Sprintf("PLfake%d", s.nextID)
+	body.Kind = "youtube#playlist"
+	body.Snippet.PublishedAt = fixtureTime
+	body.Snippet.ChannelID = s.mineChannelID
+	if channel, ok := s.channels[s.mineChannelID]; ok {
+		body.Snippet.ChannelTitle = channel.Snippet.Title
+	}
+	if body.Status.PrivacyStatus == "" {
+		body.Status.PrivacyStatus = youtube.PrivacyPublic
+	}
+	body.ContentDetails.ItemCount = 0
+	s.playlists[body.ID] = body
+	s.playlistOrder = append(s.playlistOrder, body.ID)
+	s.mu.Unlock()
+
+	writeJSON(w, body)
+}
+
+// updatePlaylist implementa PUT /playlists
+func (s *Server) updatePlaylist(w http.ResponseWriter, r *http.Request) {
+	var body youtube.Playlist
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Snippet.Title == "" {
+		writeError(w, Error{Code: http.StatusBadRequest, Reason: "playlistTitleRequired", Message: "The request must specify a playlist title."})
+		return
+	}
+
+	s.mu.Lock()
+	playlist, ok := s.playlists[body.ID]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, NotFound(youtube.ReasonPlaylistNotFound))
+		return
+	}
+	playlist.Snippet.Title = body.Snippet.Title
+	playlist.Snippet.Description = body.Snippet.Description
+	if body.Status.PrivacyStatus != "" {
+		playlist.Status.PrivacyStatus = body.Status.PrivacyStatus
+	}
+	s.playlists[body.ID] = playlist
+	s.mu.Unlock()
+
+	writeJSON(w, playlist)
+}
+
+// deletePlaylist implementa DELETE /playlists
+func (s *Server) deletePlaylist(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.playlists[id]; !ok {
+		writeError(w, NotFound(youtube.ReasonPlaylistNotFound))
+		return
+	}
+	delete(s.playlists, id)
+	delete(s.items, id)
+	for i, existing := range s.playlistOrder {
+		if existing == id {
+			s.playlistOrder = append(s.playlistOrder[:i], s.playlistOrder[i+1:]...)
+			break
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// insertPlaylistItem implementa POST /playlistItems
+func (s *Server) insertPlaylistItem(w http.ResponseWriter, r *http.Request) {
+	var body youtube.PlaylistItem
+	var position struct {
+		Snippet struct {
+			Position *int `json:"position"`
+		} `json:"snippet"`
+	}
+	if !decodeTwice(r, &body, &position) {
+		writeError(w, Error{Code: http.StatusBadRequest, Reason: "parseError", Message: "Parse Error"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlistID := body.Snippet.PlaylistID
+	if _, ok := s.playlists[playlistID]; !ok {
+		writeError(w, NotFound(youtube.ReasonPlaylistNotFound))
+		return
+	}
+	video, ok := s.videos[body.Snippet.ResourceID.VideoID]
+	if !ok {
+		writeError(w, NotFound(youtube.ReasonVideoNotFound))
+		return
+	}
+
+	items := s.items[playlistID]
+	index := len(items)
+	if position.Snippet.Position != nil {
+		index = *position.Snippet.Position
+		if index < 0 || index > len(items) {
+			writeError(w, Error{Code: http.StatusBadRequest, Reason: "invalidPlaylistItemPosition", Message: "Invalid playlist item position."})
+			return
+		}
+	}
+
+	s.nextID++
+	item := youtube.PlaylistItem{
+		Kind: "youtube#playlistItem",
+		ID:   fmt.Sprintf("PLIfake%d", s.nextID),
+		Snippet: youtube.PlaylistItemSnippet{
+			PublishedAt:  fixtureTime,
+			ChannelID:    s.mineChannelID,
+			Title:        video.Snippet.Title,
+			Description:  video.Snippet.Description,
+			ChannelTitle: video.Snippet.ChannelTitle,
+			PlaylistID:   playlistID,
+			ResourceID:   youtube.ResourceID{Kind: "youtube#video", VideoID: video.ID},
+		},
+	}
+
+	items = append(items, youtube.PlaylistItem{})
+	copy(items[index+1:], items[index:])
+	items[index] = item
+	s.setItems(playlistID, items)
+
+	writeJSON(w, s.items[playlistID][index])
+}
+
+// updatePlaylistItem implementa PUT /playlistItems (solo cambio de posición)
+func (s *Server) updatePlaylistItem(w http.ResponseWriter, r *http.Request) {
+	var body youtube.PlaylistItem
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, Error{Code: http.StatusBadRequest, Reason: "parseError", Message: "Parse Error"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlistID := body.Snippet.PlaylistID
+	items := s.items[playlistID]
+	from := indexOfItem(items, body.ID)
+	if from < 0 {
+		writeError(w, NotFound(youtube.ReasonPlaylistItemNotFound))
+		return
+	}
+	to := body.Snippet.Position
+	if to < 0 || to >= len(items) {
+		writeError(w, Error{Code: http.StatusBadRequest, Reason: "invalidPlaylistItemPosition", Message: "Invalid playlist item position."})
+		return
+	}
+
+	item := items[from]
+	items = append(items[:from], items[from+1:]...)
+	items = append(items, youtube.PlaylistItem{})
+	copy(items[to+1:], items[to:])
+	items[to] = item
+	s.setItems(playlistID, items)
+
+	writeJSON(w, s.items[playlistID][to])
+}
+
+// deletePlaylistItem implementa DELETE /playlistItems
+func (s *Server) deletePlaylistItem(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for playlistID, items := range s.items {
+		if index := indexOfItem(items, id); index >= 0 {
+			s.setItems(playlistID, append(items[:index], items[index+1:]...))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	writeError(w, NotFound(youtube.ReasonPlaylistItemNotFound))
+}
+
+// setItems reemplaza los items de una playlist renumerando sus posiciones. Debe llamarse con mu tomado.
+func (s *Server) setItems(playlistID string, items []youtube.PlaylistItem) {
+	for i := range items {
+		items[i].Snippet.Position = i
+	}
+	s.items[playlistID] = items
+
+	if playlist, ok := s.playlists[playlistID]; ok {
+		playlist.ContentDetails.ItemCount = len(items)
+		s.playlists[playlistID] = playlist
+	}
+}
+
+// indexOfItem busca un item por ID
+func indexOfItem(items []youtube.PlaylistItem, id string) int {
+	for i, item := range items {
+		if item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// decodeTwice decodifica el cuerpo JSON en dos destinos distintos
+func decodeTwice(r *http.Request, first, second interface{}) bool {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		return false
+	}
+	return json.Unmarshal(raw, first) == nil && json.Unmarshal(raw, second) == nil
+}